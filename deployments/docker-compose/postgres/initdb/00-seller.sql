@@ -1,3 +1,6 @@
+-- Bootstrap for a fresh local database. The schema is owned by the migrations embedded in each
+-- service (services/<service>/internal/migrations), which the services apply on startup. Only the
+-- tables that predate the migrations are created here; the 0001 baseline migrations adopt them.
 CREATE TABLE sellers (
     id VARCHAR(36) PRIMARY KEY,
     -- Assuming UUIDs are used for IDs
@@ -14,8 +17,8 @@ CREATE TABLE sellers (
     -- Precision for latitude
     longitude DECIMAL(11, 8) NOT NULL,
     -- Precision for longitude
-    last_updated_by VARCHAR(36) NOT NULL,
-    -- Assuming User ID is also a UUID or similar
+    last_updated_by VARCHAR(100) NOT NULL,
+    -- User ID (UUID) or "service:<client_id>" for changes made by a service principal
     last_update_time TIMESTAMP WITH TIME ZONE NOT NULL DEFAULT CURRENT_TIMESTAMP
 );
 -- Optional: Add an index for frequently queried fields like email or brand_id
@@ -36,5 +39,5 @@ COMMENT ON COLUMN sellers.email IS 'Contact email address of the seller';
 COMMENT ON COLUMN sellers.phone_number IS 'Contact phone number of the seller';
 COMMENT ON COLUMN sellers.latitude IS 'Geographical latitude of the seller';
 COMMENT ON COLUMN sellers.longitude IS 'Geographical longitude of the seller';
-COMMENT ON COLUMN sellers.last_updated_by IS 'User ID of the person who last updated the record';
+COMMENT ON COLUMN sellers.last_updated_by IS 'User ID or service principal that last updated the record';
 COMMENT ON COLUMN sellers.last_update_time IS 'Timestamp of when the record was last updated';
\ No newline at end of file
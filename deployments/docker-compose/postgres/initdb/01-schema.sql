@@ -1,3 +1,6 @@
+-- Bootstrap for a fresh local database. The schema is owned by the migrations embedded in each
+-- service (services/<service>/internal/migrations), which the services apply on startup. Only the
+-- tables that predate the migrations are created here; the 0001 baseline migrations adopt them.
 -- Create users table
 CREATE TABLE IF NOT EXISTS users (
     id VARCHAR(36) PRIMARY KEY,
@@ -14,4 +17,4 @@ CREATE TABLE IF NOT EXISTS products (
     sku VARCHAR(100) UNIQUE NOT NULL,
     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
     updated_at TIMESTAMP WITH TIME ZONE NOT NULL
-);
\ No newline at end of file
+);
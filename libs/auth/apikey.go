@@ -0,0 +1,152 @@
+package auth
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"database/sql"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+const (
+	// APIKeyHeader is the request header carrying an API key.
+	APIKeyHeader = "X-API-Key"
+	// PrincipalAPIKey marks claims resolved from an API key.
+	PrincipalAPIKey = "api_key"
+
+	apiKeyPrefix       = "dmk_"
+	apiKeyDisplayChars = 12 // Length of the prefix kept in clear text so users can tell keys apart
+)
+
+// ErrInvalidAPIKey is returned when an API key is unknown, expired or revoked.
+var ErrInvalidAPIKey = errors.New("invalid API key")
+
+// APIKey is the stored form of an API key. Only the SHA-256 hash of the key is kept.
+type APIKey struct {
+	ID        string
+	OwnerID   string // User who issued the key
+	Scopes    []string
+	Brands    []string // Tenants copied from the owner when the key was issued
+	ExpiresAt time.Time
+	RevokedAt *time.Time
+}
+
+// APIKeyStore looks up API keys by hash.
+type APIKeyStore interface {
+	GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error)
+}
+
+// GenerateAPIKey returns a new random API key together with its display prefix and hash.
+// The raw key is shown to the user once; only the prefix and hash are stored.
+func GenerateAPIKey() (rawKey, displayPrefix, keyHash string) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("auth: failed to read random bytes: %v", err))
+	}
+	rawKey = apiKeyPrefix + base64.RawURLEncoding.EncodeToString(b)
+	return rawKey, rawKey[:apiKeyDisplayChars], HashAPIKey(rawKey)
+}
+
+// HashAPIKey returns the value stored in api_keys.key_hash for a raw API key.
+func HashAPIKey(rawKey string) string {
+	sum := sha256.Sum256([]byte(rawKey))
+	return hex.EncodeToString(sum[:])
+}
+
+// IsAPIKey reports whether the claims were resolved from an API key.
+func (c *Claims) IsAPIKey() bool {
+	return c.PrincipalType == PrincipalAPIKey
+}
+
+// WithAPIKeyStore makes Middleware accept the X-API-Key header in addition to Bearer tokens.
+func (a *JWTAuthenticator) WithAPIKeyStore(store APIKeyStore) *JWTAuthenticator {
+	a.apiKeys = store
+	return a
+}
+
+// AuthenticateAPIKey resolves a raw API key to Claims. The claims carry the owner as UserID,
+// the key ID as ClientID and jti, and the key's scopes, which bound what the key may do.
+func (a *JWTAuthenticator) AuthenticateAPIKey(ctx context.Context, rawKey string) (*Claims, error) {
+	if a.apiKeys == nil {
+		return nil, errors.New("authenticator does not accept API keys")
+	}
+	if !strings.HasPrefix(rawKey, apiKeyPrefix) {
+		return nil, ErrInvalidAPIKey
+	}
+	key, err := a.apiKeys.GetAPIKeyByHash(ctx, HashAPIKey(rawKey))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up API key: %w", err)
+	}
+	if key == nil || key.RevokedAt != nil || !time.Now().Before(key.ExpiresAt) {
+		return nil, ErrInvalidAPIKey
+	}
+	return &Claims{
+		UserID:        key.OwnerID,
+		PrincipalType: PrincipalAPIKey,
+		ClientID:      key.ID,
+		Scope:         strings.Join(key.Scopes, " "),
+		Brands:        key.Brands,
+		RegisteredClaims: jwt.RegisteredClaims{
+			Subject:   PrincipalAPIKey + ":" + key.ID,
+			ExpiresAt: jwt.NewNumericDate(key.ExpiresAt),
+			ID:        key.ID,
+		},
+	}, nil
+}
+
+// SQLAPIKeyStore is an APIKeyStore backed by the api_keys table, which the user service manages.
+//
+//	CREATE TABLE api_keys (
+//	    id VARCHAR(36) PRIMARY KEY,
+//	    owner_id VARCHAR(255) NOT NULL,
+//	    name VARCHAR(255) NOT NULL,
+//	    key_prefix VARCHAR(16) NOT NULL,
+//	    key_hash VARCHAR(64) UNIQUE NOT NULL,
+//	    scopes TEXT NOT NULL,
+//	    brands TEXT NOT NULL DEFAULT '',
+//	    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//	    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//	    revoked_at TIMESTAMP WITH TIME ZONE
+//	);
+type SQLAPIKeyStore struct {
+	db *sql.DB
+}
+
+// NewSQLAPIKeyStore creates a new SQLAPIKeyStore.
+func NewSQLAPIKeyStore(db *sql.DB) *SQLAPIKeyStore {
+	return &SQLAPIKeyStore{db: db}
+}
+
+// CheckSchema returns an error if the api_keys table does not exist. The user service creates it;
+// other services call this at startup, as for SQLRevocationStore.CheckSchema.
+func (s *SQLAPIKeyStore) CheckSchema(ctx context.Context) error {
+	return checkTables(ctx, s.db, "api_keys")
+}
+
+// GetAPIKeyByHash returns the key with the given hash, or nil if there is none.
+func (s *SQLAPIKeyStore) GetAPIKeyByHash(ctx context.Context, keyHash string) (*APIKey, error) {
+	key := &APIKey{}
+	var scopes, brands string
+	var revokedAt sql.NullTime
+	query := `SELECT id, owner_id, scopes, brands, expires_at, revoked_at FROM api_keys WHERE key_hash = $1`
+	err := s.db.QueryRowContext(ctx, query, keyHash).Scan(&key.ID, &key.OwnerID, &scopes, &brands, &key.ExpiresAt, &revokedAt)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("failed to query API key: %w", err)
+	}
+	key.Scopes = strings.Fields(scopes)
+	key.Brands = strings.Fields(brands)
+	if revokedAt.Valid {
+		key.RevokedAt = &revokedAt.Time
+	}
+	return key, nil
+}
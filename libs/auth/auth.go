@@ -2,7 +2,10 @@ package auth
 
 import (
 	"context"
+	"crypto/rand"
+	"encoding/hex"
 	"errors"
+	"fmt"
 	"net/http"
 	"strings"
 	"time"
@@ -10,20 +13,81 @@ import (
 	"github.com/golang-jwt/jwt/v4"
 )
 
+// ErrNoTokenID is returned by RevokeToken for tokens without a jti claim, such as API keys.
+var ErrNoTokenID = errors.New("token has no jti claim")
+
 // JWTAuthenticator handles JWT creation and validation.
 type JWTAuthenticator struct {
-	jwtSecret []byte
+	jwtSecret   []byte            // Shared HMAC secret (legacy HS256 tokens)
+	signingKeys *KeySet           // Asymmetric keys used to sign tokens (issuer only)
+	verifyKeys  PublicKeyProvider // Public keys used to verify RS256/ES256 tokens
+	revocations RevocationStore   // Optional list of revoked token IDs (jti)
+	audience    string            // Audience this service accepts (required for service tokens)
+	apiKeys     APIKeyStore       // Optional store of API keys accepted via the X-API-Key header
 }
 
-// NewJWTAuthenticator creates a new JWTAuthenticator.
+// NewJWTAuthenticator creates a new JWTAuthenticator that signs and verifies HS256 tokens with a shared secret.
 func NewJWTAuthenticator(secret string) *JWTAuthenticator {
 	return &JWTAuthenticator{jwtSecret: []byte(secret)}
 }
 
+// NewKeySetAuthenticator creates a JWTAuthenticator for the token issuer.
+// Tokens are signed with the primary key of the set and carry its kid header.
+func NewKeySetAuthenticator(keys *KeySet) *JWTAuthenticator {
+	return &JWTAuthenticator{signingKeys: keys, verifyKeys: keys}
+}
+
+// NewJWKSAuthenticator creates a verify-only JWTAuthenticator that validates tokens
+// against the JWKS published by the issuer (e.g. http://user-service/.well-known/jwks.json).
+func NewJWKSAuthenticator(jwksURL string, refreshInterval time.Duration) *JWTAuthenticator {
+	return &JWTAuthenticator{verifyKeys: NewRemoteKeySet(jwksURL, refreshInterval)}
+}
+
+// NewPublicKeyAuthenticator creates a verify-only JWTAuthenticator backed by any PublicKeyProvider.
+func NewPublicKeyAuthenticator(keys PublicKeyProvider) *JWTAuthenticator {
+	return &JWTAuthenticator{verifyKeys: keys}
+}
+
+// WithRevocationStore makes Middleware reject tokens that were revoked, by jti or with all tokens of their user.
+func (a *JWTAuthenticator) WithRevocationStore(store RevocationStore) *JWTAuthenticator {
+	a.revocations = store
+	return a
+}
+
+// RevokeToken adds the token's jti to the revocation list until the token expires.
+func (a *JWTAuthenticator) RevokeToken(ctx context.Context, claims *Claims) error {
+	if a.revocations == nil {
+		return errors.New("authenticator has no revocation store")
+	}
+	if claims.ID == "" {
+		return ErrNoTokenID
+	}
+	expiresAt := time.Now()
+	if claims.ExpiresAt != nil {
+		expiresAt = claims.ExpiresAt.Time
+	}
+	return a.revocations.Revoke(ctx, claims.ID, expiresAt)
+}
+
+// RevokeUserTokens revokes every token issued to the user so far, e.g. after an account compromise.
+func (a *JWTAuthenticator) RevokeUserTokens(ctx context.Context, userID string) error {
+	if a.revocations == nil {
+		return errors.New("authenticator has no revocation store")
+	}
+	return a.revocations.RevokeUser(ctx, userID, time.Now())
+}
+
 // Claims defines the JWT claims.
+// End-user tokens carry UserID and Roles; service tokens (client-credentials grant) carry
+// PrincipalType "service", ClientID, Scope and an audience instead.
+// Brands lists the tenants the principal may act on ("*" for all); without it no tenant data is visible.
 type Claims struct {
-	UserID string   `json:"user_id"`
-	Roles  []string `json:"roles"`
+	UserID        string   `json:"user_id,omitempty"`
+	Roles         []string `json:"roles,omitempty"`
+	Brands        []string `json:"brands,omitempty"`
+	PrincipalType string   `json:"principal_type,omitempty"`
+	ClientID      string   `json:"client_id,omitempty"`
+	Scope         string   `json:"scope,omitempty"` // Space separated, as in OAuth 2.0
 	jwt.RegisteredClaims
 }
 
@@ -31,17 +95,24 @@ type contextKey string
 
 const ClaimsContextKey contextKey = "jwtClaims"
 
-// GenerateToken creates a new JWT token.
+// GenerateToken creates a new JWT token without tenant claims; see GenerateUserToken.
 func (a *JWTAuthenticator) GenerateToken(userID string, roles []string, duration time.Duration) (string, error) {
-	expirationTime := time.Now().Add(duration)
-	claims := &Claims{
-		UserID: userID,
-		Roles:  roles,
-		RegisteredClaims: jwt.RegisteredClaims{
-			ExpiresAt: jwt.NewNumericDate(expirationTime),
-			IssuedAt:  jwt.NewNumericDate(time.Now()),
-			Issuer:    "user-service", // Or your issuer name
-		},
+	return a.GenerateUserToken(userID, roles, nil, duration)
+}
+
+// SignClaims signs the given claims with the primary key, or the shared secret if no key set is configured.
+func (a *JWTAuthenticator) SignClaims(claims jwt.Claims) (string, error) {
+	if a.signingKeys != nil {
+		key, err := a.signingKeys.Primary()
+		if err != nil {
+			return "", err
+		}
+		token := jwt.NewWithClaims(key.Method, claims)
+		token.Header["kid"] = key.ID
+		return token.SignedString(key.PrivateKey)
+	}
+	if len(a.jwtSecret) == 0 {
+		return "", errors.New("authenticator has no signing key")
 	}
 
 	token := jwt.NewWithClaims(jwt.SigningMethodHS256, claims)
@@ -52,9 +123,69 @@ func (a *JWTAuthenticator) GenerateToken(userID string, roles []string, duration
 	return tokenString, nil
 }
 
+// ParseToken validates a token string and returns its claims.
+func (a *JWTAuthenticator) ParseToken(tokenString string) (*Claims, error) {
+	claims := &Claims{}
+	token, err := jwt.ParseWithClaims(tokenString, claims, a.keyFunc)
+	if err != nil {
+		return nil, err
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid token")
+	}
+	if err := a.verifyAudience(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+// keyFunc selects the verification key based on the token's alg and kid headers.
+func (a *JWTAuthenticator) keyFunc(token *jwt.Token) (interface{}, error) {
+	switch token.Method.(type) {
+	case *jwt.SigningMethodHMAC:
+		if len(a.jwtSecret) == 0 {
+			return nil, errors.New("unexpected signing method")
+		}
+		return a.jwtSecret, nil
+	case *jwt.SigningMethodRSA, *jwt.SigningMethodECDSA:
+		if a.verifyKeys == nil {
+			return nil, errors.New("unexpected signing method")
+		}
+		kid, _ := token.Header["kid"].(string)
+		if kid == "" {
+			return nil, errors.New("token has no kid header")
+		}
+		key, alg, err := a.verifyKeys.PublicKey(kid)
+		if err != nil {
+			return nil, err
+		}
+		if alg != "" && alg != token.Method.Alg() {
+			return nil, fmt.Errorf("signing method %s does not match key %s", token.Method.Alg(), kid)
+		}
+		return key, nil
+	}
+	return nil, errors.New("unexpected signing method")
+}
+
 // Middleware is an HTTP middleware for validating JWT tokens.
+// If an APIKeyStore is configured, an X-API-Key header is accepted instead of a Bearer token.
 func (a *JWTAuthenticator) Middleware(next http.Handler) http.Handler {
 	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if apiKey := r.Header.Get(APIKeyHeader); apiKey != "" && a.apiKeys != nil {
+			claims, err := a.AuthenticateAPIKey(r.Context(), apiKey)
+			if err != nil {
+				if errors.Is(err, ErrInvalidAPIKey) {
+					http.Error(w, "Invalid API key", http.StatusUnauthorized)
+				} else {
+					http.Error(w, "Unable to verify API key", http.StatusServiceUnavailable)
+				}
+				return
+			}
+			ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
+			next.ServeHTTP(w, r.WithContext(ctx))
+			return
+		}
+
 		authHeader := r.Header.Get("Authorization")
 		if authHeader == "" {
 			http.Error(w, "Authorization header required", http.StatusUnauthorized)
@@ -68,14 +199,7 @@ func (a *JWTAuthenticator) Middleware(next http.Handler) http.Handler {
 		}
 		tokenString := parts[1]
 
-		claims := &Claims{}
-		token, err := jwt.ParseWithClaims(tokenString, claims, func(token *jwt.Token) (interface{}, error) {
-			if _, ok := token.Method.(*jwt.SigningMethodHMAC); !ok {
-				return nil, errors.New("unexpected signing method")
-			}
-			return a.jwtSecret, nil
-		})
-
+		claims, err := a.ParseToken(tokenString)
 		if err != nil {
 			if errors.Is(err, jwt.ErrTokenExpired) {
 				http.Error(w, "Token has expired", http.StatusUnauthorized)
@@ -85,9 +209,16 @@ func (a *JWTAuthenticator) Middleware(next http.Handler) http.Handler {
 			return
 		}
 
-		if !token.Valid {
-			http.Error(w, "Invalid token", http.StatusUnauthorized)
-			return
+		if a.revocations != nil && (claims.ID != "" || claims.UserID != "") {
+			revoked, err := a.revocations.IsRevoked(r.Context(), claims)
+			if err != nil {
+				http.Error(w, "Unable to verify token status", http.StatusServiceUnavailable)
+				return
+			}
+			if revoked {
+				http.Error(w, "Token has been revoked", http.StatusUnauthorized)
+				return
+			}
 		}
 
 		ctx := context.WithValue(r.Context(), ClaimsContextKey, claims)
@@ -95,6 +226,15 @@ func (a *JWTAuthenticator) Middleware(next http.Handler) http.Handler {
 	})
 }
 
+// NewTokenID returns a random identifier suitable for the jti claim or opaque tokens.
+func NewTokenID() string {
+	b := make([]byte, 16)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("auth: failed to read random bytes: %v", err))
+	}
+	return hex.EncodeToString(b)
+}
+
 // GetClaimsFromContext retrieves the Claims struct from the context.
 // It returns the Claims and a boolean indicating if the claims were found.
 func GetClaimsFromContext(ctx context.Context) (*Claims, bool) {
@@ -0,0 +1,163 @@
+package auth
+
+import (
+	"context"
+	_ "embed"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+)
+
+// Permissions checked by the domain services. A permission is "<resource>:<action>";
+// a policy may grant "<resource>:*" or "*" to cover several at once.
+const (
+	PermSellersRead   = "sellers:read"
+	PermSellersWrite  = "sellers:write"
+	PermSellersDelete = "sellers:delete"
+	PermProductsRead  = "products:read"
+	PermProductsWrite = "products:write"
+	PermUsersRead     = "users:read"
+	PermUsersWrite    = "users:write"
+	PermUsersAdmin    = "users:admin"
+	PermLoggingAdmin  = "logging:admin" // Change log levels at runtime
+)
+
+var (
+	// ErrUnauthenticated is returned when no claims are present in the context.
+	ErrUnauthenticated = errors.New("unauthorized: no valid token in context")
+	// ErrForbidden is returned when the caller lacks the required role or permission.
+	ErrForbidden = errors.New("forbidden")
+)
+
+//go:embed default_policy.json
+var defaultPolicyJSON []byte
+
+// Policy maps roles to the permissions they grant.
+type Policy struct {
+	Roles map[string][]string `json:"roles"`
+}
+
+// NewPolicy creates a Policy from a role to permissions map.
+func NewPolicy(roles map[string][]string) *Policy {
+	return &Policy{Roles: roles}
+}
+
+// ParsePolicy parses a JSON policy document of the form {"roles": {"admin": ["*"], ...}}.
+func ParsePolicy(data []byte) (*Policy, error) {
+	policy := &Policy{}
+	if err := json.Unmarshal(data, policy); err != nil {
+		return nil, fmt.Errorf("failed to parse authorization policy: %w", err)
+	}
+	if len(policy.Roles) == 0 {
+		return nil, errors.New("authorization policy defines no roles")
+	}
+	return policy, nil
+}
+
+// DefaultPolicy returns the policy shipped with the library (default_policy.json).
+func DefaultPolicy() *Policy {
+	policy, err := ParsePolicy(defaultPolicyJSON)
+	if err != nil {
+		panic(err) // The embedded policy is part of the build
+	}
+	return policy
+}
+
+// LoadPolicy reads a policy file, or returns DefaultPolicy when path is empty.
+func LoadPolicy(path string) (*Policy, error) {
+	if path == "" {
+		return DefaultPolicy(), nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read authorization policy %s: %w", path, err)
+	}
+	return ParsePolicy(data)
+}
+
+// HasPermission reports whether any of the roles grants the permission.
+func (p *Policy) HasPermission(roles []string, permission string) bool {
+	resource, _, _ := strings.Cut(permission, ":")
+	for _, role := range roles {
+		for _, granted := range p.Roles[role] {
+			if granted == "*" || granted == permission || granted == resource+":*" {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Authorize checks that the caller in ctx holds the permission.
+// Service principals and API keys are authorized by their granted scopes, which use the same names as permissions.
+func (p *Policy) Authorize(ctx context.Context, permission string) error {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	if claims.IsScopeLimited() {
+		if !claims.HasScope(permission) {
+			return fmt.Errorf("%w: missing scope %s", ErrForbidden, permission)
+		}
+		return nil
+	}
+	if !p.HasPermission(claims.Roles, permission) {
+		return fmt.Errorf("%w: missing permission %s", ErrForbidden, permission)
+	}
+	return nil
+}
+
+// AuthorizeRoles checks that the caller in ctx holds at least one of the roles.
+func AuthorizeRoles(ctx context.Context, roles ...string) error {
+	claims, ok := GetClaimsFromContext(ctx)
+	if !ok {
+		return ErrUnauthenticated
+	}
+	for _, required := range roles {
+		for _, role := range claims.Roles {
+			if role == required {
+				return nil
+			}
+		}
+	}
+	return fmt.Errorf("%w: requires one of roles %s", ErrForbidden, strings.Join(roles, ", "))
+}
+
+// RequirePermission returns middleware that rejects callers without the permission.
+// It must run after JWTAuthenticator.Middleware; it can be used with mux.Router.Use or to wrap single routes.
+func (p *Policy) RequirePermission(permission string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := p.Authorize(r.Context(), permission); err != nil {
+				WriteAuthorizationError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// RequireRoles returns middleware that rejects callers holding none of the roles.
+func RequireRoles(roles ...string) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			if err := AuthorizeRoles(r.Context(), roles...); err != nil {
+				WriteAuthorizationError(w, err)
+				return
+			}
+			next.ServeHTTP(w, r)
+		})
+	}
+}
+
+// WriteAuthorizationError writes the standard 401/403 response for an Authorize error.
+func WriteAuthorizationError(w http.ResponseWriter, err error) {
+	if errors.Is(err, ErrUnauthenticated) {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+	http.Error(w, "Forbidden: "+strings.TrimPrefix(err.Error(), ErrForbidden.Error()+": "), http.StatusForbidden)
+}
@@ -0,0 +1,224 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/omni-compos/digital-mono/libs/auth"
+)
+
+func withClaims(claims *auth.Claims) context.Context {
+	return context.WithValue(context.Background(), auth.ClaimsContextKey, claims)
+}
+
+func TestPolicy_HasPermission(t *testing.T) {
+	policy := auth.NewPolicy(map[string][]string{
+		"admin":           {"*"},
+		"seller_manager":  {"sellers:*"},
+		"catalog_manager": {"products:read", "products:write"},
+	})
+	tests := []struct {
+		roles      []string
+		permission string
+		want       bool
+	}{
+		{[]string{"admin"}, auth.PermUsersAdmin, true},
+		{[]string{"seller_manager"}, auth.PermSellersDelete, true},
+		{[]string{"seller_manager"}, auth.PermProductsRead, false},
+		{[]string{"catalog_manager"}, auth.PermProductsWrite, true},
+		{[]string{"catalog_manager"}, "products:delete", false},
+		{[]string{"seller_manager", "catalog_manager"}, auth.PermProductsRead, true},
+		{[]string{"unknown"}, auth.PermSellersRead, false},
+		{nil, auth.PermSellersRead, false},
+	}
+	for _, tt := range tests {
+		if got := policy.HasPermission(tt.roles, tt.permission); got != tt.want {
+			t.Errorf("HasPermission(%v, %s) = %v, want %v", tt.roles, tt.permission, got, tt.want)
+		}
+	}
+}
+
+func TestDefaultPolicy_Roles(t *testing.T) {
+	policy := auth.DefaultPolicy()
+	tests := []struct {
+		role       string
+		permission string
+		want       bool
+	}{
+		{"admin", auth.PermLoggingAdmin, true},
+		{"seller_manager", auth.PermSellersDelete, true},
+		{"catalog_manager", auth.PermProductsWrite, true},
+		{"catalog_manager", auth.PermSellersWrite, false},
+		{"user", auth.PermSellersRead, true},
+		{"user", auth.PermUsersWrite, false},
+	}
+	for _, tt := range tests {
+		if got := policy.HasPermission([]string{tt.role}, tt.permission); got != tt.want {
+			t.Errorf("%s has %s = %v, want %v", tt.role, tt.permission, got, tt.want)
+		}
+	}
+
+	if _, err := auth.ParsePolicy([]byte(`{"roles": {}}`)); err == nil {
+		t.Error("expected a policy without roles to be rejected")
+	}
+	if _, err := auth.ParsePolicy([]byte(`not json`)); err == nil {
+		t.Error("expected malformed JSON to be rejected")
+	}
+}
+
+func TestPolicy_Authorize(t *testing.T) {
+	policy := auth.DefaultPolicy()
+	tests := map[string]struct {
+		ctx        context.Context
+		permission string
+		want       error
+	}{
+		"no claims":                {context.Background(), auth.PermSellersRead, auth.ErrUnauthenticated},
+		"role grants":              {withClaims(&auth.Claims{UserID: "u", Roles: []string{"user"}}), auth.PermSellersRead, nil},
+		"role does not grant":      {withClaims(&auth.Claims{UserID: "u", Roles: []string{"user"}}), auth.PermSellersWrite, auth.ErrForbidden},
+		"service with scope":       {withClaims(&auth.Claims{PrincipalType: auth.PrincipalService, Scope: "sellers:read products:read"}), auth.PermProductsRead, nil},
+		"service without scope":    {withClaims(&auth.Claims{PrincipalType: auth.PrincipalService, Scope: "sellers:read"}), auth.PermSellersWrite, auth.ErrForbidden},
+		"service roles are unused": {withClaims(&auth.Claims{PrincipalType: auth.PrincipalService, Roles: []string{"admin"}, Scope: "sellers:read"}), auth.PermUsersAdmin, auth.ErrForbidden},
+		"API key wildcard scope":   {withClaims(&auth.Claims{PrincipalType: auth.PrincipalAPIKey, Scope: "sellers:*"}), auth.PermSellersDelete, nil},
+		"API key roles are unused": {withClaims(&auth.Claims{PrincipalType: auth.PrincipalAPIKey, Roles: []string{"admin"}}), auth.PermSellersRead, auth.ErrForbidden},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			err := policy.Authorize(tt.ctx, tt.permission)
+			if tt.want == nil && err != nil {
+				t.Errorf("Authorize = %v, want nil", err)
+			}
+			if tt.want != nil && !errors.Is(err, tt.want) {
+				t.Errorf("Authorize = %v, want %v", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestClaims_HasScope(t *testing.T) {
+	claims := &auth.Claims{Scope: "sellers:read products:*"}
+	tests := map[string]bool{
+		auth.PermSellersRead:   true,
+		auth.PermSellersWrite:  false,
+		auth.PermProductsWrite: true,
+		auth.PermUsersRead:     false,
+	}
+	for scope, want := range tests {
+		if got := claims.HasScope(scope); got != want {
+			t.Errorf("HasScope(%s) = %v, want %v", scope, got, want)
+		}
+	}
+	if !(&auth.Claims{Scope: "*"}).HasScope(auth.PermUsersAdmin) {
+		t.Error(`"*" must grant every scope`)
+	}
+	if (&auth.Claims{}).HasScope(auth.PermSellersRead) {
+		t.Error("no scope must grant nothing")
+	}
+}
+
+func TestRequirePermission_StatusAndBody(t *testing.T) {
+	handler := auth.DefaultPolicy().RequirePermission(auth.PermSellersWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusNoContent)
+	}))
+	tests := map[string]struct {
+		ctx    context.Context
+		status int
+		body   string
+	}{
+		"no claims":       {context.Background(), http.StatusUnauthorized, "Unauthorized\n"},
+		"missing role":    {withClaims(&auth.Claims{UserID: "u", Roles: []string{"user"}}), http.StatusForbidden, "Forbidden: missing permission sellers:write\n"},
+		"missing scope":   {withClaims(&auth.Claims{PrincipalType: auth.PrincipalService, Scope: "sellers:read"}), http.StatusForbidden, "Forbidden: missing scope sellers:write\n"},
+		"role grants":     {withClaims(&auth.Claims{UserID: "u", Roles: []string{"seller_manager"}}), http.StatusNoContent, ""},
+		"scope grants":    {withClaims(&auth.Claims{PrincipalType: auth.PrincipalAPIKey, Scope: "sellers:write"}), http.StatusNoContent, ""},
+		"wildcard grants": {withClaims(&auth.Claims{UserID: "u", Roles: []string{"admin"}}), http.StatusNoContent, ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ServeHTTP(rec, httptest.NewRequest(http.MethodPost, "/sellers", nil).WithContext(tt.ctx))
+			if rec.Code != tt.status || rec.Body.String() != tt.body {
+				t.Errorf("got %d %q, want %d %q", rec.Code, rec.Body.String(), tt.status, tt.body)
+			}
+		})
+	}
+}
+
+func TestRequireRoles(t *testing.T) {
+	handler := auth.RequireRoles("admin", "seller_manager")(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	tests := map[string]struct {
+		ctx    context.Context
+		status int
+	}{
+		"no claims":  {context.Background(), http.StatusUnauthorized},
+		"other role": {withClaims(&auth.Claims{Roles: []string{"user"}}), http.StatusForbidden},
+		"one role":   {withClaims(&auth.Claims{Roles: []string{"user", "seller_manager"}}), http.StatusOK},
+	}
+	for name, tt := range tests {
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/", nil).WithContext(tt.ctx))
+		if rec.Code != tt.status {
+			t.Errorf("%s: status = %d, want %d", name, rec.Code, tt.status)
+		}
+	}
+}
+
+func TestRequirePermissionResolver(t *testing.T) {
+	policy := auth.DefaultPolicy()
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{
+			Name: "Query",
+			Fields: graphql.Fields{
+				"secret": &graphql.Field{
+					Type: graphql.String,
+					Resolve: policy.RequirePermissionResolver(auth.PermUsersWrite, func(p graphql.ResolveParams) (interface{}, error) {
+						return "resolved", nil
+					}),
+				},
+				"admin": &graphql.Field{
+					Type: graphql.String,
+					Resolve: auth.RequireRolesResolver([]string{"admin"}, func(p graphql.ResolveParams) (interface{}, error) {
+						return "resolved", nil
+					}),
+				},
+			},
+		}),
+	})
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	run := func(ctx context.Context, query string) *graphql.Result {
+		return graphql.Do(graphql.Params{Schema: schema, RequestString: query, Context: ctx})
+	}
+
+	tests := map[string]struct {
+		ctx     context.Context
+		query   string
+		message string // Expected error, empty if the field resolves
+	}{
+		"no claims":     {context.Background(), "{ secret }", auth.ErrUnauthenticated.Error()},
+		"missing":       {withClaims(&auth.Claims{UserID: "u", Roles: []string{"user"}}), "{ secret }", "forbidden: missing permission users:write"},
+		"granted":       {withClaims(&auth.Claims{UserID: "u", Roles: []string{"admin"}}), "{ secret }", ""},
+		"service scope": {withClaims(&auth.Claims{PrincipalType: auth.PrincipalService, Scope: "users:write"}), "{ secret }", ""},
+		"role missing":  {withClaims(&auth.Claims{UserID: "u", Roles: []string{"user"}}), "{ admin }", "forbidden: requires one of roles admin"},
+		"role granted":  {withClaims(&auth.Claims{UserID: "u", Roles: []string{"admin"}}), "{ admin }", ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			result := run(tt.ctx, tt.query)
+			if tt.message == "" {
+				if len(result.Errors) != 0 {
+					t.Fatalf("errors = %v, want none", result.Errors)
+				}
+				return
+			}
+			if len(result.Errors) != 1 || !strings.Contains(result.Errors[0].Message, tt.message) {
+				t.Errorf("errors = %v, want %q", result.Errors, tt.message)
+			}
+		})
+	}
+}
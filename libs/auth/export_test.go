@@ -0,0 +1,11 @@
+package auth
+
+import "time"
+
+// Backdate moves the key set's last fetch and fetch attempt d into the past, as if d had elapsed.
+func (rks *RemoteKeySet) Backdate(d time.Duration) {
+	rks.mu.Lock()
+	defer rks.mu.Unlock()
+	rks.lastFetched = rks.lastFetched.Add(-d)
+	rks.lastAttempt = rks.lastAttempt.Add(-d)
+}
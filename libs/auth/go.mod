@@ -4,4 +4,7 @@ go 1.23.0
 
 toolchain go1.24.2
 
-require github.com/golang-jwt/jwt/v4 v4.5.2
+require (
+	github.com/golang-jwt/jwt/v4 v4.5.2
+	github.com/graphql-go/graphql v0.8.1
+)
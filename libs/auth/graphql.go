@@ -0,0 +1,24 @@
+package auth
+
+import "github.com/graphql-go/graphql"
+
+// RequirePermissionResolver wraps a graphql-go resolver so it only runs for callers holding the permission.
+// The returned error carries the same "forbidden: missing permission ..." message as the REST middleware.
+func (p *Policy) RequirePermissionResolver(permission string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(params graphql.ResolveParams) (interface{}, error) {
+		if err := p.Authorize(params.Context, permission); err != nil {
+			return nil, err
+		}
+		return resolve(params)
+	}
+}
+
+// RequireRolesResolver wraps a graphql-go resolver so it only runs for callers holding one of the roles.
+func RequireRolesResolver(roles []string, resolve graphql.FieldResolveFn) graphql.FieldResolveFn {
+	return func(params graphql.ResolveParams) (interface{}, error) {
+		if err := AuthorizeRoles(params.Context, roles...); err != nil {
+			return nil, err
+		}
+		return resolve(params)
+	}
+}
@@ -0,0 +1,228 @@
+package auth
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"net/http"
+	"sync"
+	"time"
+)
+
+// JSONWebKey is the public part of a signing key as described in RFC 7517.
+type JSONWebKey struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use,omitempty"`
+	Alg string `json:"alg,omitempty"`
+	// RSA
+	N string `json:"n,omitempty"`
+	E string `json:"e,omitempty"`
+	// EC
+	Crv string `json:"crv,omitempty"`
+	X   string `json:"x,omitempty"`
+	Y   string `json:"y,omitempty"`
+}
+
+// JSONWebKeySet is the document served at /.well-known/jwks.json.
+type JSONWebKeySet struct {
+	Keys []JSONWebKey `json:"keys"`
+}
+
+// NewJSONWebKey encodes an RSA or ECDSA public key as a JWK.
+func NewJSONWebKey(kid, alg string, publicKey crypto.PublicKey) (*JSONWebKey, error) {
+	switch k := publicKey.(type) {
+	case *rsa.PublicKey:
+		return &JSONWebKey{
+			Kty: "RSA",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			N:   base64.RawURLEncoding.EncodeToString(k.N.Bytes()),
+			E:   base64.RawURLEncoding.EncodeToString(big.NewInt(int64(k.E)).Bytes()),
+		}, nil
+	case *ecdsa.PublicKey:
+		size := (k.Curve.Params().BitSize + 7) / 8
+		return &JSONWebKey{
+			Kty: "EC",
+			Kid: kid,
+			Use: "sig",
+			Alg: alg,
+			Crv: k.Curve.Params().Name,
+			X:   base64.RawURLEncoding.EncodeToString(k.X.FillBytes(make([]byte, size))),
+			Y:   base64.RawURLEncoding.EncodeToString(k.Y.FillBytes(make([]byte, size))),
+		}, nil
+	}
+	return nil, fmt.Errorf("unsupported public key type %T", publicKey)
+}
+
+// PublicKey decodes the JWK back into an RSA or ECDSA public key.
+func (k *JSONWebKey) PublicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA modulus for key %s: %w", k.Kid, err)
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, fmt.Errorf("invalid RSA exponent for key %s: %w", k.Kid, err)
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %s for key %s", k.Crv, k.Kid)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC x coordinate for key %s: %w", k.Kid, err)
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, fmt.Errorf("invalid EC y coordinate for key %s: %w", k.Kid, err)
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %s for key %s", k.Kty, k.Kid)
+}
+
+type cachedKey struct {
+	key crypto.PublicKey
+	alg string
+}
+
+// RemoteKeySet verifies tokens against a JWKS document fetched over HTTP.
+// Keys are cached for refreshInterval, and an unknown kid triggers an early
+// refresh (at most once per minRefreshInterval) so rotated keys are picked up
+// without redeploying the verifying service.
+type RemoteKeySet struct {
+	url                string
+	client             *http.Client
+	refreshInterval    time.Duration
+	minRefreshInterval time.Duration
+
+	mu          sync.RWMutex
+	keys        map[string]cachedKey
+	lastFetched time.Time
+	lastAttempt time.Time
+}
+
+// NewRemoteKeySet creates a RemoteKeySet for the given JWKS URL.
+func NewRemoteKeySet(jwksURL string, refreshInterval time.Duration) *RemoteKeySet {
+	if refreshInterval <= 0 {
+		refreshInterval = 5 * time.Minute
+	}
+	return &RemoteKeySet{
+		url:                jwksURL,
+		client:             &http.Client{Timeout: 10 * time.Second},
+		refreshInterval:    refreshInterval,
+		minRefreshInterval: 10 * time.Second,
+		keys:               make(map[string]cachedKey),
+	}
+}
+
+// PublicKey implements PublicKeyProvider.
+func (rks *RemoteKeySet) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	rks.mu.RLock()
+	k, ok := rks.keys[kid]
+	stale := time.Since(rks.lastFetched) > rks.refreshInterval
+	rks.mu.RUnlock()
+	if ok && !stale {
+		return k.key, k.alg, nil
+	}
+
+	if err := rks.refresh(context.Background(), false); err != nil && !ok {
+		return nil, "", err
+	}
+
+	rks.mu.RLock()
+	defer rks.mu.RUnlock()
+	if k, ok = rks.keys[kid]; !ok {
+		return nil, "", ErrUnknownKeyID
+	}
+	return k.key, k.alg, nil
+}
+
+// Refresh fetches the JWKS document immediately.
+func (rks *RemoteKeySet) Refresh(ctx context.Context) error {
+	return rks.refresh(ctx, true)
+}
+
+func (rks *RemoteKeySet) refresh(ctx context.Context, force bool) error {
+	rks.mu.Lock()
+	if !force && time.Since(rks.lastAttempt) < rks.minRefreshInterval {
+		rks.mu.Unlock()
+		return nil // Fetched recently; don't hammer the issuer for unknown kids
+	}
+	rks.lastAttempt = time.Now()
+	rks.mu.Unlock()
+
+	// Fetched without the lock, so verifications with cached keys don't wait on a slow issuer
+	started := time.Now()
+	keys, err := rks.fetch(ctx)
+	if err != nil {
+		return err
+	}
+
+	rks.mu.Lock()
+	defer rks.mu.Unlock()
+	if started.Before(rks.lastFetched) {
+		return nil // A fetch started later has already replaced the keys
+	}
+	rks.keys = keys
+	rks.lastFetched = started
+	return nil
+}
+
+// fetch downloads the JWKS document and returns its signing keys by kid.
+func (rks *RemoteKeySet) fetch(ctx context.Context) (map[string]cachedKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, rks.url, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build JWKS request: %w", err)
+	}
+	resp, err := rks.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: %w", rks.url, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch JWKS from %s: status %d", rks.url, resp.StatusCode)
+	}
+
+	var set JSONWebKeySet
+	if err := json.NewDecoder(resp.Body).Decode(&set); err != nil {
+		return nil, fmt.Errorf("failed to decode JWKS from %s: %w", rks.url, err)
+	}
+
+	keys := make(map[string]cachedKey, len(set.Keys))
+	for i := range set.Keys {
+		jwk := &set.Keys[i]
+		if jwk.Use != "" && jwk.Use != "sig" {
+			continue
+		}
+		publicKey, err := jwk.PublicKey()
+		if err != nil {
+			continue // Skip keys we cannot use rather than rejecting the whole set
+		}
+		keys[jwk.Kid] = cachedKey{key: publicKey, alg: jwk.Alg}
+	}
+	return keys, nil
+}
+
+func writeJSON(w http.ResponseWriter, v interface{}) {
+	json.NewEncoder(w).Encode(v)
+}
@@ -0,0 +1,224 @@
+package auth_test
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/libs/auth"
+)
+
+func TestJSONWebKey_RoundTrip(t *testing.T) {
+	curveKey := func(curve elliptic.Curve) func(kid string) (*auth.SigningKey, error) {
+		return func(kid string) (*auth.SigningKey, error) {
+			privateKey, err := ecdsa.GenerateKey(curve, rand.Reader)
+			if err != nil {
+				return nil, err
+			}
+			return auth.NewSigningKey(kid, privateKey)
+		}
+	}
+	tests := map[string]struct {
+		generate func(kid string) (*auth.SigningKey, error)
+		kty, alg string
+	}{
+		"RSA":   {auth.GenerateRSASigningKey, "RSA", "RS256"},
+		"P-256": {auth.GenerateECDSASigningKey, "EC", "ES256"},
+		"P-384": {curveKey(elliptic.P384()), "EC", "ES384"},
+		"P-521": {curveKey(elliptic.P521()), "EC", "ES512"},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			key, err := tt.generate("key-1")
+			if err != nil {
+				t.Fatalf("generate: %v", err)
+			}
+			set, err := auth.NewKeySet(key).JWKS()
+			if err != nil {
+				t.Fatalf("JWKS: %v", err)
+			}
+			encoded, err := json.Marshal(set)
+			if err != nil {
+				t.Fatalf("Marshal: %v", err)
+			}
+			var decoded auth.JSONWebKeySet
+			if err := json.Unmarshal(encoded, &decoded); err != nil {
+				t.Fatalf("Unmarshal: %v", err)
+			}
+			if len(decoded.Keys) != 1 {
+				t.Fatalf("decoded %d keys, want 1", len(decoded.Keys))
+			}
+			jwk := decoded.Keys[0]
+			if jwk.Kid != "key-1" || jwk.Kty != tt.kty || jwk.Alg != tt.alg || jwk.Use != "sig" {
+				t.Errorf("JWK = %+v, want kid key-1, kty %s, alg %s, use sig", jwk, tt.kty, tt.alg)
+			}
+			publicKey, err := jwk.PublicKey()
+			if err != nil {
+				t.Fatalf("PublicKey: %v", err)
+			}
+			want := key.PrivateKey.Public().(interface{ Equal(crypto.PublicKey) bool })
+			if !want.Equal(publicKey) {
+				t.Error("decoded public key differs from the signing key")
+			}
+		})
+	}
+
+	if _, err := (&auth.JSONWebKey{Kty: "EC", Kid: "key-1", Crv: "secp256k1"}).PublicKey(); err == nil {
+		t.Error("expected an unsupported curve to be rejected")
+	}
+	if _, err := (&auth.JSONWebKey{Kty: "oct", Kid: "key-1"}).PublicKey(); err == nil {
+		t.Error("expected a symmetric key to be rejected")
+	}
+}
+
+// jwksServer serves the JWKS of keys and counts the fetches.
+func jwksServer(t *testing.T, keys *auth.KeySet) (*httptest.Server, *int32) {
+	t.Helper()
+	var fetches int32
+	handler := keys.JWKSHandler()
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		atomic.AddInt32(&fetches, 1)
+		handler.ServeHTTP(w, r)
+	}))
+	t.Cleanup(server.Close)
+	return server, &fetches
+}
+
+func TestRemoteKeySet_RefetchesUnknownKidAtMostEvery10s(t *testing.T) {
+	keys := auth.NewKeySet(mustGenerate(t, "RS256", "key-1"))
+	issuer := auth.NewKeySetAuthenticator(keys)
+	server, fetches := jwksServer(t, keys)
+	remote := auth.NewRemoteKeySet(server.URL, time.Hour)
+	verifier := auth.NewPublicKeyAuthenticator(remote)
+
+	first, err := issuer.GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	for i := 0; i < 3; i++ {
+		if _, err := verifier.ParseToken(first); err != nil {
+			t.Fatalf("ParseToken: %v", err)
+		}
+	}
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Fatalf("fetches = %d after verifying with a cached key, want 1", got)
+	}
+
+	// The issuer rotates; the verifier has not seen the new kid yet.
+	keys.Rotate(mustGenerate(t, "ES256", "key-2"))
+	rotated, err := issuer.GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := verifier.ParseToken(rotated); !errors.Is(err, auth.ErrUnknownKeyID) {
+		t.Fatalf("ParseToken within 10s of the last fetch: err = %v, want ErrUnknownKeyID", err)
+	}
+	remote.Backdate(9 * time.Second)
+	if _, err := verifier.ParseToken(rotated); !errors.Is(err, auth.ErrUnknownKeyID) {
+		t.Fatalf("ParseToken 9s after the last fetch: err = %v, want ErrUnknownKeyID", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Fatalf("fetches = %d, want unknown kids not to refetch within 10s", got)
+	}
+
+	remote.Backdate(time.Second)
+	if _, err := verifier.ParseToken(rotated); err != nil {
+		t.Fatalf("ParseToken 10s after the last fetch: %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 2 {
+		t.Errorf("fetches = %d, want one refetch for the unknown kid", got)
+	}
+	if _, err := verifier.ParseToken(first); err != nil {
+		t.Errorf("token of the old key after the refetch: ParseToken: %v", err)
+	}
+
+	// Refresh is explicit and not limited.
+	if err := remote.Refresh(context.Background()); err != nil {
+		t.Fatalf("Refresh: %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 3 {
+		t.Errorf("fetches = %d after Refresh, want 3", got)
+	}
+}
+
+func TestRemoteKeySet_KeepsStaleKeysWhenIssuerIsDown(t *testing.T) {
+	keys := auth.NewKeySet(mustGenerate(t, "ES256", "key-1"))
+	server, fetches := jwksServer(t, keys)
+	remote := auth.NewRemoteKeySet(server.URL, time.Minute)
+	verifier := auth.NewPublicKeyAuthenticator(remote)
+	signed, err := auth.NewKeySetAuthenticator(keys).GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := verifier.ParseToken(signed); err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+
+	// Past the refresh interval the key is stale; the refetch fails but the cached key stays in use.
+	server.Close()
+	remote.Backdate(2 * time.Minute)
+	if _, err := verifier.ParseToken(signed); err != nil {
+		t.Errorf("ParseToken with a stale key while the issuer is down: %v", err)
+	}
+	if got := atomic.LoadInt32(fetches); got != 1 {
+		t.Errorf("fetches = %d, want the closed server not to be reached", got)
+	}
+	if _, _, err := remote.PublicKey("key-2"); err == nil {
+		t.Error("expected an unknown kid to fail while the issuer is down")
+	}
+}
+
+func TestRemoteKeySet_VerifiesWhileRefreshIsSlow(t *testing.T) {
+	keys := auth.NewKeySet(mustGenerate(t, "ES256", "key-1"))
+	handler := keys.JWKSHandler()
+	fetching, release := make(chan struct{}, 1), make(chan struct{})
+	var slow atomic.Bool
+	server := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if slow.Load() {
+			fetching <- struct{}{}
+			<-release
+		}
+		handler.ServeHTTP(w, r)
+	}))
+	defer server.Close()
+	remote := auth.NewRemoteKeySet(server.URL, time.Hour)
+	verifier := auth.NewPublicKeyAuthenticator(remote)
+	signed, err := auth.NewKeySetAuthenticator(keys).GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := verifier.ParseToken(signed); err != nil {
+		t.Fatalf("ParseToken: %v", err)
+	}
+
+	slow.Store(true)
+	refreshed := make(chan error, 1)
+	go func() { refreshed <- remote.Refresh(context.Background()) }()
+	<-fetching
+	verified := make(chan error, 1)
+	go func() {
+		_, err := verifier.ParseToken(signed)
+		verified <- err
+	}()
+	select {
+	case err := <-verified:
+		if err != nil {
+			t.Errorf("ParseToken during the refresh: %v", err)
+		}
+	case <-time.After(time.Second):
+		t.Error("ParseToken with a cached key waited for the JWKS fetch")
+	}
+	close(release)
+	if err := <-refreshed; err != nil {
+		t.Errorf("Refresh: %v", err)
+	}
+}
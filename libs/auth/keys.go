@@ -0,0 +1,227 @@
+package auth
+
+import (
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"errors"
+	"fmt"
+	"net/http"
+	"os"
+	"path/filepath"
+	"strings"
+	"sync"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// ErrUnknownKeyID is returned when a token references a key ID that is not known.
+var ErrUnknownKeyID = errors.New("unknown signing key ID")
+
+// PublicKeyProvider resolves the public key used to verify a token signed with the given key ID.
+type PublicKeyProvider interface {
+	PublicKey(kid string) (crypto.PublicKey, string, error) // Returns the key and its JWS algorithm (e.g. RS256)
+}
+
+// SigningKey is an asymmetric private key identified by a key ID (kid).
+type SigningKey struct {
+	ID         string
+	Method     jwt.SigningMethod
+	PrivateKey crypto.Signer
+}
+
+// NewSigningKey wraps an RSA or ECDSA private key and picks the matching signing method.
+func NewSigningKey(kid string, privateKey crypto.Signer) (*SigningKey, error) {
+	if kid == "" {
+		return nil, errors.New("signing key ID must not be empty")
+	}
+	switch k := privateKey.(type) {
+	case *rsa.PrivateKey:
+		return &SigningKey{ID: kid, Method: jwt.SigningMethodRS256, PrivateKey: k}, nil
+	case *ecdsa.PrivateKey:
+		switch k.Curve {
+		case elliptic.P256():
+			return &SigningKey{ID: kid, Method: jwt.SigningMethodES256, PrivateKey: k}, nil
+		case elliptic.P384():
+			return &SigningKey{ID: kid, Method: jwt.SigningMethodES384, PrivateKey: k}, nil
+		case elliptic.P521():
+			return &SigningKey{ID: kid, Method: jwt.SigningMethodES512, PrivateKey: k}, nil
+		}
+		return nil, fmt.Errorf("unsupported ECDSA curve %s", k.Curve.Params().Name)
+	}
+	return nil, fmt.Errorf("unsupported private key type %T", privateKey)
+}
+
+// GenerateRSASigningKey creates a new 2048-bit RS256 signing key.
+func GenerateRSASigningKey(kid string) (*SigningKey, error) {
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate RSA key: %w", err)
+	}
+	return NewSigningKey(kid, privateKey)
+}
+
+// GenerateECDSASigningKey creates a new P-256 ES256 signing key.
+func GenerateECDSASigningKey(kid string) (*SigningKey, error) {
+	privateKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate ECDSA key: %w", err)
+	}
+	return NewSigningKey(kid, privateKey)
+}
+
+// ParseSigningKeyPEM parses a PEM encoded RSA or ECDSA private key.
+func ParseSigningKeyPEM(kid string, pemBytes []byte) (*SigningKey, error) {
+	if rsaKey, err := jwt.ParseRSAPrivateKeyFromPEM(pemBytes); err == nil {
+		return NewSigningKey(kid, rsaKey)
+	}
+	if ecKey, err := jwt.ParseECPrivateKeyFromPEM(pemBytes); err == nil {
+		return NewSigningKey(kid, ecKey)
+	}
+	return nil, fmt.Errorf("key %s is not a PEM encoded RSA or ECDSA private key", kid)
+}
+
+// LoadSigningKeyFile reads a PEM private key from disk. The key ID is the file name without extension.
+func LoadSigningKeyFile(path string) (*SigningKey, error) {
+	pemBytes, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read signing key %s: %w", path, err)
+	}
+	kid := strings.TrimSuffix(filepath.Base(path), filepath.Ext(path))
+	return ParseSigningKeyPEM(kid, pemBytes)
+}
+
+// KeySet holds the active signing keys of a token issuer.
+// The primary key signs new tokens; the others stay available for verification
+// so tokens issued before a rotation remain valid until they expire.
+type KeySet struct {
+	mu      sync.RWMutex
+	keys    map[string]*SigningKey
+	order   []string
+	primary string
+}
+
+// NewKeySet creates a KeySet. The first key becomes the primary signing key.
+func NewKeySet(keys ...*SigningKey) *KeySet {
+	ks := &KeySet{keys: make(map[string]*SigningKey)}
+	for _, k := range keys {
+		ks.AddKey(k)
+	}
+	return ks
+}
+
+// AddKey adds a key to the set. If the set was empty it becomes the primary key.
+func (ks *KeySet) AddKey(key *SigningKey) {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if _, exists := ks.keys[key.ID]; !exists {
+		ks.order = append(ks.order, key.ID)
+	}
+	ks.keys[key.ID] = key
+	if ks.primary == "" {
+		ks.primary = key.ID
+	}
+}
+
+// Rotate adds a new key and makes it the primary signing key.
+func (ks *KeySet) Rotate(key *SigningKey) {
+	ks.AddKey(key)
+	ks.mu.Lock()
+	ks.primary = key.ID
+	ks.mu.Unlock()
+}
+
+// RemoveKey retires a key. The primary key cannot be removed.
+func (ks *KeySet) RemoveKey(kid string) error {
+	ks.mu.Lock()
+	defer ks.mu.Unlock()
+	if kid == ks.primary {
+		return fmt.Errorf("cannot remove primary signing key %s", kid)
+	}
+	if _, ok := ks.keys[kid]; !ok {
+		return ErrUnknownKeyID
+	}
+	delete(ks.keys, kid)
+	for i, id := range ks.order {
+		if id == kid {
+			ks.order = append(ks.order[:i], ks.order[i+1:]...)
+			break
+		}
+	}
+	return nil
+}
+
+// Primary returns the key used to sign new tokens.
+func (ks *KeySet) Primary() (*SigningKey, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok := ks.keys[ks.primary]
+	if !ok {
+		return nil, errors.New("key set has no signing keys")
+	}
+	return key, nil
+}
+
+// PublicKey implements PublicKeyProvider.
+func (ks *KeySet) PublicKey(kid string) (crypto.PublicKey, string, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	key, ok := ks.keys[kid]
+	if !ok {
+		return nil, "", ErrUnknownKeyID
+	}
+	return key.PrivateKey.Public(), key.Method.Alg(), nil
+}
+
+// JWKS returns the public half of every key in the set.
+func (ks *KeySet) JWKS() (*JSONWebKeySet, error) {
+	ks.mu.RLock()
+	defer ks.mu.RUnlock()
+	set := &JSONWebKeySet{Keys: make([]JSONWebKey, 0, len(ks.order))}
+	for _, kid := range ks.order {
+		key := ks.keys[kid]
+		jwk, err := NewJSONWebKey(kid, key.Method.Alg(), key.PrivateKey.Public())
+		if err != nil {
+			return nil, err
+		}
+		set.Keys = append(set.Keys, *jwk)
+	}
+	return set, nil
+}
+
+// JWKSHandler serves the key set at /.well-known/jwks.json.
+func (ks *KeySet) JWKSHandler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		set, err := ks.JWKS()
+		if err != nil {
+			http.Error(w, "Failed to build key set", http.StatusInternalServerError)
+			return
+		}
+		w.Header().Set("Content-Type", "application/json")
+		w.Header().Set("Cache-Control", "public, max-age=300")
+		writeJSON(w, set)
+	})
+}
+
+// LoadKeySetFiles loads PEM private keys from disk into a KeySet.
+// The first file becomes the primary signing key.
+func LoadKeySetFiles(paths ...string) (*KeySet, error) {
+	ks := NewKeySet()
+	for _, path := range paths {
+		path = strings.TrimSpace(path)
+		if path == "" {
+			continue
+		}
+		key, err := LoadSigningKeyFile(path)
+		if err != nil {
+			return nil, err
+		}
+		ks.AddKey(key)
+	}
+	if _, err := ks.Primary(); err != nil {
+		return nil, err
+	}
+	return ks, nil
+}
@@ -0,0 +1,212 @@
+package auth_test
+
+import (
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/pem"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/omni-compos/digital-mono/libs/auth"
+)
+
+var keyGenerators = map[string]func(kid string) (*auth.SigningKey, error){
+	"RS256": auth.GenerateRSASigningKey,
+	"ES256": auth.GenerateECDSASigningKey,
+}
+
+func mustGenerate(t *testing.T, alg, kid string) *auth.SigningKey {
+	t.Helper()
+	key, err := keyGenerators[alg](kid)
+	if err != nil {
+		t.Fatalf("generate %s key: %v", alg, err)
+	}
+	return key
+}
+
+func TestKeySetAuthenticator_SignAndVerify(t *testing.T) {
+	for alg := range keyGenerators {
+		t.Run(alg, func(t *testing.T) {
+			keys := auth.NewKeySet(mustGenerate(t, alg, "key-1"))
+			issuer := auth.NewKeySetAuthenticator(keys)
+
+			signed, err := issuer.GenerateUserToken("user-1", []string{"admin"}, []string{"BRAND_A"}, time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateUserToken: %v", err)
+			}
+			token, _, err := new(jwt.Parser).ParseUnverified(signed, &auth.Claims{})
+			if err != nil {
+				t.Fatalf("ParseUnverified: %v", err)
+			}
+			if token.Header["alg"] != alg || token.Header["kid"] != "key-1" {
+				t.Errorf("header = %v, want alg %s and kid key-1", token.Header, alg)
+			}
+
+			// Verifiers only holding the public keys accept the token too.
+			for name, verifier := range map[string]*auth.JWTAuthenticator{"issuer": issuer, "public keys": auth.NewPublicKeyAuthenticator(keys)} {
+				claims, err := verifier.ParseToken(signed)
+				if err != nil {
+					t.Fatalf("%s: ParseToken: %v", name, err)
+				}
+				if claims.UserID != "user-1" || len(claims.Brands) != 1 || claims.Brands[0] != "BRAND_A" {
+					t.Errorf("%s: claims = %+v", name, claims)
+				}
+			}
+
+			// A token signed by another key with the same kid must not verify.
+			forged, err := auth.NewKeySetAuthenticator(auth.NewKeySet(mustGenerate(t, alg, "key-1"))).GenerateToken("user-1", nil, time.Minute)
+			if err != nil {
+				t.Fatalf("GenerateToken: %v", err)
+			}
+			if _, err := issuer.ParseToken(forged); err == nil {
+				t.Error("expected a token signed by a foreign key to be rejected")
+			}
+		})
+	}
+}
+
+func TestKeySet_RotationAndRemoval(t *testing.T) {
+	oldKey, newKey := mustGenerate(t, "RS256", "key-1"), mustGenerate(t, "ES256", "key-2")
+	keys := auth.NewKeySet(oldKey)
+	issuer := auth.NewKeySetAuthenticator(keys)
+	before, err := issuer.GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+
+	keys.Rotate(newKey)
+	if primary, _ := keys.Primary(); primary.ID != "key-2" {
+		t.Fatalf("primary = %s after rotation, want key-2", primary.ID)
+	}
+	after, err := issuer.GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if token, _, _ := new(jwt.Parser).ParseUnverified(after, &auth.Claims{}); token.Header["kid"] != "key-2" {
+		t.Errorf("kid = %v after rotation, want key-2", token.Header["kid"])
+	}
+	for name, signed := range map[string]string{"before rotation": before, "after rotation": after} {
+		if _, err := issuer.ParseToken(signed); err != nil {
+			t.Errorf("token issued %s: ParseToken: %v", name, err)
+		}
+	}
+
+	if err := keys.RemoveKey("key-2"); err == nil {
+		t.Error("RemoveKey removed the primary key")
+	}
+	if err := keys.RemoveKey("missing"); !errors.Is(err, auth.ErrUnknownKeyID) {
+		t.Errorf("RemoveKey(missing) = %v, want ErrUnknownKeyID", err)
+	}
+	if err := keys.RemoveKey("key-1"); err != nil {
+		t.Fatalf("RemoveKey(key-1): %v", err)
+	}
+	if _, err := issuer.ParseToken(before); !errors.Is(err, auth.ErrUnknownKeyID) {
+		t.Errorf("token of a removed key: err = %v, want ErrUnknownKeyID", err)
+	}
+	if _, err := issuer.ParseToken(after); err != nil {
+		t.Errorf("token of the primary key: ParseToken: %v", err)
+	}
+	set, err := keys.JWKS()
+	if err != nil {
+		t.Fatalf("JWKS: %v", err)
+	}
+	if len(set.Keys) != 1 || set.Keys[0].Kid != "key-2" {
+		t.Errorf("JWKS = %+v, want only key-2", set.Keys)
+	}
+}
+
+func TestJWTAuthenticator_RejectsUnexpectedSigningMethods(t *testing.T) {
+	ecKey, rsaKey := mustGenerate(t, "ES256", "ec-key"), mustGenerate(t, "RS256", "rsa-key")
+	verifier := auth.NewKeySetAuthenticator(auth.NewKeySet(ecKey, rsaKey))
+	claims := func() *auth.Claims {
+		return &auth.Claims{UserID: "user-1", RegisteredClaims: jwt.RegisteredClaims{ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Minute))}}
+	}
+	sign := func(t *testing.T, method jwt.SigningMethod, kid string, key interface{}) string {
+		t.Helper()
+		token := jwt.NewWithClaims(method, claims())
+		if kid != "" {
+			token.Header["kid"] = kid
+		}
+		signed, err := token.SignedString(key)
+		if err != nil {
+			t.Fatalf("SignedString: %v", err)
+		}
+		return signed
+	}
+	publicPEM := func(t *testing.T) []byte {
+		t.Helper()
+		der, err := x509.MarshalPKIXPublicKey(rsaKey.PrivateKey.Public().(*rsa.PublicKey))
+		if err != nil {
+			t.Fatalf("MarshalPKIXPublicKey: %v", err)
+		}
+		return pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der})
+	}
+
+	tests := map[string]struct {
+		token   func(t *testing.T) string
+		message string
+	}{
+		"alg does not match the kid's key": {
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, "ec-key", rsaKey.PrivateKey) },
+			message: "does not match key ec-key",
+		},
+		"HS256 without a configured secret": {
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, "", []byte("guessed-secret")) },
+			message: "unexpected signing method",
+		},
+		"HS256 keyed with the public key": {
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodHS256, "rsa-key", publicPEM(t)) },
+			message: "unexpected signing method",
+		},
+		"none": {
+			token: func(t *testing.T) string {
+				return sign(t, jwt.SigningMethodNone, "rsa-key", jwt.UnsafeAllowNoneSignatureType)
+			},
+			message: "",
+		},
+		"no kid": {
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, "", rsaKey.PrivateKey) },
+			message: "no kid header",
+		},
+		"unknown kid": {
+			token:   func(t *testing.T) string { return sign(t, jwt.SigningMethodRS256, "retired-key", rsaKey.PrivateKey) },
+			message: auth.ErrUnknownKeyID.Error(),
+		},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			_, err := verifier.ParseToken(tt.token(t))
+			if err == nil {
+				t.Fatal("expected the token to be rejected")
+			}
+			if !strings.Contains(err.Error(), tt.message) {
+				t.Errorf("err = %v, want it to mention %q", err, tt.message)
+			}
+		})
+	}
+
+	// The RSA key itself still verifies properly signed tokens.
+	if _, err := verifier.ParseToken(sign(t, jwt.SigningMethodRS256, "rsa-key", rsaKey.PrivateKey)); err != nil {
+		t.Errorf("ParseToken: %v", err)
+	}
+}
+
+func TestJWTAuthenticator_HS256OnlyWithSecret(t *testing.T) {
+	legacy := auth.NewJWTAuthenticator("shared-secret")
+	signed, err := legacy.GenerateToken("user-1", nil, time.Minute)
+	if err != nil {
+		t.Fatalf("GenerateToken: %v", err)
+	}
+	if _, err := legacy.ParseToken(signed); err != nil {
+		t.Errorf("authenticator with the secret: ParseToken: %v", err)
+	}
+	if _, err := auth.NewJWTAuthenticator("other-secret").ParseToken(signed); err == nil {
+		t.Error("authenticator with another secret accepted the token")
+	}
+	if _, err := auth.NewPublicKeyAuthenticator(auth.NewKeySet(mustGenerate(t, "RS256", "key-1"))).ParseToken(signed); err == nil {
+		t.Error("authenticator without a secret accepted an HS256 token")
+	}
+}
@@ -0,0 +1,222 @@
+package auth
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// OIDCConfig configures an OpenID Connect relying party.
+type OIDCConfig struct {
+	IssuerURL    string   // e.g. https://login.example.com/realms/corp; discovery is read from <issuer>/.well-known/openid-configuration
+	ClientID     string   // Expected audience of ID tokens
+	ClientSecret string   // Used for the authorization-code exchange
+	RedirectURL  string   // Callback registered with the IdP
+	Scopes       []string // Defaults to openid, profile, email
+	UserIDClaim  string   // Claim mapped to Claims.UserID, defaults to "sub"
+	RolesClaim   string   // Claim mapped to Claims.Roles (string array or space separated), defaults to "roles"
+	DefaultRoles []string // Roles used when the ID token carries none
+	BrandsClaim  string   // Claim mapped to Claims.Brands, defaults to "brands"
+}
+
+// OIDCProviderMetadata is the subset of the discovery document used by OIDCProvider.
+type OIDCProviderMetadata struct {
+	Issuer                string `json:"issuer"`
+	AuthorizationEndpoint string `json:"authorization_endpoint"`
+	TokenEndpoint         string `json:"token_endpoint"`
+	JWKSURI               string `json:"jwks_uri"`
+	UserinfoEndpoint      string `json:"userinfo_endpoint,omitempty"`
+}
+
+// OIDCTokenResponse is the token endpoint response of the authorization-code grant.
+type OIDCTokenResponse struct {
+	AccessToken  string `json:"access_token"`
+	IDToken      string `json:"id_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"`
+	RefreshToken string `json:"refresh_token,omitempty"`
+}
+
+// OIDCProvider verifies ID tokens issued by an external OpenID Connect provider and drives
+// the authorization-code flow against it.
+type OIDCProvider struct {
+	config   OIDCConfig
+	metadata OIDCProviderMetadata
+	verifier *JWTAuthenticator // Verify-only, backed by the provider's JWKS
+	client   *http.Client
+}
+
+// NewOIDCProvider reads the provider's discovery document and prepares its JWKS.
+func NewOIDCProvider(ctx context.Context, config OIDCConfig) (*OIDCProvider, error) {
+	if config.IssuerURL == "" || config.ClientID == "" {
+		return nil, errors.New("OIDC issuer URL and client ID are required")
+	}
+	if len(config.Scopes) == 0 {
+		config.Scopes = []string{"openid", "profile", "email"}
+	}
+	if config.UserIDClaim == "" {
+		config.UserIDClaim = "sub"
+	}
+	if config.RolesClaim == "" {
+		config.RolesClaim = "roles"
+	}
+	if config.BrandsClaim == "" {
+		config.BrandsClaim = "brands"
+	}
+	client := &http.Client{Timeout: 10 * time.Second}
+
+	discoveryURL := strings.TrimSuffix(config.IssuerURL, "/") + "/.well-known/openid-configuration"
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, discoveryURL, nil)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OIDC discovery request: %w", err)
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to fetch OIDC discovery document: status %d", resp.StatusCode)
+	}
+	var metadata OIDCProviderMetadata
+	if err := json.NewDecoder(resp.Body).Decode(&metadata); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC discovery document: %w", err)
+	}
+	// The issuer in the document must be the one we were configured with (OIDC Discovery 1.0, section 4.3)
+	if strings.TrimSuffix(metadata.Issuer, "/") != strings.TrimSuffix(config.IssuerURL, "/") {
+		return nil, fmt.Errorf("OIDC issuer mismatch: configured %s, discovered %s", config.IssuerURL, metadata.Issuer)
+	}
+	if metadata.JWKSURI == "" || metadata.TokenEndpoint == "" || metadata.AuthorizationEndpoint == "" {
+		return nil, errors.New("OIDC discovery document is missing required endpoints")
+	}
+
+	return &OIDCProvider{
+		config:   config,
+		metadata: metadata,
+		verifier: NewPublicKeyAuthenticator(NewRemoteKeySet(metadata.JWKSURI, time.Hour)),
+		client:   client,
+	}, nil
+}
+
+// Metadata returns the provider's discovery metadata.
+func (p *OIDCProvider) Metadata() OIDCProviderMetadata {
+	return p.metadata
+}
+
+// AuthCodeURL returns the URL to redirect the browser to for login.
+// state protects the callback against CSRF, nonce binds the ID token to this login attempt.
+func (p *OIDCProvider) AuthCodeURL(state, nonce string) string {
+	params := url.Values{}
+	params.Set("response_type", "code")
+	params.Set("client_id", p.config.ClientID)
+	params.Set("redirect_uri", p.config.RedirectURL)
+	params.Set("scope", strings.Join(p.config.Scopes, " "))
+	params.Set("state", state)
+	params.Set("nonce", nonce)
+
+	separator := "?"
+	if strings.Contains(p.metadata.AuthorizationEndpoint, "?") {
+		separator = "&"
+	}
+	return p.metadata.AuthorizationEndpoint + separator + params.Encode()
+}
+
+// Exchange redeems an authorization code at the provider's token endpoint.
+func (p *OIDCProvider) Exchange(ctx context.Context, code string) (*OIDCTokenResponse, error) {
+	form := url.Values{}
+	form.Set("grant_type", "authorization_code")
+	form.Set("code", code)
+	form.Set("redirect_uri", p.config.RedirectURL)
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, p.metadata.TokenEndpoint, strings.NewReader(form.Encode()))
+	if err != nil {
+		return nil, fmt.Errorf("failed to build OIDC token request: %w", err)
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	req.SetBasicAuth(url.QueryEscape(p.config.ClientID), url.QueryEscape(p.config.ClientSecret))
+
+	resp, err := p.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to exchange authorization code: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("failed to exchange authorization code: status %d", resp.StatusCode)
+	}
+	var tokens OIDCTokenResponse
+	if err := json.NewDecoder(resp.Body).Decode(&tokens); err != nil {
+		return nil, fmt.Errorf("failed to decode OIDC token response: %w", err)
+	}
+	if tokens.IDToken == "" {
+		return nil, errors.New("OIDC token response has no id_token")
+	}
+	return &tokens, nil
+}
+
+// VerifyIDToken checks the ID token's signature, issuer, audience, expiry and, if given, nonce,
+// and maps it to Claims. UserID, Roles and Brands come from the configured claims.
+func (p *OIDCProvider) VerifyIDToken(ctx context.Context, rawIDToken, nonce string) (*Claims, error) {
+	raw := jwt.MapClaims{}
+	token, err := jwt.ParseWithClaims(rawIDToken, raw, p.verifier.keyFunc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid ID token: %w", err)
+	}
+	if !token.Valid {
+		return nil, errors.New("invalid ID token")
+	}
+	if !raw.VerifyIssuer(p.metadata.Issuer, true) {
+		return nil, errors.New("invalid ID token: unexpected issuer")
+	}
+	if !raw.VerifyAudience(p.config.ClientID, true) {
+		return nil, errors.New("invalid ID token: audience does not include " + p.config.ClientID)
+	}
+	if _, ok := raw["exp"]; !ok {
+		return nil, errors.New("invalid ID token: no exp claim")
+	}
+	if nonce != "" {
+		if tokenNonce, _ := raw["nonce"].(string); tokenNonce != nonce {
+			return nil, errors.New("invalid ID token: nonce mismatch")
+		}
+	}
+
+	userID, _ := raw[p.config.UserIDClaim].(string)
+	if userID == "" {
+		return nil, fmt.Errorf("invalid ID token: no %s claim", p.config.UserIDClaim)
+	}
+	roles := stringsClaim(raw[p.config.RolesClaim])
+	if len(roles) == 0 {
+		roles = p.config.DefaultRoles
+	}
+
+	claims := &Claims{UserID: userID, Roles: roles, Brands: stringsClaim(raw[p.config.BrandsClaim]), PrincipalType: PrincipalUser}
+	claims.Issuer = p.metadata.Issuer
+	claims.Subject, _ = raw["sub"].(string)
+	claims.Audience = jwt.ClaimStrings{p.config.ClientID}
+	if exp, ok := raw["exp"].(float64); ok {
+		claims.ExpiresAt = jwt.NewNumericDate(time.Unix(int64(exp), 0))
+	}
+	return claims, nil
+}
+
+// stringsClaim reads a claim that is either a JSON string array or a space separated string.
+func stringsClaim(value interface{}) []string {
+	switch v := value.(type) {
+	case string:
+		return strings.Fields(v)
+	case []interface{}:
+		values := make([]string, 0, len(v))
+		for _, item := range v {
+			if s, ok := item.(string); ok && s != "" {
+				values = append(values, s)
+			}
+		}
+		return values
+	}
+	return nil
+}
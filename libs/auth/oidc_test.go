@@ -0,0 +1,136 @@
+package auth_test
+
+import (
+	"context"
+	"net/http"
+	"net/url"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/auth/oidctest"
+)
+
+func newTestOIDCProvider(t *testing.T) (*oidctest.MockIdP, *auth.OIDCProvider) {
+	t.Helper()
+	idp := oidctest.NewMockIdP("digital-mono", "s3cret")
+	t.Cleanup(idp.Close)
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:    idp.Issuer,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  "http://localhost:8081/api/v1/oidc/callback",
+		DefaultRoles: []string{"user"},
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+	return idp, provider
+}
+
+func TestOIDCProvider_AuthorizationCodeFlow(t *testing.T) {
+	idp, provider := newTestOIDCProvider(t)
+	idp.Subject = "employee-42"
+	idp.Claims["roles"] = []string{"catalog_manager"}
+
+	// Follow the login redirect by hand, as the browser would.
+	client := &http.Client{CheckRedirect: func(*http.Request, []*http.Request) error { return http.ErrUseLastResponse }}
+	resp, err := client.Get(provider.AuthCodeURL("state-1", "nonce-1"))
+	if err != nil {
+		t.Fatalf("authorize: %v", err)
+	}
+	resp.Body.Close()
+	callback, err := url.Parse(resp.Header.Get("Location"))
+	if err != nil {
+		t.Fatalf("callback URL: %v", err)
+	}
+	if got := callback.Query().Get("state"); got != "state-1" {
+		t.Fatalf("state = %q, want state-1", got)
+	}
+
+	tokens, err := provider.Exchange(context.Background(), callback.Query().Get("code"))
+	if err != nil {
+		t.Fatalf("Exchange: %v", err)
+	}
+	claims, err := provider.VerifyIDToken(context.Background(), tokens.IDToken, "nonce-1")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if claims.UserID != "employee-42" {
+		t.Errorf("UserID = %q, want employee-42", claims.UserID)
+	}
+	if len(claims.Roles) != 1 || claims.Roles[0] != "catalog_manager" {
+		t.Errorf("Roles = %v, want [catalog_manager]", claims.Roles)
+	}
+
+	if _, err := provider.Exchange(context.Background(), callback.Query().Get("code")); err == nil {
+		t.Error("Exchange accepted a code twice")
+	}
+}
+
+func TestOIDCProvider_VerifyIDTokenRejectsInvalidTokens(t *testing.T) {
+	idp, provider := newTestOIDCProvider(t)
+
+	tests := map[string]func(jwt.MapClaims){
+		"wrong audience": func(c jwt.MapClaims) { c["aud"] = "another-client" },
+		"wrong issuer":   func(c jwt.MapClaims) { c["iss"] = "https://evil.example.com" },
+		"wrong nonce":    func(c jwt.MapClaims) { c["nonce"] = "replayed" },
+		"expired":        func(c jwt.MapClaims) { c["exp"] = time.Now().Add(-time.Minute).Unix() },
+		"no exp":         func(c jwt.MapClaims) { delete(c, "exp") },
+		"no subject":     func(c jwt.MapClaims) { delete(c, "sub") },
+	}
+	for name, mutate := range tests {
+		t.Run(name, func(t *testing.T) {
+			claims := idp.DefaultClaims("nonce-1")
+			mutate(claims)
+			if _, err := provider.VerifyIDToken(context.Background(), idp.IDToken(claims), "nonce-1"); err == nil {
+				t.Error("expected the token to be rejected")
+			}
+		})
+	}
+
+	// A token signed by another IdP with the same kid must not verify.
+	other := oidctest.NewMockIdP(idp.ClientID, idp.ClientSecret)
+	defer other.Close()
+	forged := idp.DefaultClaims("nonce-1")
+	if _, err := provider.VerifyIDToken(context.Background(), other.IDToken(forged), "nonce-1"); err == nil {
+		t.Error("expected a token signed by a foreign key to be rejected")
+	}
+}
+
+func TestOIDCProvider_MapsConfiguredClaims(t *testing.T) {
+	idp := oidctest.NewMockIdP("digital-mono", "s3cret")
+	defer idp.Close()
+	provider, err := auth.NewOIDCProvider(context.Background(), auth.OIDCConfig{
+		IssuerURL:    idp.Issuer,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		UserIDClaim:  "email",
+		RolesClaim:   "groups",
+		DefaultRoles: []string{"user"},
+	})
+	if err != nil {
+		t.Fatalf("NewOIDCProvider: %v", err)
+	}
+
+	claims := idp.DefaultClaims("")
+	claims["email"] = "jane@example.com"
+	claims["groups"] = "admin seller_manager"
+	verified, err := provider.VerifyIDToken(context.Background(), idp.IDToken(claims), "")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if verified.UserID != "jane@example.com" || len(verified.Roles) != 2 {
+		t.Errorf("got UserID %q Roles %v", verified.UserID, verified.Roles)
+	}
+
+	delete(claims, "groups")
+	verified, err = provider.VerifyIDToken(context.Background(), idp.IDToken(claims), "")
+	if err != nil {
+		t.Fatalf("VerifyIDToken: %v", err)
+	}
+	if len(verified.Roles) != 1 || verified.Roles[0] != "user" {
+		t.Errorf("Roles = %v, want default [user]", verified.Roles)
+	}
+}
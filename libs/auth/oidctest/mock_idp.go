@@ -0,0 +1,151 @@
+// Package oidctest provides a mock OpenID Connect identity provider for tests.
+package oidctest
+
+import (
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"net/url"
+	"sync"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/omni-compos/digital-mono/libs/auth"
+)
+
+// MockIdP is an httptest server implementing discovery, JWKS and the authorization-code grant.
+// /authorize approves every request immediately and redirects back with a code.
+type MockIdP struct {
+	Server       *httptest.Server
+	Issuer       string
+	ClientID     string
+	ClientSecret string
+	Subject      string                 // sub of issued ID tokens
+	Claims       map[string]interface{} // Extra claims added to issued ID tokens, e.g. "roles"
+
+	keys  *auth.KeySet
+	mu    sync.Mutex
+	codes map[string]string // code -> nonce
+}
+
+// NewMockIdP starts a mock IdP for one client. Call Close when done.
+func NewMockIdP(clientID, clientSecret string) *MockIdP {
+	key, err := auth.GenerateRSASigningKey("mock-idp-key")
+	if err != nil {
+		panic(err)
+	}
+	m := &MockIdP{
+		ClientID:     clientID,
+		ClientSecret: clientSecret,
+		Subject:      "mock-user",
+		Claims:       map[string]interface{}{},
+		keys:         auth.NewKeySet(key),
+		codes:        make(map[string]string),
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/.well-known/openid-configuration", m.discovery)
+	mux.Handle("/jwks", m.keys.JWKSHandler())
+	mux.HandleFunc("/authorize", m.authorize)
+	mux.HandleFunc("/token", m.token)
+	m.Server = httptest.NewServer(mux)
+	m.Issuer = m.Server.URL
+	return m
+}
+
+// Close shuts the server down.
+func (m *MockIdP) Close() {
+	m.Server.Close()
+}
+
+// IDToken signs an ID token with the given claims, for tests that need malformed or foreign tokens.
+func (m *MockIdP) IDToken(claims jwt.MapClaims) string {
+	key, err := m.keys.Primary()
+	if err != nil {
+		panic(err)
+	}
+	token := jwt.NewWithClaims(key.Method, claims)
+	token.Header["kid"] = key.ID
+	signed, err := token.SignedString(key.PrivateKey)
+	if err != nil {
+		panic(err)
+	}
+	return signed
+}
+
+// DefaultClaims returns the claims of an ID token issued for nonce.
+func (m *MockIdP) DefaultClaims(nonce string) jwt.MapClaims {
+	now := time.Now()
+	claims := jwt.MapClaims{
+		"iss": m.Issuer,
+		"sub": m.Subject,
+		"aud": m.ClientID,
+		"iat": now.Unix(),
+		"exp": now.Add(5 * time.Minute).Unix(),
+	}
+	if nonce != "" {
+		claims["nonce"] = nonce
+	}
+	for name, value := range m.Claims {
+		claims[name] = value
+	}
+	return claims
+}
+
+func (m *MockIdP) discovery(w http.ResponseWriter, r *http.Request) {
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.OIDCProviderMetadata{
+		Issuer:                m.Issuer,
+		AuthorizationEndpoint: m.Issuer + "/authorize",
+		TokenEndpoint:         m.Issuer + "/token",
+		JWKSURI:               m.Issuer + "/jwks",
+	})
+}
+
+func (m *MockIdP) authorize(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	redirectURI, err := url.Parse(query.Get("redirect_uri"))
+	if err != nil || query.Get("client_id") != m.ClientID || query.Get("response_type") != "code" {
+		http.Error(w, "invalid authorization request", http.StatusBadRequest)
+		return
+	}
+	code := auth.NewTokenID()
+	m.mu.Lock()
+	m.codes[code] = query.Get("nonce")
+	m.mu.Unlock()
+
+	params := redirectURI.Query()
+	params.Set("code", code)
+	params.Set("state", query.Get("state"))
+	redirectURI.RawQuery = params.Encode()
+	http.Redirect(w, r, redirectURI.String(), http.StatusFound)
+}
+
+func (m *MockIdP) token(w http.ResponseWriter, r *http.Request) {
+	clientID, clientSecret, ok := r.BasicAuth()
+	if !ok || clientID != m.ClientID || clientSecret != m.ClientSecret {
+		http.Error(w, `{"error":"invalid_client"}`, http.StatusUnauthorized)
+		return
+	}
+	if err := r.ParseForm(); err != nil || r.PostForm.Get("grant_type") != "authorization_code" {
+		http.Error(w, `{"error":"unsupported_grant_type"}`, http.StatusBadRequest)
+		return
+	}
+	code := r.PostForm.Get("code")
+	m.mu.Lock()
+	nonce, found := m.codes[code]
+	delete(m.codes, code) // Codes are single use
+	m.mu.Unlock()
+	if !found {
+		http.Error(w, `{"error":"invalid_grant"}`, http.StatusBadRequest)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(auth.OIDCTokenResponse{
+		AccessToken: auth.NewTokenID(),
+		IDToken:     m.IDToken(m.DefaultClaims(nonce)),
+		TokenType:   "Bearer",
+		ExpiresIn:   300,
+	})
+}
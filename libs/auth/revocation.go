@@ -0,0 +1,156 @@
+package auth
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"sync"
+	"time"
+)
+
+// RevocationStore keeps track of access tokens that were revoked before they expired: single tokens
+// by jti, and all tokens of a user issued before a point in time.
+type RevocationStore interface {
+	Revoke(ctx context.Context, jti string, expiresAt time.Time) error
+	// RevokeUser revokes every token of the user issued before issuedBefore. Token issue times have
+	// second precision, so tokens issued within the same second are revoked too.
+	RevokeUser(ctx context.Context, userID string, issuedBefore time.Time) error
+	// IsRevoked reports whether the token with the claims was revoked by jti or with its user's tokens.
+	IsRevoked(ctx context.Context, claims *Claims) (bool, error)
+}
+
+// issuedAt returns when the token with the claims was issued, the zero time if it does not say.
+func issuedAt(claims *Claims) time.Time {
+	if claims.IssuedAt == nil {
+		return time.Time{}
+	}
+	return claims.IssuedAt.Time
+}
+
+// MemoryRevocationStore is an in-process RevocationStore, intended for tests and single-instance setups.
+type MemoryRevocationStore struct {
+	mu      sync.RWMutex
+	revoked map[string]time.Time
+	users   map[string]time.Time // Tokens of the user issued before are revoked
+}
+
+// NewMemoryRevocationStore creates an empty MemoryRevocationStore.
+func NewMemoryRevocationStore() *MemoryRevocationStore {
+	return &MemoryRevocationStore{revoked: make(map[string]time.Time), users: make(map[string]time.Time)}
+}
+
+// Revoke marks the token as revoked until expiresAt.
+func (s *MemoryRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	now := time.Now()
+	for id, exp := range s.revoked {
+		if now.After(exp) {
+			delete(s.revoked, id) // Expired tokens are rejected anyway, no need to remember them
+		}
+	}
+	s.revoked[jti] = expiresAt
+	return nil
+}
+
+// RevokeUser marks the user's tokens issued before issuedBefore as revoked.
+func (s *MemoryRevocationStore) RevokeUser(ctx context.Context, userID string, issuedBefore time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if issuedBefore.After(s.users[userID]) {
+		s.users[userID] = issuedBefore
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token has been revoked.
+func (s *MemoryRevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if exp, ok := s.revoked[claims.ID]; ok && claims.ID != "" && time.Now().Before(exp) {
+		return true, nil
+	}
+	before, ok := s.users[claims.UserID]
+	return ok && claims.UserID != "" && !issuedAt(claims).After(before), nil
+}
+
+// SQLRevocationStore is a RevocationStore backed by the revoked_tokens and revoked_user_tokens
+// tables, shared by every service that verifies tokens.
+//
+//	CREATE TABLE revoked_tokens (
+//	    jti VARCHAR(64) PRIMARY KEY,
+//	    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+//	);
+//	CREATE TABLE revoked_user_tokens (
+//	    user_id VARCHAR(255) PRIMARY KEY,
+//	    issued_before TIMESTAMP WITH TIME ZONE NOT NULL
+//	);
+type SQLRevocationStore struct {
+	db *sql.DB
+}
+
+// NewSQLRevocationStore creates a new SQLRevocationStore.
+func NewSQLRevocationStore(db *sql.DB) *SQLRevocationStore {
+	return &SQLRevocationStore{db: db}
+}
+
+// Revoke inserts the token into the revocation list.
+func (s *SQLRevocationStore) Revoke(ctx context.Context, jti string, expiresAt time.Time) error {
+	query := `INSERT INTO revoked_tokens (jti, expires_at) VALUES ($1, $2) ON CONFLICT (jti) DO NOTHING`
+	if _, err := s.db.ExecContext(ctx, query, jti, expiresAt); err != nil {
+		return fmt.Errorf("failed to revoke token %s: %w", jti, err)
+	}
+	return nil
+}
+
+// RevokeUser records that the user's tokens issued before issuedBefore are revoked.
+func (s *SQLRevocationStore) RevokeUser(ctx context.Context, userID string, issuedBefore time.Time) error {
+	query := `INSERT INTO revoked_user_tokens (user_id, issued_before) VALUES ($1, $2)
+		ON CONFLICT (user_id) DO UPDATE SET issued_before = GREATEST(revoked_user_tokens.issued_before, EXCLUDED.issued_before)`
+	if _, err := s.db.ExecContext(ctx, query, userID, issuedBefore); err != nil {
+		return fmt.Errorf("failed to revoke tokens of user %s: %w", userID, err)
+	}
+	return nil
+}
+
+// IsRevoked reports whether the token is on the revocation list or was issued before its user's
+// tokens were revoked.
+func (s *SQLRevocationStore) IsRevoked(ctx context.Context, claims *Claims) (bool, error) {
+	var revoked bool
+	query := `SELECT EXISTS (SELECT 1 FROM revoked_tokens WHERE jti = $1 AND $1 <> '' AND expires_at > NOW())
+		OR EXISTS (SELECT 1 FROM revoked_user_tokens WHERE user_id = $2 AND $2 <> '' AND issued_before >= $3)`
+	if err := s.db.QueryRowContext(ctx, query, claims.ID, claims.UserID, issuedAt(claims)).Scan(&revoked); err != nil {
+		return false, fmt.Errorf("failed to check revocation for token %s: %w", claims.ID, err)
+	}
+	return revoked, nil
+}
+
+// CheckSchema returns an error if the revocation tables do not exist. The user service creates them;
+// other services call this at startup so a database without them fails there rather than on every
+// authenticated request.
+func (s *SQLRevocationStore) CheckSchema(ctx context.Context) error {
+	return checkTables(ctx, s.db, "revoked_tokens", "revoked_user_tokens")
+}
+
+// PurgeExpired deletes revocation entries for tokens that have expired.
+func (s *SQLRevocationStore) PurgeExpired(ctx context.Context) (int64, error) {
+	result, err := s.db.ExecContext(ctx, `DELETE FROM revoked_tokens WHERE expires_at <= NOW()`)
+	if err != nil {
+		return 0, fmt.Errorf("failed to purge expired revocations: %w", err)
+	}
+	return result.RowsAffected()
+}
+
+// checkTables returns an error naming the first of tables missing from the database.
+func checkTables(ctx context.Context, db *sql.DB, tables ...string) error {
+	for _, table := range tables {
+		var name sql.NullString
+		if err := db.QueryRowContext(ctx, `SELECT to_regclass($1)::text`, table).Scan(&name); err != nil {
+			return fmt.Errorf("failed to look up table %s: %w", table, err)
+		}
+		if !name.Valid {
+			return fmt.Errorf("table %s does not exist; it is created by the user service migrations, which must run on this database", table)
+		}
+	}
+	return nil
+}
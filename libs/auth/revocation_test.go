@@ -0,0 +1,108 @@
+package auth_test
+
+import (
+	"context"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	"github.com/omni-compos/digital-mono/libs/auth"
+)
+
+func TestMemoryRevocationStore(t *testing.T) {
+	ctx := context.Background()
+	store := auth.NewMemoryRevocationStore()
+	revokedAt := time.Now()
+	issued := func(userID, jti string, at time.Time) *auth.Claims {
+		return &auth.Claims{UserID: userID, RegisteredClaims: jwt.RegisteredClaims{ID: jti, IssuedAt: jwt.NewNumericDate(at)}}
+	}
+	if err := store.Revoke(ctx, "jti-1", revokedAt.Add(time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := store.Revoke(ctx, "jti-expired", revokedAt.Add(-time.Minute)); err != nil {
+		t.Fatalf("Revoke: %v", err)
+	}
+	if err := store.RevokeUser(ctx, "user-1", revokedAt); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+	if err := store.RevokeUser(ctx, "user-1", revokedAt.Add(-time.Hour)); err != nil {
+		t.Fatalf("RevokeUser: %v", err)
+	}
+
+	tests := map[string]struct {
+		claims *auth.Claims
+		want   bool
+	}{
+		"revoked jti":                          {issued("user-2", "jti-1", revokedAt), true},
+		"expired revocation":                   {issued("user-2", "jti-expired", revokedAt), false},
+		"user token issued before":             {issued("user-1", "jti-2", revokedAt.Add(-time.Minute)), true},
+		"user token issued in the same second": {issued("user-1", "jti-2", revokedAt), true},
+		"user token issued after":              {issued("user-1", "jti-2", revokedAt.Add(2*time.Second)), false},
+		"token of another user":                {issued("user-2", "jti-2", revokedAt.Add(-time.Minute)), false},
+		"service token":                        {issued("", "jti-2", revokedAt.Add(-time.Minute)), false},
+	}
+	for name, tt := range tests {
+		revoked, err := store.IsRevoked(ctx, tt.claims)
+		if err != nil {
+			t.Fatalf("%s: IsRevoked: %v", name, err)
+		}
+		if revoked != tt.want {
+			t.Errorf("%s: IsRevoked = %v, want %v", name, revoked, tt.want)
+		}
+	}
+}
+
+func TestMiddleware_RejectsRevokedTokens(t *testing.T) {
+	authenticator := auth.NewKeySetAuthenticator(auth.NewKeySet(mustGenerate(t, "ES256", "key-1"))).
+		WithRevocationStore(auth.NewMemoryRevocationStore())
+	handler := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	status := func(token string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+token)
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+		return rec.Code
+	}
+	issue := func(userID string) (string, *auth.Claims) {
+		t.Helper()
+		token, err := authenticator.GenerateUserToken(userID, []string{"user"}, nil, time.Minute)
+		if err != nil {
+			t.Fatalf("GenerateUserToken: %v", err)
+		}
+		claims, err := authenticator.ParseToken(token)
+		if err != nil {
+			t.Fatalf("ParseToken: %v", err)
+		}
+		return token, claims
+	}
+
+	first, firstClaims := issue("user-1")
+	second, _ := issue("user-1")
+	other, _ := issue("user-2")
+	if err := authenticator.RevokeToken(context.Background(), firstClaims); err != nil {
+		t.Fatalf("RevokeToken: %v", err)
+	}
+	if got := status(first); got != http.StatusUnauthorized {
+		t.Errorf("revoked token: status = %d, want 401", got)
+	}
+	if got := status(second); got != http.StatusOK {
+		t.Errorf("other token of the user: status = %d, want 200", got)
+	}
+
+	if err := authenticator.RevokeUserTokens(context.Background(), "user-1"); err != nil {
+		t.Fatalf("RevokeUserTokens: %v", err)
+	}
+	if got := status(second); got != http.StatusUnauthorized {
+		t.Errorf("token issued before the user's tokens were revoked: status = %d, want 401", got)
+	}
+	if got := status(other); got != http.StatusOK {
+		t.Errorf("token of another user: status = %d, want 200", got)
+	}
+
+	if err := authenticator.RevokeToken(context.Background(), &auth.Claims{PrincipalType: auth.PrincipalAPIKey}); !errors.Is(err, auth.ErrNoTokenID) {
+		t.Errorf("RevokeToken without a jti = %v, want ErrNoTokenID", err)
+	}
+}
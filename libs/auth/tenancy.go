@@ -0,0 +1,78 @@
+package auth
+
+import (
+	"context"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+)
+
+// AllTenants in Claims.Brands grants access to every brand.
+const AllTenants = "*"
+
+// TenantFilter restricts data access to the brands the caller may act on.
+type TenantFilter struct {
+	All    bool     // No restriction
+	Brands []string // Allowed brands when All is false; empty means none
+}
+
+type systemTenantKey struct{}
+
+// WithSystemTenant marks ctx as an internal caller (a job, the outbox relay, a test fixture)
+// that may act on every brand without carrying claims.
+func WithSystemTenant(ctx context.Context) context.Context {
+	return context.WithValue(ctx, systemTenantKey{}, true)
+}
+
+// TenantFilterFromContext returns the tenant restriction of the caller in ctx.
+// It fails closed: a context with neither claims nor WithSystemTenant may act on no brand,
+// so a code path that drops the request context sees nothing rather than everything.
+func TenantFilterFromContext(ctx context.Context) TenantFilter {
+	if claims, ok := GetClaimsFromContext(ctx); ok {
+		return claims.TenantFilter()
+	}
+	if system, _ := ctx.Value(systemTenantKey{}).(bool); system {
+		return TenantFilter{All: true}
+	}
+	return TenantFilter{}
+}
+
+// TenantFilter returns the tenant restriction carried by the claims.
+func (c *Claims) TenantFilter() TenantFilter {
+	for _, brand := range c.Brands {
+		if brand == AllTenants {
+			return TenantFilter{All: true}
+		}
+	}
+	return TenantFilter{Brands: c.Brands}
+}
+
+// Allows reports whether the brand is within the filter.
+func (f TenantFilter) Allows(brand string) bool {
+	if f.All {
+		return true
+	}
+	for _, b := range f.Brands {
+		if b == brand {
+			return true
+		}
+	}
+	return false
+}
+
+// GenerateUserToken creates a token for an end user restricted to the given brands.
+func (a *JWTAuthenticator) GenerateUserToken(userID string, roles, brands []string, duration time.Duration) (string, error) {
+	now := time.Now()
+	claims := &Claims{
+		UserID: userID,
+		Roles:  roles,
+		Brands: brands,
+		RegisteredClaims: jwt.RegisteredClaims{
+			ExpiresAt: jwt.NewNumericDate(now.Add(duration)),
+			IssuedAt:  jwt.NewNumericDate(now),
+			Issuer:    "user-service",
+			ID:        NewTokenID(),
+		},
+	}
+	return a.SignClaims(claims)
+}
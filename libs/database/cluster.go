@@ -0,0 +1,212 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+)
+
+type readPrimaryKey struct{}
+
+// WithReadPrimary marks ctx so Cluster.Reader sends its reads to the primary, for reads that must
+// see the latest committed data (e.g. before a conditional write).
+func WithReadPrimary(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readPrimaryKey{}, true)
+}
+
+// ClusterConfig configures a Cluster. Zero values take the defaults in brackets.
+type ClusterConfig struct {
+	// ReadYourWritesWindow is how long after a write the same session reads from the primary, so
+	// it sees its own change despite replication lag [5s].
+	ReadYourWritesWindow time.Duration
+	// SessionKey identifies the caller in ctx for ReadYourWritesWindow, e.g. the authenticated user.
+	// If nil, or if it returns "", a write sends every read to the primary for the window.
+	SessionKey func(ctx context.Context) string
+	// HealthCheckInterval is how often replicas are checked [5s].
+	HealthCheckInterval time.Duration
+	// MaxReplicaLag takes a replica out of rotation while its replay lags further behind [10s].
+	MaxReplicaLag time.Duration
+	// OnReplicaStateChange, if set, is called when a replica is ejected or readmitted, e.g. to log it.
+	OnReplicaStateChange func(replica int, healthy bool, err error)
+}
+
+// Cluster routes statements between a primary and its read replicas. Repositories take writes from
+// Writer and reads from Reader: reads go round-robin to the healthy replicas, and to the primary
+// inside a transaction, with WithReadPrimary, shortly after the session wrote, or when no replica
+// is healthy. A Cluster without replicas sends everything to the primary.
+type Cluster struct {
+	primary  *sql.DB
+	replicas []*replica
+	cfg      ClusterConfig
+	next     atomic.Uint64
+
+	mu         sync.Mutex
+	lastWrites map[string]time.Time // By session key; "" for writes without a session
+}
+
+type replica struct {
+	db      *sql.DB
+	healthy atomic.Bool // False until the first health check passes
+}
+
+// NewCluster creates a Cluster. Replicas join the rotation once Run has checked them.
+func NewCluster(primary *sql.DB, replicas []*sql.DB, cfg ClusterConfig) *Cluster {
+	if cfg.ReadYourWritesWindow <= 0 {
+		cfg.ReadYourWritesWindow = 5 * time.Second
+	}
+	if cfg.HealthCheckInterval <= 0 {
+		cfg.HealthCheckInterval = 5 * time.Second
+	}
+	if cfg.MaxReplicaLag <= 0 {
+		cfg.MaxReplicaLag = 10 * time.Second
+	}
+	c := &Cluster{primary: primary, cfg: cfg, lastWrites: map[string]time.Time{}}
+	for _, db := range replicas {
+		c.replicas = append(c.replicas, &replica{db: db})
+	}
+	return c
+}
+
+// Primary returns the primary database, e.g. for a TxManager.
+func (c *Cluster) Primary() *sql.DB {
+	return c.primary
+}
+
+// Writer returns where to run a write: the transaction in ctx, or the primary. It starts the
+// session's read-your-writes window.
+func (c *Cluster) Writer(ctx context.Context) DBTX {
+	c.markWrite(ctx)
+	return Executor(ctx, c.primary)
+}
+
+// Reader returns where to run a read.
+func (c *Cluster) Reader(ctx context.Context) DBTX {
+	return Executor(ctx, c.readDB(ctx))
+}
+
+// WithPgxWriter is Writer for pgx: it calls fn with the connection of the transaction in ctx, or
+// of the primary (see WithPgx).
+func (c *Cluster) WithPgxWriter(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	c.markWrite(ctx)
+	return WithPgx(ctx, c.primary, fn)
+}
+
+// WithPgxReader is Reader for pgx.
+func (c *Cluster) WithPgxReader(ctx context.Context, fn func(conn *pgx.Conn) error) error {
+	return WithPgx(ctx, c.readDB(ctx), fn)
+}
+
+// markWrite starts the session's read-your-writes window.
+func (c *Cluster) markWrite(ctx context.Context) {
+	if len(c.replicas) > 0 {
+		c.mu.Lock()
+		c.lastWrites[c.sessionKey(ctx)] = time.Now()
+		c.mu.Unlock()
+	}
+}
+
+// readDB picks the database for a read: the primary inside a transaction on it, so the read joins it.
+func (c *Cluster) readDB(ctx context.Context) *sql.DB {
+	if len(c.replicas) == 0 {
+		return c.primary
+	}
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == c.primary {
+		return c.primary
+	}
+	if primary, _ := ctx.Value(readPrimaryKey{}).(bool); primary || c.recentlyWrote(ctx) {
+		return c.primary
+	}
+	for range c.replicas {
+		r := c.replicas[c.next.Add(1)%uint64(len(c.replicas))]
+		if r.healthy.Load() {
+			return r.db
+		}
+	}
+	return c.primary
+}
+
+func (c *Cluster) sessionKey(ctx context.Context) string {
+	if c.cfg.SessionKey == nil {
+		return ""
+	}
+	return c.cfg.SessionKey(ctx)
+}
+
+func (c *Cluster) recentlyWrote(ctx context.Context) bool {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	since := time.Now().Add(-c.cfg.ReadYourWritesWindow)
+	if c.lastWrites[""].After(since) {
+		return true
+	}
+	key := c.sessionKey(ctx)
+	return key != "" && c.lastWrites[key].After(since)
+}
+
+// Run checks the replicas every HealthCheckInterval until ctx is done, taking out of rotation those
+// that fail to answer or lag more than MaxReplicaLag. Start it in its own goroutine.
+func (c *Cluster) Run(ctx context.Context) {
+	if len(c.replicas) == 0 {
+		return
+	}
+	ticker := time.NewTicker(c.cfg.HealthCheckInterval)
+	defer ticker.Stop()
+	for {
+		for i, r := range c.replicas {
+			err := c.checkReplica(ctx, r)
+			if healthy := err == nil; r.healthy.Swap(healthy) != healthy && c.cfg.OnReplicaStateChange != nil {
+				c.cfg.OnReplicaStateChange(i, healthy, err)
+			}
+		}
+		c.forgetOldWrites()
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *Cluster) checkReplica(ctx context.Context, r *replica) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	// Lag is 0 while the replica has replayed everything it received, however old the last write
+	var lagSeconds sql.NullFloat64
+	query := `SELECT CASE WHEN pg_last_wal_receive_lsn() = pg_last_wal_replay_lsn() THEN 0
+              ELSE EXTRACT(EPOCH FROM now() - pg_last_xact_replay_timestamp()) END`
+	if err := r.db.QueryRowContext(ctx, query).Scan(&lagSeconds); err != nil {
+		return fmt.Errorf("failed to check replica: %w", err)
+	}
+	if lag := time.Duration(lagSeconds.Float64 * float64(time.Second)); lag > c.cfg.MaxReplicaLag {
+		return fmt.Errorf("replica lags %s behind the primary", lag.Round(time.Millisecond))
+	}
+	return nil
+}
+
+func (c *Cluster) forgetOldWrites() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	since := time.Now().Add(-c.cfg.ReadYourWritesWindow)
+	for key, at := range c.lastWrites {
+		if at.Before(since) {
+			delete(c.lastWrites, key)
+		}
+	}
+}
+
+// Close closes every replica, even if closing an earlier one fails. The primary is left to its owner.
+func (c *Cluster) Close() error {
+	var errs []error
+	for i, r := range c.replicas {
+		if err := r.db.Close(); err != nil {
+			errs = append(errs, fmt.Errorf("failed to close replica %d: %w", i, err))
+		}
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,148 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"errors"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+)
+
+// fakeConnector opens a *sql.DB that never connects and fails to close with closeErr.
+type fakeConnector struct{ closeErr error }
+
+func (fakeConnector) Connect(context.Context) (driver.Conn, error) {
+	return nil, errors.New("not connected")
+}
+func (fakeConnector) Driver() driver.Driver { return nil }
+func (c fakeConnector) Close() error        { return c.closeErr }
+
+func TestCluster_CloseClosesEveryReplica(t *testing.T) {
+	primary := sql.OpenDB(fakeConnector{})
+	replicas := []*sql.DB{
+		sql.OpenDB(fakeConnector{closeErr: errors.New("first")}),
+		sql.OpenDB(fakeConnector{}),
+		sql.OpenDB(fakeConnector{closeErr: errors.New("third")}),
+	}
+	err := database.NewCluster(primary, replicas, database.ClusterConfig{}).Close()
+	if err == nil || !strings.Contains(err.Error(), "replica 0: first") || !strings.Contains(err.Error(), "replica 2: third") {
+		t.Errorf("Close = %v, want the errors of replicas 0 and 2", err)
+	}
+	for i, replica := range replicas {
+		if err := replica.Ping(); err == nil || err.Error() != "sql: database is closed" {
+			t.Errorf("replica %d: Ping = %v, want it closed", i, err)
+		}
+	}
+	if err := primary.Ping(); err != nil && err.Error() == "sql: database is closed" {
+		t.Error("Close closed the primary")
+	}
+}
+
+func TestCluster_UsesPrimaryUntilReplicasAreChecked(t *testing.T) {
+	primary, replica := sql.OpenDB(fakeConnector{}), sql.OpenDB(fakeConnector{})
+	for name, cluster := range map[string]*database.Cluster{
+		"no replicas":        database.NewCluster(primary, nil, database.ClusterConfig{}),
+		"unchecked replicas": database.NewCluster(primary, []*sql.DB{replica}, database.ClusterConfig{}),
+	} {
+		if got := cluster.Reader(context.Background()); got != primary {
+			t.Errorf("%s: Reader = %v, want the primary", name, got)
+		}
+		if got := cluster.Writer(context.Background()); got != primary {
+			t.Errorf("%s: Writer = %v, want the primary", name, got)
+		}
+	}
+}
+
+type sessionKey struct{}
+
+func withSession(session string) context.Context {
+	return context.WithValue(context.Background(), sessionKey{}, session)
+}
+
+func TestCluster_RoutesReads(t *testing.T) {
+	primary := openTestDB(t)
+	cfg := databasetest.Config(t)
+	// The test database doubles as a healthy replica; the first replica is unreachable.
+	cfg.ReplicaDSNs = []string{unreachableDSN, cfg.DSN}
+	replicas, err := database.NewPgxReplicaDBs(cfg)
+	if err != nil {
+		t.Fatalf("NewPgxReplicaDBs: %v", err)
+	}
+	type stateChange struct {
+		replica int
+		healthy bool
+	}
+	changes := make(chan stateChange, 10)
+	cluster := database.NewCluster(primary, replicas, database.ClusterConfig{
+		ReadYourWritesWindow: 300 * time.Millisecond,
+		SessionKey:           func(ctx context.Context) string { s, _ := ctx.Value(sessionKey{}).(string); return s },
+		HealthCheckInterval:  50 * time.Millisecond,
+		OnReplicaStateChange: func(replica int, healthy bool, err error) { changes <- stateChange{replica, healthy} },
+	})
+	ctx, cancel := context.WithCancel(context.Background())
+	defer func() {
+		cancel()
+		cluster.Close()
+	}()
+	go cluster.Run(ctx)
+
+	waitFor := func(want stateChange) {
+		t.Helper()
+		select {
+		case got := <-changes:
+			if got != want {
+				t.Fatalf("replica state change = %+v, want %+v", got, want)
+			}
+		case <-time.After(5 * time.Second):
+			t.Fatalf("no replica state change, want %+v", want)
+		}
+	}
+	// Replica 0 failing its health check stays out of rotation; replica 1 joins it.
+	waitFor(stateChange{1, true})
+	for i := 0; i < 4; i++ {
+		if got := cluster.Reader(withSession("alice")); got != replicas[1] {
+			t.Fatalf("read %d went to %v, want the healthy replica", i, got)
+		}
+	}
+
+	if got := cluster.Reader(database.WithReadPrimary(withSession("alice"))); got != primary {
+		t.Error("WithReadPrimary read did not go to the primary")
+	}
+	err = database.NewTxManager(primary, database.TxOptions{}).WithinTx(withSession("alice"), func(ctx context.Context) error {
+		if _, ok := cluster.Reader(ctx).(*sql.Tx); !ok {
+			t.Errorf("read inside a transaction went to %T, want the transaction", cluster.Reader(ctx))
+		}
+		var one int
+		return cluster.Reader(ctx).QueryRowContext(ctx, "SELECT 1").Scan(&one)
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+
+	// After a write, the writing session reads from the primary for the window; others do not.
+	if got := cluster.Writer(withSession("alice")); got != primary {
+		t.Fatalf("Writer = %v, want the primary", got)
+	}
+	if got := cluster.Reader(withSession("alice")); got != primary {
+		t.Error("read right after the session's write did not go to the primary")
+	}
+	if got := cluster.Reader(withSession("bob")); got != replicas[1] {
+		t.Error("another session's read went to the primary")
+	}
+	time.Sleep(350 * time.Millisecond)
+	if got := cluster.Reader(withSession("alice")); got != replicas[1] {
+		t.Error("read after the window did not go back to the replica")
+	}
+
+	// A replica failing its health check is ejected.
+	replicas[1].Close()
+	waitFor(stateChange{1, false})
+	if got := cluster.Reader(withSession("bob")); got != primary {
+		t.Error("read with every replica down did not go to the primary")
+	}
+}
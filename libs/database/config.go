@@ -0,0 +1,177 @@
+package database
+
+import (
+	"fmt"
+	"net/url"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Config describes a PostgreSQL connection and its pool. Either set DSN, or the parts it is built
+// from (Host, Port, User, Password, Name, SSLMode).
+type Config struct {
+	DSN      string // Complete DSN (key=value or postgres:// URL); overrides the parts below
+	Host     string
+	Port     int
+	User     string
+	Password string
+	Name     string // Database name
+	SSLMode  string // disable, require, verify-full, ...
+
+	// ReplicaDSNs are read replicas of the database, opened by NewReplicaDBs with the same pool settings.
+	ReplicaDSNs []string
+
+	MaxOpenConns    int           // 0 means unlimited
+	MaxIdleConns    int           // 0 means database/sql's default of 2; not used by NewPgxDB
+	ConnMaxLifetime time.Duration // Recycle connections after this long, e.g. to follow failovers; 0 keeps them forever
+	ConnMaxIdleTime time.Duration // Close connections idle for this long; 0 keeps them
+	// StatementTimeout makes Postgres cancel any statement running longer; 0 disables the limit.
+	StatementTimeout time.Duration
+
+	// StatementCacheCapacity is how many prepared statements NewPgxDB caches per connection; 0 means
+	// pgx's default of 512.
+	StatementCacheCapacity int
+
+	// ConnectTimeout bounds how long NewPostgresDB and NewPgxDB keep retrying the initial ping.
+	ConnectTimeout time.Duration
+	// OnRetry, if set, is called before each retry of the initial ping, e.g. to log it.
+	OnRetry func(attempt int, wait time.Duration, err error)
+}
+
+// DefaultConfig returns the settings for the local development database (see deployments/docker-compose).
+func DefaultConfig() Config {
+	return Config{
+		Host:             "localhost",
+		Port:             5432,
+		User:             "omni_user",
+		Password:         "strong_password",
+		Name:             "digital_mono_db",
+		SSLMode:          "disable",
+		MaxOpenConns:     25,
+		MaxIdleConns:     25,
+		ConnMaxLifetime:  30 * time.Minute,
+		ConnMaxIdleTime:  5 * time.Minute,
+		StatementTimeout: 30 * time.Second,
+		ConnectTimeout:   30 * time.Second,
+	}
+}
+
+// ConfigFromEnv starts from DefaultConfig and applies DB_DSN, DB_HOST, DB_PORT, DB_USER, DB_PASSWORD,
+// DB_NAME, DB_SSLMODE, DB_REPLICA_DSNS (separated by ";"), DB_MAX_OPEN_CONNS, DB_MAX_IDLE_CONNS, DB_CONN_MAX_LIFETIME,
+// DB_CONN_MAX_IDLE_TIME, DB_STATEMENT_TIMEOUT, DB_STATEMENT_CACHE_CAPACITY and DB_CONNECT_TIMEOUT. Durations use Go syntax, e.g. "5s".
+func ConfigFromEnv() (Config, error) {
+	cfg := DefaultConfig()
+	cfg.DSN = os.Getenv("DB_DSN")
+	setString(&cfg.Host, "DB_HOST")
+	setString(&cfg.User, "DB_USER")
+	setString(&cfg.Password, "DB_PASSWORD")
+	setString(&cfg.Name, "DB_NAME")
+	setString(&cfg.SSLMode, "DB_SSLMODE")
+	for _, dsn := range strings.Split(os.Getenv("DB_REPLICA_DSNS"), ";") {
+		if dsn = strings.TrimSpace(dsn); dsn != "" {
+			cfg.ReplicaDSNs = append(cfg.ReplicaDSNs, dsn)
+		}
+	}
+	for _, setting := range []struct {
+		env string
+		set func(string) error
+	}{
+		{"DB_PORT", intSetter(&cfg.Port)},
+		{"DB_MAX_OPEN_CONNS", intSetter(&cfg.MaxOpenConns)},
+		{"DB_MAX_IDLE_CONNS", intSetter(&cfg.MaxIdleConns)},
+		{"DB_CONN_MAX_LIFETIME", durationSetter(&cfg.ConnMaxLifetime)},
+		{"DB_CONN_MAX_IDLE_TIME", durationSetter(&cfg.ConnMaxIdleTime)},
+		{"DB_STATEMENT_TIMEOUT", durationSetter(&cfg.StatementTimeout)},
+		{"DB_STATEMENT_CACHE_CAPACITY", intSetter(&cfg.StatementCacheCapacity)},
+		{"DB_CONNECT_TIMEOUT", durationSetter(&cfg.ConnectTimeout)},
+	} {
+		if value := os.Getenv(setting.env); value != "" {
+			if err := setting.set(value); err != nil {
+				return Config{}, fmt.Errorf("invalid %s %q: %w", setting.env, value, err)
+			}
+		}
+	}
+	return cfg, nil
+}
+
+// DataSourceName returns the DSN passed to the driver: DSN, or one built from the parts, with
+// StatementTimeout added as a connection parameter.
+func (c Config) DataSourceName() string {
+	dsn := c.DSN
+	if dsn == "" {
+		parts := []string{}
+		for _, part := range []struct{ key, value string }{
+			{"host", c.Host},
+			{"port", portString(c.Port)},
+			{"user", c.User},
+			{"password", c.Password},
+			{"dbname", c.Name},
+			{"sslmode", c.SSLMode},
+		} {
+			if part.value != "" {
+				parts = append(parts, part.key+"="+quoteDSNValue(part.value))
+			}
+		}
+		dsn = strings.Join(parts, " ")
+	}
+	if c.StatementTimeout <= 0 {
+		return dsn
+	}
+	// lib/pq and pgx send parameters they do not know as run-time settings of the session
+	timeout := strconv.FormatInt(c.StatementTimeout.Milliseconds(), 10)
+	if strings.HasPrefix(dsn, "postgres://") || strings.HasPrefix(dsn, "postgresql://") {
+		if u, err := url.Parse(dsn); err == nil {
+			query := u.Query()
+			query.Set("statement_timeout", timeout)
+			u.RawQuery = query.Encode()
+			return u.String()
+		}
+		return dsn
+	}
+	return dsn + " statement_timeout=" + timeout
+}
+
+func setString(field *string, env string) {
+	if value := os.Getenv(env); value != "" {
+		*field = value
+	}
+}
+
+func intSetter(field *int) func(string) error {
+	return func(value string) error {
+		n, err := strconv.Atoi(value)
+		if err != nil {
+			return err
+		}
+		*field = n
+		return nil
+	}
+}
+
+func durationSetter(field *time.Duration) func(string) error {
+	return func(value string) error {
+		d, err := time.ParseDuration(value)
+		if err != nil {
+			return err
+		}
+		*field = d
+		return nil
+	}
+}
+
+func portString(port int) string {
+	if port == 0 {
+		return ""
+	}
+	return strconv.Itoa(port)
+}
+
+// quoteDSNValue quotes a key=value DSN value if it is empty or contains spaces, quotes or backslashes.
+func quoteDSNValue(value string) string {
+	if value != "" && !strings.ContainsAny(value, ` '\`) {
+		return value
+	}
+	return "'" + strings.NewReplacer(`\`, `\\`, `'`, `\'`).Replace(value) + "'"
+}
@@ -1,14 +1,101 @@
 package database
 
 import (
+	"context"
 	"database/sql"
+	"fmt"
+	"time"
 
+	"github.com/XSAM/otelsql"
 	_ "github.com/lib/pq" // PostgreSQL driver
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
 )
 
-// NewPostgresDB creates a new PostgreSQL database connection (placeholder)
-func NewPostgresDB(dsn string) (*sql.DB, error) {
-	db, err := sql.Open("postgres", dsn)
-	return db, err
-	//return nil, fmt.Errorf("PostgreSQL connection not implemented yet. DSN: %s", dsn)
-}
\ No newline at end of file
+// Backoff between attempts of the initial ping.
+const (
+	initialRetryWait = 250 * time.Millisecond
+	maxRetryWait     = 5 * time.Second
+)
+
+// NewPostgresDB opens a PostgreSQL connection pool tuned by cfg and pings the database until it
+// answers, retrying with exponential backoff for up to cfg.ConnectTimeout (or until ctx is done).
+// Every query is traced as a child span of the span in its context, if any (see libs/tracing).
+func NewPostgresDB(ctx context.Context, cfg Config) (*sql.DB, error) {
+	db, err := openDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := pingWithRetry(ctx, db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewReplicaDBs opens a pool for each of cfg.ReplicaDSNs, with cfg's pool settings. Replicas are
+// not pinged: an unreachable replica must not stop the service, Cluster takes it out of rotation.
+func NewReplicaDBs(cfg Config) ([]*sql.DB, error) {
+	return openReplicas(cfg, openDB)
+}
+
+func openReplicas(cfg Config, open func(cfg Config) (*sql.DB, error)) ([]*sql.DB, error) {
+	var replicas []*sql.DB
+	for i, dsn := range cfg.ReplicaDSNs {
+		replicaCfg := cfg
+		replicaCfg.DSN = dsn
+		db, err := open(replicaCfg)
+		if err != nil {
+			for _, replica := range replicas {
+				replica.Close()
+			}
+			return nil, fmt.Errorf("failed to open replica %d: %w", i, err)
+		}
+		replicas = append(replicas, db)
+	}
+	return replicas, nil
+}
+
+func openDB(cfg Config) (*sql.DB, error) {
+	db, err := otelsql.Open("postgres", cfg.DataSourceName(),
+		otelsql.WithAttributes(semconv.DBSystemPostgreSQL),
+		otelsql.WithSpanOptions(otelsql.SpanOptions{OmitConnResetSession: true, OmitRows: true}),
+	)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db.SetMaxOpenConns(cfg.MaxOpenConns)
+	db.SetMaxIdleConns(cfg.MaxIdleConns)
+	db.SetConnMaxLifetime(cfg.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(cfg.ConnMaxIdleTime)
+	return db, nil
+}
+
+func pingWithRetry(ctx context.Context, db *sql.DB, cfg Config) error {
+	if cfg.ConnectTimeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, cfg.ConnectTimeout)
+		defer cancel()
+	}
+	wait := initialRetryWait
+	for attempt := 1; ; attempt++ {
+		err := db.PingContext(ctx)
+		if err == nil {
+			return nil
+		}
+		if ctx.Err() != nil {
+			return fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
+		}
+		if cfg.OnRetry != nil {
+			cfg.OnRetry(attempt, wait, err)
+		}
+		select {
+		case <-ctx.Done():
+			return fmt.Errorf("failed to connect to database after %d attempts: %w", attempt, err)
+		case <-time.After(wait):
+		}
+		wait *= 2
+		if wait > maxRetryWait {
+			wait = maxRetryWait
+		}
+	}
+}
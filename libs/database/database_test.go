@@ -0,0 +1,106 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+)
+
+// unreachableDSN points at a port nothing listens on, so connecting fails at once.
+const unreachableDSN = "postgres://omni_user@127.0.0.1:1/digital_mono_test?sslmode=disable&connect_timeout=1"
+
+// Tests using openTestDB run against the database in TEST_DATABASE_DSN and are skipped without it.
+
+// openTestDB connects to the test database through pgx; the connection is closed when the test ends.
+func openTestDB(t *testing.T) *sql.DB {
+	t.Helper()
+	db, err := database.NewPgxDB(context.Background(), databasetest.Config(t))
+	if err != nil {
+		t.Fatalf("failed to connect to test database: %v", err)
+	}
+	t.Cleanup(func() { db.Close() })
+	return db
+}
+
+// createTable (re)creates a scratch table for the test and drops it when the test ends.
+func createTable(t *testing.T, db *sql.DB, name, columns string) {
+	t.Helper()
+	ctx := context.Background()
+	if _, err := db.ExecContext(ctx, "DROP TABLE IF EXISTS "+name+"; CREATE TABLE "+name+" ("+columns+")"); err != nil {
+		t.Fatalf("failed to create %s: %v", name, err)
+	}
+	t.Cleanup(func() { db.ExecContext(ctx, "DROP TABLE IF EXISTS "+name) })
+}
+
+func TestNewPostgresDB_RetriesPingUntilConnectTimeout(t *testing.T) {
+	cfg := database.DefaultConfig()
+	cfg.DSN = unreachableDSN
+	cfg.ConnectTimeout = 800 * time.Millisecond
+	var waits []time.Duration
+	cfg.OnRetry = func(attempt int, wait time.Duration, err error) {
+		if attempt != len(waits)+1 || err == nil {
+			t.Errorf("OnRetry(%d, %s, %v) after %d retries", attempt, wait, err, len(waits))
+		}
+		waits = append(waits, wait)
+	}
+
+	start := time.Now()
+	for name, open := range map[string]func(context.Context, database.Config) (*sql.DB, error){
+		"lib/pq": database.NewPostgresDB,
+		"pgx":    database.NewPgxDB,
+	} {
+		waits = nil
+		db, err := open(context.Background(), cfg)
+		if err == nil {
+			db.Close()
+			t.Fatalf("%s: connected to %s", name, unreachableDSN)
+		}
+		if !strings.Contains(err.Error(), "failed to connect to database after") {
+			t.Errorf("%s: err = %v", name, err)
+		}
+		// 250ms, then 500ms: the next wait would end past the 800ms timeout
+		if len(waits) < 2 || waits[0] != 250*time.Millisecond || waits[1] != 500*time.Millisecond {
+			t.Errorf("%s: retry waits = %v, want 250ms doubling", name, waits)
+		}
+	}
+	if elapsed := time.Since(start); elapsed > 4*time.Second {
+		t.Errorf("gave up after %s, want about ConnectTimeout per driver", elapsed)
+	}
+
+	// A cancelled context stops the retries too.
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+	cfg.ConnectTimeout = time.Minute
+	cfg.OnRetry = nil
+	if db, err := database.NewPostgresDB(ctx, cfg); err == nil {
+		db.Close()
+		t.Fatal("connected with a cancelled context")
+	}
+}
+
+func TestReadinessHandler(t *testing.T) {
+	replicas, err := database.NewReplicaDBs(database.Config{ReplicaDSNs: []string{unreachableDSN}})
+	if err != nil {
+		t.Fatalf("NewReplicaDBs: %v", err)
+	}
+	defer replicas[0].Close()
+
+	rec := httptest.NewRecorder()
+	database.ReadinessHandler(replicas[0]).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusServiceUnavailable {
+		t.Errorf("unreachable database: status = %d, want 503", rec.Code)
+	}
+
+	rec = httptest.NewRecorder()
+	database.ReadinessHandler(openTestDB(t)).ServeHTTP(rec, httptest.NewRequest(http.MethodGet, "/ready", nil))
+	if rec.Code != http.StatusOK || rec.Body.String() != "OK" {
+		t.Errorf("reachable database: got %d %q, want 200 OK", rec.Code, rec.Body.String())
+	}
+}
@@ -0,0 +1,35 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"net/http"
+	"time"
+)
+
+// healthCheckTimeout keeps readiness probes from hanging on an unresponsive database.
+const healthCheckTimeout = 2 * time.Second
+
+// HealthCheck pings db, giving up after two seconds. It returns nil while the database is reachable.
+func HealthCheck(ctx context.Context, db *sql.DB) error {
+	ctx, cancel := context.WithTimeout(ctx, healthCheckTimeout)
+	defer cancel()
+	if err := db.PingContext(ctx); err != nil {
+		return fmt.Errorf("failed to ping database: %w", err)
+	}
+	return nil
+}
+
+// ReadinessHandler serves a readiness endpoint: 200 OK while HealthCheck passes, 503 otherwise, so
+// the orchestrator stops routing traffic to an instance that lost its database.
+func ReadinessHandler(db *sql.DB) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		if err := HealthCheck(r.Context(), db); err != nil {
+			http.Error(w, "database unavailable", http.StatusServiceUnavailable)
+			return
+		}
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	})
+}
@@ -0,0 +1,73 @@
+package database
+
+import (
+	"database/sql"
+	"errors"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
+)
+
+// NewDBStatsCollector exports db.Stats() (open, in-use and idle connections, wait count and
+// wait duration, ...) as go_sql_* metrics labeled with dbName.
+func NewDBStatsCollector(db *sql.DB, dbName string) prometheus.Collector {
+	return collectors.NewDBStatsCollector(db, dbName)
+}
+
+// QueryMetrics records query latency and errors by logical query name, e.g. "sellers.get_by_id".
+// It is a prometheus.Collector; register it next to the service's other metrics.
+// A nil *QueryMetrics records nothing, so repositories can be built without it in tests.
+type QueryMetrics struct {
+	duration *prometheus.HistogramVec
+	errors   *prometheus.CounterVec
+}
+
+// NewQueryMetrics creates query metrics under the namespace, e.g. "seller_service".
+func NewQueryMetrics(namespace string) *QueryMetrics {
+	return &QueryMetrics{
+		duration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace: namespace,
+				Subsystem: "db",
+				Name:      "query_duration_seconds",
+				Help:      "Database query latency in seconds by logical query name.",
+				Buckets:   []float64{.001, .0025, .005, .01, .025, .05, .1, .25, .5, 1, 2.5, 5},
+			},
+			[]string{"query"},
+		),
+		errors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace: namespace,
+				Subsystem: "db",
+				Name:      "query_errors_total",
+				Help:      "Total number of failed database queries by logical query name.",
+			},
+			[]string{"query"},
+		),
+	}
+}
+
+// Observe records a query that started at start and finished with err.
+// sql.ErrNoRows is a normal outcome and is not counted as an error.
+func (m *QueryMetrics) Observe(name string, start time.Time, err error) {
+	if m == nil {
+		return
+	}
+	m.duration.WithLabelValues(name).Observe(time.Since(start).Seconds())
+	if err != nil && !errors.Is(err, sql.ErrNoRows) {
+		m.errors.WithLabelValues(name).Inc()
+	}
+}
+
+// Describe implements prometheus.Collector.
+func (m *QueryMetrics) Describe(ch chan<- *prometheus.Desc) {
+	m.duration.Describe(ch)
+	m.errors.Describe(ch)
+}
+
+// Collect implements prometheus.Collector.
+func (m *QueryMetrics) Collect(ch chan<- prometheus.Metric) {
+	m.duration.Collect(ch)
+	m.errors.Collect(ch)
+}
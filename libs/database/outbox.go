@@ -0,0 +1,306 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"hash/fnv"
+	"time"
+
+	"github.com/google/uuid"
+	"github.com/lib/pq"
+)
+
+// OutboxEvent is a domain event waiting in, or read from, an outbox table.
+type OutboxEvent struct {
+	Seq           int64  // Insertion order; events of one transaction are published in this order
+	ID            string // Unique event ID; consumers use it to drop redeliveries
+	AggregateType string // e.g. "seller"
+	AggregateID   string
+	Type          string // e.g. "seller.created"
+	Payload       json.RawMessage
+	CreatedAt     time.Time
+	Attempts      int // Failed publish attempts so far
+}
+
+// Outbox stores domain events in a table of the service's own database, written in the same
+// transaction as the change they describe, so an event is recorded if and only if the change
+// commits. A Relay publishes them afterwards. A nil *Outbox records nothing.
+//
+// The table is created by the service's migrations:
+//
+//	CREATE TABLE seller_outbox (
+//	    seq BIGSERIAL PRIMARY KEY,
+//	    id VARCHAR(36) UNIQUE NOT NULL,
+//	    aggregate_type VARCHAR(50) NOT NULL,
+//	    aggregate_id VARCHAR(255) NOT NULL,
+//	    event_type VARCHAR(100) NOT NULL,
+//	    payload JSONB NOT NULL,
+//	    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//	    attempts INT NOT NULL DEFAULT 0,
+//	    last_error TEXT,
+//	    next_attempt_at TIMESTAMP WITH TIME ZONE,
+//	    published_at TIMESTAMP WITH TIME ZONE,
+//	    txid xid8 NOT NULL DEFAULT pg_current_xact_id()
+//	);
+//	CREATE INDEX idx_seller_outbox_pending ON seller_outbox(txid, seq) WHERE published_at IS NULL;
+type Outbox struct {
+	db    *sql.DB
+	table string
+}
+
+// NewOutbox creates an Outbox writing to table, e.g. "seller_outbox".
+func NewOutbox(db *sql.DB, table string) (*Outbox, error) {
+	if !tableName.MatchString(table) {
+		return nil, fmt.Errorf("invalid outbox table name %q", table)
+	}
+	return &Outbox{db: db, table: table}, nil
+}
+
+// Add records an event with payload marshalled to JSON. Call it inside the TxManager.WithinTx of
+// the change it describes; outside a transaction it is written on its own.
+func (o *Outbox) Add(ctx context.Context, aggregateType, aggregateID, eventType string, payload interface{}) error {
+	if o == nil {
+		return nil
+	}
+	data, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal %s event: %w", eventType, err)
+	}
+	query := fmt.Sprintf(`INSERT INTO %s (id, aggregate_type, aggregate_id, event_type, payload, created_at) VALUES ($1, $2, $3, $4, $5, $6)`, o.table)
+	if _, err := Executor(ctx, o.db).ExecContext(ctx, query, uuid.NewString(), aggregateType, aggregateID, eventType, string(data), time.Now()); err != nil {
+		return fmt.Errorf("failed to add %s event to outbox: %w", eventType, err)
+	}
+	return nil
+}
+
+// NewEvent is an event to record with Outbox.AddAll.
+type NewEvent struct {
+	AggregateType string
+	AggregateID   string
+	Type          string
+	Payload       interface{} // Marshalled to JSON
+}
+
+// AddAll records events in one statement, in the order given, e.g. for the changes of a bulk
+// import. Like Add, call it inside the transaction of the changes.
+func (o *Outbox) AddAll(ctx context.Context, events []NewEvent) error {
+	if o == nil || len(events) == 0 {
+		return nil
+	}
+	ids := make([]string, len(events))
+	aggregateTypes := make([]string, len(events))
+	aggregateIDs := make([]string, len(events))
+	eventTypes := make([]string, len(events))
+	payloads := make([]string, len(events))
+	for i, event := range events {
+		data, err := json.Marshal(event.Payload)
+		if err != nil {
+			return fmt.Errorf("failed to marshal %s event: %w", event.Type, err)
+		}
+		ids[i], aggregateTypes[i], aggregateIDs[i], eventTypes[i], payloads[i] = uuid.NewString(), event.AggregateType, event.AggregateID, event.Type, string(data)
+	}
+	// unnest returns the rows in array order, so seq follows the order of events
+	query := fmt.Sprintf(`INSERT INTO %s (id, aggregate_type, aggregate_id, event_type, payload, created_at)
+              SELECT id, aggregate_type, aggregate_id, event_type, payload::jsonb, $6
+              FROM unnest($1::text[], $2::text[], $3::text[], $4::text[], $5::text[]) AS e(id, aggregate_type, aggregate_id, event_type, payload)`, o.table)
+	_, err := Executor(ctx, o.db).ExecContext(ctx, query, pq.Array(ids), pq.Array(aggregateTypes), pq.Array(aggregateIDs), pq.Array(eventTypes), pq.Array(payloads), time.Now())
+	if err != nil {
+		return fmt.Errorf("failed to add %d events to outbox: %w", len(events), err)
+	}
+	return nil
+}
+
+// Publisher delivers outbox events, e.g. to a message broker. Publish may be called more than once
+// for an event (at-least-once delivery); consumers deduplicate on OutboxEvent.ID.
+type Publisher interface {
+	Publish(ctx context.Context, event OutboxEvent) error
+}
+
+// PublisherFunc adapts a function to Publisher.
+type PublisherFunc func(ctx context.Context, event OutboxEvent) error
+
+// Publish calls f.
+func (f PublisherFunc) Publish(ctx context.Context, event OutboxEvent) error {
+	return f(ctx, event)
+}
+
+// RelayConfig configures a Relay. Zero values take the defaults in brackets.
+type RelayConfig struct {
+	PollInterval time.Duration // How often to look for pending events [1s]
+	BatchSize    int           // Events published per poll [100]
+	MaxBackoff   time.Duration // Upper bound of the wait before retrying a failed event [1m]
+	Retention    time.Duration // How long published events are kept before they are deleted [24h]
+	// OnError, if set, is called with every publish or database error, e.g. to log it.
+	OnError func(err error)
+}
+
+// Relay publishes the pending events of an Outbox in order. A failed event is retried with
+// exponential backoff and holds back the events after it, so consumers never see them out of order.
+// Only one replica relays at a time: each poll takes a Postgres advisory lock on the table.
+// Marking an event published commits before the next one is published, so after a crash at most
+// the event being published is delivered again.
+//
+// seq is taken at insert, not at commit, so a transaction that commits late can add events with a
+// lower seq than ones already visible. The relay therefore orders events by the ID of the
+// transaction that wrote them (txid), then seq, and only reads events of transactions older than
+// the oldest one still running (pg_snapshot_xmin), which can no longer be overtaken. A long-running
+// transaction anywhere on the server delays publishing until it ends.
+type Relay struct {
+	outbox      *Outbox
+	publisher   Publisher
+	cfg         RelayConfig
+	lockID      int64
+	lastCleanup time.Time
+}
+
+// NewRelay creates a Relay publishing the events of outbox to publisher.
+func NewRelay(outbox *Outbox, publisher Publisher, cfg RelayConfig) *Relay {
+	if cfg.PollInterval <= 0 {
+		cfg.PollInterval = time.Second
+	}
+	if cfg.BatchSize <= 0 {
+		cfg.BatchSize = 100
+	}
+	if cfg.MaxBackoff <= 0 {
+		cfg.MaxBackoff = time.Minute
+	}
+	if cfg.Retention <= 0 {
+		cfg.Retention = 24 * time.Hour
+	}
+	hash := fnv.New64a()
+	hash.Write([]byte(outbox.table))
+	return &Relay{outbox: outbox, publisher: publisher, cfg: cfg, lockID: int64(hash.Sum64())}
+}
+
+// Run publishes pending events every PollInterval until ctx is done. Start it in its own goroutine.
+func (r *Relay) Run(ctx context.Context) {
+	ticker := time.NewTicker(r.cfg.PollInterval)
+	defer ticker.Stop()
+	for {
+		if _, err := r.PublishPending(ctx); err != nil && ctx.Err() == nil {
+			r.reportError(err)
+		}
+		if err := r.cleanup(ctx); err != nil && ctx.Err() == nil {
+			r.reportError(err)
+		}
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+// PublishPending publishes up to BatchSize pending events, stopping at the first one that fails or
+// is waiting for its retry, and returns how many it published. It returns 0 without error while
+// another replica holds the lock.
+//
+// No transaction is open while the publisher runs: the lock is held by the session and each event
+// is marked published on its own. An open transaction would hold back pg_snapshot_xmin for as long
+// as a slow publisher takes, delaying the relays of every service on the server and vacuum.
+func (r *Relay) PublishPending(ctx context.Context) (published int, err error) {
+	conn, err := r.outbox.db.Conn(ctx)
+	if err != nil {
+		return 0, fmt.Errorf("failed to get connection for outbox: %w", err)
+	}
+	defer conn.Close()
+
+	// Session-level advisory locks belong to the connection, so lock and unlock on the same one
+	var locked bool
+	if err := conn.QueryRowContext(ctx, `SELECT pg_try_advisory_lock($1)`, r.lockID).Scan(&locked); err != nil {
+		return 0, fmt.Errorf("failed to lock outbox: %w", err)
+	}
+	if !locked {
+		return 0, nil
+	}
+	defer func() {
+		if _, unlockErr := conn.ExecContext(context.Background(), `SELECT pg_advisory_unlock($1)`, r.lockID); unlockErr != nil && err == nil {
+			err = fmt.Errorf("failed to release outbox lock: %w", unlockErr)
+		}
+	}()
+
+	events, nextAttempts, err := r.pending(ctx, conn)
+	if err != nil {
+		return 0, err
+	}
+	for i, event := range events {
+		if nextAttempts[i].Valid && nextAttempts[i].Time.After(time.Now()) {
+			break // Still backing off; later events wait for it
+		}
+		if publishErr := r.publisher.Publish(ctx, event); publishErr != nil {
+			r.reportError(fmt.Errorf("failed to publish event %s (%s): %w", event.ID, event.Type, publishErr))
+			retry := fmt.Sprintf(`UPDATE %s SET attempts = attempts + 1, last_error = $2, next_attempt_at = $3 WHERE seq = $1`, r.outbox.table)
+			if _, err := conn.ExecContext(ctx, retry, event.Seq, publishErr.Error(), time.Now().Add(r.backoff(event.Attempts+1))); err != nil {
+				return published, fmt.Errorf("failed to schedule retry of event %s: %w", event.ID, err)
+			}
+			break
+		}
+		done := fmt.Sprintf(`UPDATE %s SET published_at = $2 WHERE seq = $1`, r.outbox.table)
+		if _, err := conn.ExecContext(ctx, done, event.Seq, time.Now()); err != nil {
+			return published, fmt.Errorf("failed to mark event %s published: %w", event.ID, err)
+		}
+		published++
+	}
+	return published, nil
+}
+
+func (r *Relay) pending(ctx context.Context, conn *sql.Conn) ([]OutboxEvent, []sql.NullTime, error) {
+	query := fmt.Sprintf(`SELECT seq, id, aggregate_type, aggregate_id, event_type, payload, created_at, attempts, next_attempt_at
+              FROM %s WHERE published_at IS NULL AND txid < pg_snapshot_xmin(pg_current_snapshot())
+              ORDER BY txid, seq LIMIT $1`, r.outbox.table)
+	rows, err := conn.QueryContext(ctx, query, r.cfg.BatchSize)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read pending events: %w", err)
+	}
+	defer rows.Close()
+	var events []OutboxEvent
+	var nextAttempts []sql.NullTime
+	for rows.Next() {
+		var event OutboxEvent
+		var payload []byte
+		var nextAttempt sql.NullTime
+		if err := rows.Scan(&event.Seq, &event.ID, &event.AggregateType, &event.AggregateID, &event.Type, &payload, &event.CreatedAt, &event.Attempts, &nextAttempt); err != nil {
+			return nil, nil, fmt.Errorf("failed to scan pending event: %w", err)
+		}
+		event.Payload = payload
+		events = append(events, event)
+		nextAttempts = append(nextAttempts, nextAttempt)
+	}
+	if err := rows.Err(); err != nil {
+		return nil, nil, fmt.Errorf("failed to read pending events: %w", err)
+	}
+	return events, nextAttempts, nil
+}
+
+// cleanup deletes events published longer than Retention ago, at most once a minute.
+func (r *Relay) cleanup(ctx context.Context) error {
+	if time.Since(r.lastCleanup) < time.Minute {
+		return nil
+	}
+	query := fmt.Sprintf(`DELETE FROM %s WHERE published_at < $1`, r.outbox.table)
+	if _, err := r.outbox.db.ExecContext(ctx, query, time.Now().Add(-r.cfg.Retention)); err != nil {
+		return fmt.Errorf("failed to delete published events: %w", err)
+	}
+	r.lastCleanup = time.Now()
+	return nil
+}
+
+// backoff doubles from one second with each failed attempt, up to MaxBackoff.
+func (r *Relay) backoff(attempts int) time.Duration {
+	wait := time.Second
+	for i := 1; i < attempts && wait < r.cfg.MaxBackoff; i++ {
+		wait *= 2
+	}
+	if wait > r.cfg.MaxBackoff {
+		wait = r.cfg.MaxBackoff
+	}
+	return wait
+}
+
+func (r *Relay) reportError(err error) {
+	if r.cfg.OnError != nil {
+		r.cfg.OnError(err)
+	}
+}
@@ -0,0 +1,185 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"reflect"
+	"sync"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/database"
+)
+
+const outboxColumns = `seq BIGSERIAL PRIMARY KEY,
+	id VARCHAR(36) UNIQUE NOT NULL,
+	aggregate_type VARCHAR(50) NOT NULL,
+	aggregate_id VARCHAR(255) NOT NULL,
+	event_type VARCHAR(100) NOT NULL,
+	payload JSONB NOT NULL,
+	created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+	attempts INT NOT NULL DEFAULT 0,
+	last_error TEXT,
+	next_attempt_at TIMESTAMP WITH TIME ZONE,
+	published_at TIMESTAMP WITH TIME ZONE,
+	txid xid8 NOT NULL DEFAULT pg_current_xact_id()`
+
+// recordingPublisher records the aggregate IDs it publishes and fails those listed in failures once each.
+type recordingPublisher struct {
+	mu        sync.Mutex
+	calls     []string
+	published []string
+	failures  map[string]bool
+}
+
+func (p *recordingPublisher) Publish(ctx context.Context, event database.OutboxEvent) error {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.calls = append(p.calls, event.AggregateID)
+	if p.failures[event.AggregateID] {
+		delete(p.failures, event.AggregateID)
+		return errors.New("broker unavailable")
+	}
+	p.published = append(p.published, event.AggregateID)
+	return nil
+}
+
+func newTestOutbox(t *testing.T, table string) (*sql.DB, *database.Outbox, *database.TxManager) {
+	t.Helper()
+	db := openTestDB(t)
+	createTable(t, db, table, outboxColumns)
+	outbox, err := database.NewOutbox(db, table)
+	if err != nil {
+		t.Fatalf("NewOutbox: %v", err)
+	}
+	return db, outbox, database.NewTxManager(db, database.TxOptions{})
+}
+
+func publishPending(t *testing.T, relay *database.Relay, want int) {
+	t.Helper()
+	published, err := relay.PublishPending(context.Background())
+	if err != nil {
+		t.Fatalf("PublishPending: %v", err)
+	}
+	if published != want {
+		t.Errorf("PublishPending published %d events, want %d", published, want)
+	}
+}
+
+func TestOutbox_RejectsInvalidTableNames(t *testing.T) {
+	if _, err := database.NewOutbox(nil, "seller_outbox; DROP TABLE sellers"); err == nil {
+		t.Error("expected an invalid table name to be rejected")
+	}
+	var outbox *database.Outbox
+	if err := outbox.Add(context.Background(), "seller", "1", "seller.created", nil); err != nil {
+		t.Errorf("nil Outbox: Add = %v, want nil", err)
+	}
+}
+
+func TestRelay_BackoffHoldsBackLaterEvents(t *testing.T) {
+	db, outbox, txManager := newTestOutbox(t, "outbox_backoff_test")
+	err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		return outbox.AddAll(ctx, []database.NewEvent{
+			{AggregateType: "seller", AggregateID: "1", Type: "seller.created", Payload: map[string]string{"name": "Acme"}},
+			{AggregateType: "seller", AggregateID: "2", Type: "seller.created", Payload: map[string]string{"name": "Globex"}},
+			{AggregateType: "seller", AggregateID: "3", Type: "seller.created", Payload: map[string]string{"name": "Initech"}},
+		})
+	})
+	if err != nil {
+		t.Fatalf("AddAll: %v", err)
+	}
+	publisher := &recordingPublisher{failures: map[string]bool{"2": true}}
+	var reported []error
+	relay := database.NewRelay(outbox, publisher, database.RelayConfig{OnError: func(err error) { reported = append(reported, err) }})
+
+	// Event 2 fails: event 1 stays published, event 3 waits behind event 2.
+	publishPending(t, relay, 1)
+	if !reflect.DeepEqual(publisher.calls, []string{"1", "2"}) || len(reported) != 1 {
+		t.Fatalf("publish calls = %v, reported errors = %v, want 1 and a failed 2", publisher.calls, reported)
+	}
+	var attempts int
+	var lastError string
+	var waiting bool
+	err = db.QueryRowContext(context.Background(), `SELECT attempts, last_error, next_attempt_at > now() FROM outbox_backoff_test WHERE aggregate_id = '2'`).Scan(&attempts, &lastError, &waiting)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if attempts != 1 || lastError != "broker unavailable" || !waiting {
+		t.Errorf("failed event: attempts = %d, last_error = %q, backing off = %v", attempts, lastError, waiting)
+	}
+
+	// While event 2 backs off, nothing is published, not even event 3.
+	publishPending(t, relay, 0)
+	if len(publisher.calls) != 2 {
+		t.Errorf("publish calls = %v, want no calls during the backoff", publisher.calls)
+	}
+
+	// Once the backoff has elapsed, event 2 and then event 3 go out.
+	if _, err := db.ExecContext(context.Background(), `UPDATE outbox_backoff_test SET next_attempt_at = now() - interval '1 second'`); err != nil {
+		t.Fatal(err)
+	}
+	publishPending(t, relay, 2)
+	if !reflect.DeepEqual(publisher.published, []string{"1", "2", "3"}) {
+		t.Errorf("published = %v, want the events in order", publisher.published)
+	}
+	publishPending(t, relay, 0)
+}
+
+func TestRelay_WaitsForTransactionsThatCommitLate(t *testing.T) {
+	_, outbox, txManager := newTestOutbox(t, "outbox_order_test")
+	publisher := &recordingPublisher{}
+	relay := database.NewRelay(outbox, publisher, database.RelayConfig{})
+	add := func(ctx context.Context, id string) error {
+		return outbox.Add(ctx, "seller", id, "seller.updated", map[string]string{"id": id})
+	}
+
+	// The first transaction takes the lower seq but commits after the second.
+	added, release, done := make(chan struct{}), make(chan struct{}), make(chan error)
+	go func() {
+		done <- txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+			err := add(ctx, "late")
+			close(added)
+			<-release
+			return err
+		})
+	}()
+	<-added
+	if err := txManager.WithinTx(context.Background(), func(ctx context.Context) error { return add(ctx, "early") }); err != nil {
+		close(release)
+		t.Fatalf("Add: %v", err)
+	}
+
+	publishPending(t, relay, 0) // "early" could still be overtaken by "late"
+	close(release)
+	if err := <-done; err != nil {
+		t.Fatalf("Add: %v", err)
+	}
+	publishPending(t, relay, 2)
+	if !reflect.DeepEqual(publisher.published, []string{"late", "early"}) {
+		t.Errorf("published = %v, want the events in the order of their transactions", publisher.published)
+	}
+}
+
+// A transaction left open while the publisher runs would hold back pg_snapshot_xmin, and with it
+// the relays of every service on the server.
+func TestRelay_PublishesOutsideATransaction(t *testing.T) {
+	db, outbox, _ := newTestOutbox(t, "outbox_publish_test")
+	for _, id := range []string{"a", "b"} {
+		if err := outbox.Add(context.Background(), "seller", id, "seller.updated", map[string]string{"id": id}); err != nil {
+			t.Fatalf("Add: %v", err)
+		}
+	}
+	var openTransactions []int
+	relay := database.NewRelay(outbox, database.PublisherFunc(func(ctx context.Context, event database.OutboxEvent) error {
+		var open int
+		err := db.QueryRowContext(ctx, `SELECT count(*) FROM pg_stat_activity
+              WHERE datname = current_database() AND state LIKE 'idle in transaction%'`).Scan(&open)
+		openTransactions = append(openTransactions, open)
+		return err
+	}), database.RelayConfig{})
+
+	publishPending(t, relay, 2)
+	if !reflect.DeepEqual(openTransactions, []int{0, 0}) {
+		t.Errorf("transactions open while publishing = %v, want none", openTransactions)
+	}
+}
@@ -0,0 +1,162 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"database/sql/driver"
+	"fmt"
+
+	"github.com/jackc/pgx/v5"
+	"github.com/jackc/pgx/v5/pgxpool"
+	"github.com/jackc/pgx/v5/stdlib"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/codes"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const tracerName = "github.com/omni-compos/digital-mono/libs/database"
+
+// NewPgxDB is NewPostgresDB on pgx: it opens a pgx connection pool (pgxpool) tuned by cfg and returns
+// a database/sql handle over it, so TxManager, Outbox, Migrator and Cluster work unchanged.
+// Statements are prepared on first use and cached per connection (cfg.StatementCacheCapacity);
+// repositories reach pgx itself, for batches and COPY, through WithPgx. MaxIdleConns does not apply,
+// and zero ConnMaxLifetime, ConnMaxIdleTime and MaxOpenConns take pgxpool's defaults.
+func NewPgxDB(ctx context.Context, cfg Config) (*sql.DB, error) {
+	db, err := openPgxDB(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if err := pingWithRetry(ctx, db, cfg); err != nil {
+		db.Close()
+		return nil, err
+	}
+	return db, nil
+}
+
+// NewPgxReplicaDBs is NewReplicaDBs on pgx.
+func NewPgxReplicaDBs(cfg Config) ([]*sql.DB, error) {
+	return openReplicas(cfg, openPgxDB)
+}
+
+// WithPgx calls fn with the pgx connection behind db, for what database/sql cannot express: batches
+// (SendBatch) and COPY (CopyFrom). Inside a transaction on db (see TxManager) fn gets the
+// transaction's connection, so its statements join the transaction. db must come from NewPgxDB.
+func WithPgx(ctx context.Context, db *sql.DB, fn func(conn *pgx.Conn) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == db {
+		return rawPgx(state.conn, fn)
+	}
+	conn, err := db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to get connection: %w", err)
+	}
+	defer conn.Close()
+	return rawPgx(conn, fn)
+}
+
+func rawPgx(conn *sql.Conn, fn func(conn *pgx.Conn) error) error {
+	return conn.Raw(func(driverConn any) error {
+		pgxConn, ok := driverConn.(*stdlib.Conn)
+		if !ok {
+			return fmt.Errorf("database not opened with NewPgxDB: driver connection is %T", driverConn)
+		}
+		return fn(pgxConn.Conn())
+	})
+}
+
+func openPgxDB(cfg Config) (*sql.DB, error) {
+	poolConfig, err := pgxpool.ParseConfig(cfg.DataSourceName())
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	if cfg.MaxOpenConns > 0 {
+		poolConfig.MaxConns = int32(cfg.MaxOpenConns)
+	}
+	if cfg.ConnMaxLifetime > 0 {
+		poolConfig.MaxConnLifetime = cfg.ConnMaxLifetime
+	}
+	if cfg.ConnMaxIdleTime > 0 {
+		poolConfig.MaxConnIdleTime = cfg.ConnMaxIdleTime
+	}
+	if cfg.StatementCacheCapacity > 0 {
+		poolConfig.ConnConfig.StatementCacheCapacity = cfg.StatementCacheCapacity
+	}
+	poolConfig.ConnConfig.Tracer = pgxTracer{tracer: otel.Tracer(tracerName)}
+	// Connects lazily, like sql.Open; NewPgxDB pings
+	pool, err := pgxpool.NewWithConfig(context.Background(), poolConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to open database: %w", err)
+	}
+	db := sql.OpenDB(poolConnector{Connector: stdlib.GetPoolConnector(pool), pool: pool})
+	// Idle connections belong in the pool, where WithPgx and database/sql share them
+	db.SetMaxIdleConns(0)
+	return db, nil
+}
+
+// poolConnector closes the pgx pool with the sql.DB over it.
+type poolConnector struct {
+	driver.Connector
+	pool *pgxpool.Pool
+}
+
+func (c poolConnector) Close() error {
+	c.pool.Close()
+	return nil
+}
+
+// pgxTracer records a client span for every statement, batch and COPY, whether it was run through
+// database/sql or pgx, as a child of the span in its context.
+type pgxTracer struct {
+	tracer trace.Tracer
+}
+
+func (t pgxTracer) start(ctx context.Context, name, query string) context.Context {
+	ctx, _ = t.tracer.Start(ctx, name, trace.WithSpanKind(trace.SpanKindClient),
+		trace.WithAttributes(semconv.DBSystemPostgreSQL, semconv.DBQueryText(query)))
+	return ctx
+}
+
+func endSpan(ctx context.Context, err error) {
+	span := trace.SpanFromContext(ctx)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
+
+func (t pgxTracer) TraceQueryStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryStartData) context.Context {
+	return t.start(ctx, "pgx.query", data.SQL)
+}
+
+func (t pgxTracer) TraceQueryEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceQueryEndData) {
+	endSpan(ctx, data.Err)
+}
+
+func (t pgxTracer) TraceBatchStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchStartData) context.Context {
+	query := "" // The first statement; batches usually repeat one
+	if data.Batch.Len() > 0 {
+		query = data.Batch.QueuedQueries[0].SQL
+	}
+	return t.start(ctx, "pgx.batch", query)
+}
+
+func (t pgxTracer) TraceBatchQuery(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchQueryData) {
+	if data.Err != nil {
+		trace.SpanFromContext(ctx).RecordError(data.Err)
+	}
+}
+
+func (t pgxTracer) TraceBatchEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceBatchEndData) {
+	endSpan(ctx, data.Err)
+}
+
+func (t pgxTracer) TraceCopyFromStart(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromStartData) context.Context {
+	ctx = t.start(ctx, "pgx.copy_from", "COPY "+data.TableName.Sanitize()+" FROM STDIN")
+	trace.SpanFromContext(ctx).SetAttributes(semconv.DBCollectionName(data.TableName.Sanitize()))
+	return ctx
+}
+
+func (t pgxTracer) TraceCopyFromEnd(ctx context.Context, conn *pgx.Conn, data pgx.TraceCopyFromEndData) {
+	endSpan(ctx, data.Err)
+}
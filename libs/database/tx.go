@@ -0,0 +1,167 @@
+package database
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math/rand"
+	"time"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+)
+
+// DBTX is what repositories run statements on: a *sql.DB, or the *sql.Tx of the transaction in
+// progress (see Executor).
+type DBTX interface {
+	ExecContext(ctx context.Context, query string, args ...interface{}) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...interface{}) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...interface{}) *sql.Row
+}
+
+// Transactor runs fn as one unit of work. Services depend on it rather than on *sql.DB, so the
+// repositories they call inside fn share the transaction.
+type Transactor interface {
+	WithinTx(ctx context.Context, fn func(ctx context.Context) error) error
+}
+
+// NopTransactor runs fn without a transaction, for tests and storage backends without transactions.
+type NopTransactor struct{}
+
+// WithinTx calls fn with ctx.
+func (NopTransactor) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	return fn(ctx)
+}
+
+type txKey struct{}
+
+// txState is the transaction carried in a context; depth counts the savepoints opened inside it.
+type txState struct {
+	db    *sql.DB
+	conn  *sql.Conn // Runs tx; WithPgx reaches the driver connection through it
+	tx    *sql.Tx
+	depth int
+}
+
+// Executor returns the transaction in ctx if it was started on db, and db otherwise. Repositories
+// call it for every statement so they join a transaction opened by the service:
+//
+//	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, args...)
+func Executor(ctx context.Context, db *sql.DB) DBTX {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == db {
+		return state.tx
+	}
+	return db
+}
+
+// TxOptions configures a TxManager.
+type TxOptions struct {
+	Isolation   sql.IsolationLevel // Defaults to the database's (READ COMMITTED for Postgres)
+	ReadOnly    bool
+	MaxAttempts int // Attempts for transactions hitting serialization failures or deadlocks; defaults to 3
+}
+
+// TxManager opens transactions on a database and carries them in the context.
+type TxManager struct {
+	db   *sql.DB
+	opts TxOptions
+}
+
+// NewTxManager creates a TxManager for db. The zero TxOptions are the defaults.
+func NewTxManager(db *sql.DB, opts TxOptions) *TxManager {
+	if opts.MaxAttempts <= 0 {
+		opts.MaxAttempts = 3
+	}
+	return &TxManager{db: db, opts: opts}
+}
+
+// WithinTx runs fn in a transaction that repositories using Executor(ctx, db) join. It commits when
+// fn returns nil and rolls back otherwise.
+//
+// Called inside another transaction on the same database, it runs fn in a savepoint instead, so
+// fn's changes can be rolled back without aborting the outer transaction.
+//
+// Postgres aborts transactions that hit a serialization failure or deadlock; the outermost WithinTx
+// then runs fn again, up to MaxAttempts times. fn must therefore have no effects outside the
+// database, or effects that are safe to repeat.
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	if state, ok := ctx.Value(txKey{}).(*txState); ok && state.db == m.db {
+		return m.withinSavepoint(ctx, state, fn)
+	}
+	var err error
+	for attempt := 1; attempt <= m.opts.MaxAttempts; attempt++ {
+		if err = m.runTx(ctx, fn); err == nil || !IsRetryable(err) {
+			return err
+		}
+		select {
+		case <-ctx.Done():
+			return err
+		case <-time.After(retryBackoff(attempt)):
+		}
+	}
+	return fmt.Errorf("transaction failed after %d attempts: %w", m.opts.MaxAttempts, err)
+}
+
+func (m *TxManager) runTx(ctx context.Context, fn func(ctx context.Context) error) error {
+	conn, err := m.db.Conn(ctx)
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer conn.Close()
+	tx, err := conn.BeginTx(ctx, &sql.TxOptions{Isolation: m.opts.Isolation, ReadOnly: m.opts.ReadOnly})
+	if err != nil {
+		return fmt.Errorf("failed to begin transaction: %w", err)
+	}
+	defer tx.Rollback() // No-op after Commit
+	if err := fn(context.WithValue(ctx, txKey{}, &txState{db: m.db, conn: conn, tx: tx})); err != nil {
+		return err
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("failed to commit transaction: %w", err)
+	}
+	return nil
+}
+
+func (m *TxManager) withinSavepoint(ctx context.Context, outer *txState, fn func(ctx context.Context) error) error {
+	state := &txState{db: outer.db, conn: outer.conn, tx: outer.tx, depth: outer.depth + 1}
+	savepoint := fmt.Sprintf("sp_%d", state.depth)
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("failed to create savepoint: %w", err)
+	}
+	if err := fn(context.WithValue(ctx, txKey{}, state)); err != nil {
+		if _, rollbackErr := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+savepoint); rollbackErr != nil {
+			return fmt.Errorf("failed to roll back to savepoint after %v: %w", err, rollbackErr)
+		}
+		return err
+	}
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+savepoint); err != nil {
+		return fmt.Errorf("failed to release savepoint: %w", err)
+	}
+	return nil
+}
+
+// IsRetryable reports whether err means Postgres aborted the transaction because of a concurrent
+// one (serialization_failure or deadlock_detected), so running it again may succeed. It understands
+// the errors of both lib/pq and pgx.
+func IsRetryable(err error) bool {
+	var code string
+	var pqErr *pq.Error
+	var pgErr *pgconn.PgError
+	switch {
+	case errors.As(err, &pqErr):
+		code = string(pqErr.Code)
+	case errors.As(err, &pgErr):
+		code = pgErr.Code
+	default:
+		return false
+	}
+	return code == "40001" || code == "40P01"
+}
+
+// retryBackoff waits a little longer after each attempt, with jitter so the transactions that
+// collided do not collide again.
+func retryBackoff(attempt int) time.Duration {
+	base := time.Duration(attempt) * 20 * time.Millisecond
+	return base + time.Duration(rand.Int63n(int64(base)))
+}
@@ -0,0 +1,197 @@
+package database_test
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"reflect"
+	"strings"
+	"testing"
+
+	"github.com/jackc/pgx/v5/pgconn"
+	"github.com/lib/pq"
+	"github.com/omni-compos/digital-mono/libs/database"
+)
+
+func TestIsRetryable(t *testing.T) {
+	tests := map[string]struct {
+		err  error
+		want bool
+	}{
+		"pq serialization failure":  {&pq.Error{Code: "40001"}, true},
+		"pq deadlock":               {&pq.Error{Code: "40P01"}, true},
+		"pgx serialization failure": {&pgconn.PgError{Code: "40001"}, true},
+		"wrapped pgx deadlock":      {fmt.Errorf("failed to update seller: %w", &pgconn.PgError{Code: "40P01"}), true},
+		"unique violation":          {&pgconn.PgError{Code: "23505"}, false},
+		"other error":               {errors.New("40001"), false},
+		"nil":                       {nil, false},
+	}
+	for name, tt := range tests {
+		if got := database.IsRetryable(tt.err); got != tt.want {
+			t.Errorf("%s: IsRetryable = %v, want %v", name, got, tt.want)
+		}
+	}
+}
+
+func TestNopTransactor(t *testing.T) {
+	called := false
+	err := database.NopTransactor{}.WithinTx(context.Background(), func(ctx context.Context) error {
+		called = true
+		return errors.New("failed")
+	})
+	if !called || err == nil || err.Error() != "failed" {
+		t.Errorf("called = %v, err = %v, want fn's error", called, err)
+	}
+}
+
+// raiseSerializationFailure makes Postgres abort the transaction as if it had lost a race with a
+// concurrent serializable transaction.
+const raiseSerializationFailure = `DO $$ BEGIN RAISE EXCEPTION 'could not serialize access' USING ERRCODE = 'serialization_failure'; END $$`
+
+func rowNames(t *testing.T, db *sql.DB, table string) []string {
+	t.Helper()
+	rows, err := db.QueryContext(context.Background(), "SELECT name FROM "+table+" ORDER BY id")
+	if err != nil {
+		t.Fatalf("failed to read %s: %v", table, err)
+	}
+	defer rows.Close()
+	var names []string
+	for rows.Next() {
+		var name string
+		if err := rows.Scan(&name); err != nil {
+			t.Fatal(err)
+		}
+		names = append(names, name)
+	}
+	return names
+}
+
+func TestTxManager_RetriesSerializationFailures(t *testing.T) {
+	db := openTestDB(t)
+	createTable(t, db, "tx_retry_test", "id SERIAL PRIMARY KEY, name TEXT NOT NULL")
+	txManager := database.NewTxManager(db, database.TxOptions{Isolation: sql.LevelSerializable})
+	insert := func(ctx context.Context, name string) error {
+		_, err := database.Executor(ctx, db).ExecContext(ctx, "INSERT INTO tx_retry_test (name) VALUES ($1)", name)
+		return err
+	}
+
+	attempts := 0
+	err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		if err := insert(ctx, fmt.Sprintf("attempt %d", attempts)); err != nil {
+			return err
+		}
+		if attempts == 1 {
+			_, err := database.Executor(ctx, db).ExecContext(ctx, raiseSerializationFailure)
+			return err
+		}
+		return nil
+	})
+	if err != nil || attempts != 2 {
+		t.Fatalf("WithinTx = %v after %d attempts, want success on the second", err, attempts)
+	}
+	if names := rowNames(t, db, "tx_retry_test"); !reflect.DeepEqual(names, []string{"attempt 2"}) {
+		t.Errorf("rows = %v, want only the committed retry", names)
+	}
+
+	// Every attempt fails: the error says so and is still recognisable.
+	attempts = 0
+	err = txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		_, err := database.Executor(ctx, db).ExecContext(ctx, raiseSerializationFailure)
+		return err
+	})
+	if attempts != 3 || !database.IsRetryable(err) || !strings.Contains(err.Error(), "after 3 attempts") {
+		t.Errorf("WithinTx = %v after %d attempts, want a retryable error after 3", err, attempts)
+	}
+
+	// Other errors are returned at once.
+	attempts = 0
+	err = txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		attempts++
+		_, err := database.Executor(ctx, db).ExecContext(ctx, "INSERT INTO tx_retry_test (id, name) SELECT id, 'duplicate id' FROM tx_retry_test")
+		return err
+	})
+	if err == nil || attempts != 1 {
+		t.Errorf("WithinTx = %v after %d attempts, want the unique violation without a retry", err, attempts)
+	}
+}
+
+func TestTxManager_SavepointRollback(t *testing.T) {
+	db := openTestDB(t)
+	createTable(t, db, "tx_savepoint_test", "id SERIAL PRIMARY KEY, name TEXT UNIQUE NOT NULL")
+	txManager := database.NewTxManager(db, database.TxOptions{})
+	insert := func(ctx context.Context, name string) error {
+		_, err := database.Executor(ctx, db).ExecContext(ctx, "INSERT INTO tx_savepoint_test (name) VALUES ($1)", name)
+		return err
+	}
+	errInner := errors.New("inner failed")
+
+	err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := insert(ctx, "outer"); err != nil {
+			return err
+		}
+		// A failing nested unit is rolled back to its savepoint, together with its own nested units.
+		err := txManager.WithinTx(ctx, func(ctx context.Context) error {
+			if err := insert(ctx, "inner"); err != nil {
+				return err
+			}
+			if err := txManager.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, "innermost") }); err != nil {
+				return err
+			}
+			return errInner
+		})
+		if !errors.Is(err, errInner) {
+			return fmt.Errorf("inner WithinTx = %v, want errInner", err)
+		}
+		// A statement failing in Postgres aborts only the savepoint; the outer transaction goes on.
+		if err := txManager.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, "outer") }); err == nil {
+			return errors.New("duplicate name inserted")
+		}
+		if err := txManager.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, "kept") }); err != nil {
+			return err
+		}
+		return insert(ctx, "after")
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+	if names := rowNames(t, db, "tx_savepoint_test"); !reflect.DeepEqual(names, []string{"outer", "kept", "after"}) {
+		t.Errorf("rows = %v, want the outer rows and the successful savepoint", names)
+	}
+
+	// The outer transaction failing rolls back its released savepoints too.
+	err = txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		if err := txManager.WithinTx(ctx, func(ctx context.Context) error { return insert(ctx, "released") }); err != nil {
+			return err
+		}
+		return errors.New("outer failed")
+	})
+	if err == nil {
+		t.Fatal("expected the outer error")
+	}
+	if names := rowNames(t, db, "tx_savepoint_test"); len(names) != 3 {
+		t.Errorf("rows = %v, want the released savepoint rolled back with its transaction", names)
+	}
+}
+
+func TestExecutor_JoinsOnlyTransactionsOnTheSameDB(t *testing.T) {
+	db, other := openTestDB(t), openTestDB(t)
+	txManager := database.NewTxManager(db, database.TxOptions{})
+	if got := database.Executor(context.Background(), db); got != db {
+		t.Errorf("Executor outside a transaction = %T, want the database", got)
+	}
+	err := txManager.WithinTx(context.Background(), func(ctx context.Context) error {
+		if _, ok := database.Executor(ctx, db).(*sql.Tx); !ok {
+			t.Errorf("Executor inside the transaction = %T, want *sql.Tx", database.Executor(ctx, db))
+		}
+		if got := database.Executor(ctx, other); got != other {
+			t.Errorf("Executor for another database = %T, want that database", got)
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatalf("WithinTx: %v", err)
+	}
+}
@@ -1,3 +1,13 @@
 module github.com/omni-compos/digital-mono/libs/localization
 
-go 1.21
+go 1.22.0
+
+require go.opentelemetry.io/otel v1.35.0
+
+require (
+	github.com/go-logr/logr v1.4.2 // indirect
+	github.com/go-logr/stdr v1.2.2 // indirect
+	go.opentelemetry.io/auto/sdk v1.1.0 // indirect
+	go.opentelemetry.io/otel/metric v1.35.0 // indirect
+	go.opentelemetry.io/otel/trace v1.35.0 // indirect
+)
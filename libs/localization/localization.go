@@ -2,7 +2,6 @@ package localization
 
 import (
 	"context"
-	"fmt"
 )
 
 // LocationalisationService defines the interface for getting location data.
@@ -21,10 +20,7 @@ func NewDummyLocationalisationService() *DummyLocationalisationService {
 // GetLatLngFromAddress simulates getting latitude and longitude.
 // In a real application, this would call an external geocoding API.
 func (s *DummyLocationalisationService) GetLatLngFromAddress(ctx context.Context, address, city, state, country, postcode string) (latitude, longitude float64, err error) {
-	// Simulate some logic based on input, or just return fixed values/errors
-	fullAddress := fmt.Sprintf("%s, %s, %s, %s %s", address, city, state, postcode, country)
-	fmt.Printf("Simulating geocoding for: %s\n", fullAddress) // Log the address being processed
-
+	// Addresses are personal data and are not logged here; callers log through the redacting logger.
 	// Simple dummy logic: return different coords based on city
 	switch city {
 	case "Sydney":
@@ -0,0 +1,35 @@
+package localization
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+)
+
+// TracedLocalisationService records a span around every call to the wrapped service, so slow or
+// failing geocoding shows up in the caller's trace.
+type TracedLocalisationService struct {
+	next LocationalisationService
+}
+
+// NewTracedLocalisationService wraps next with tracing.
+func NewTracedLocalisationService(next LocationalisationService) *TracedLocalisationService {
+	return &TracedLocalisationService{next: next}
+}
+
+// GetLatLngFromAddress implements LocationalisationService. Only the country is recorded on the
+// span; the rest of the address is personal data.
+func (s *TracedLocalisationService) GetLatLngFromAddress(ctx context.Context, address, city, state, country, postcode string) (latitude, longitude float64, err error) {
+	ctx, span := otel.Tracer("github.com/omni-compos/digital-mono/libs/localization").Start(ctx, "LocationalisationService.GetLatLngFromAddress")
+	defer span.End()
+	span.SetAttributes(attribute.String("address.country", country))
+
+	latitude, longitude, err = s.next.GetLatLngFromAddress(ctx, address, city, state, country, postcode)
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	return latitude, longitude, err
+}
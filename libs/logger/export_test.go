@@ -0,0 +1,24 @@
+package logger
+
+import (
+	"log/slog"
+	"time"
+)
+
+// Sampler exposes the message sampler to the external tests.
+type Sampler = sampler
+
+func NewSampler(cfg Sampling) *Sampler { return newSampler(cfg) }
+
+func (s *sampler) Allow(level slog.Level, message string) (ok bool, dropped int) {
+	return s.allow(level, message)
+}
+
+// Backdate moves the start of every sampling window d into the past, as if d had elapsed.
+func (s *sampler) Backdate(d time.Duration) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, window := range s.windows {
+		window.start = window.start.Add(-d)
+	}
+}
@@ -1,3 +1,7 @@
 module github.com/omni-compos/digital-mono/libs/logger
 
-go 1.21
+go 1.22.0
+
+require go.opentelemetry.io/otel/trace v1.35.0
+
+require go.opentelemetry.io/otel v1.35.0 // indirect
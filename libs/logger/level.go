@@ -0,0 +1,218 @@
+package logger
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"net/http"
+	"runtime"
+	"sort"
+	"strings"
+	"sync"
+	"sync/atomic"
+)
+
+// LevelController holds the minimum log level of a service and optional per-package overrides,
+// both changeable at runtime (see LevelHandler).
+type LevelController struct {
+	level        slog.LevelVar
+	mu           sync.RWMutex
+	packages     map[string]slog.Level // Package path or path suffix, e.g. "seller/internal/repository" or "repository"
+	hasOverrides atomic.Bool
+	minOverride  atomic.Int64 // Lowest override level, so disabled lines are skipped without a caller lookup
+	callerPkgs   sync.Map     // Program counter -> package path
+}
+
+// NewLevelController creates a LevelController at the given level.
+func NewLevelController(level slog.Level) *LevelController {
+	c := &LevelController{packages: map[string]slog.Level{}}
+	c.level.Set(level)
+	return c
+}
+
+// Level returns the service-wide level.
+func (c *LevelController) Level() slog.Level {
+	return c.level.Level()
+}
+
+// SetLevel changes the service-wide level.
+func (c *LevelController) SetLevel(level slog.Level) {
+	c.level.Set(level)
+}
+
+// SetPackageLevel overrides the level for loggers called from packages matching pkg.
+// pkg matches a full import path or any trailing path segments of it; the longest match wins.
+func (c *LevelController) SetPackageLevel(pkg string, level slog.Level) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.packages[strings.Trim(pkg, "/")] = level
+	c.updateOverrides()
+}
+
+// ClearPackageLevel removes the override for pkg.
+func (c *LevelController) ClearPackageLevel(pkg string) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	delete(c.packages, strings.Trim(pkg, "/"))
+	c.updateOverrides()
+}
+
+// PackageLevels returns a copy of the per-package overrides.
+func (c *LevelController) PackageLevels() map[string]slog.Level {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	levels := make(map[string]slog.Level, len(c.packages))
+	for pkg, level := range c.packages {
+		levels[pkg] = level
+	}
+	return levels
+}
+
+// updateOverrides must be called with mu held.
+func (c *LevelController) updateOverrides() {
+	lowest := slog.Level(1 << 30)
+	for _, level := range c.packages {
+		if level < lowest {
+			lowest = level
+		}
+	}
+	c.minOverride.Store(int64(lowest))
+	c.hasOverrides.Store(len(c.packages) > 0)
+}
+
+// enabled reports whether a line at level should be written. skip is the number of stack frames
+// between enabled and the code calling the Logger, used to find the caller's package.
+func (c *LevelController) enabled(level slog.Level, skip int) bool {
+	global := c.level.Level()
+	if !c.hasOverrides.Load() {
+		return level >= global
+	}
+	if level < global && level < slog.Level(c.minOverride.Load()) {
+		return false
+	}
+	if override, ok := c.packageLevel(callerPackage(&c.callerPkgs, skip+1)); ok {
+		return level >= override
+	}
+	return level >= global
+}
+
+func (c *LevelController) packageLevel(pkg string) (slog.Level, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	var (
+		match   string
+		matched slog.Level
+		found   bool
+	)
+	for candidate, level := range c.packages {
+		if (pkg == candidate || strings.HasSuffix(pkg, "/"+candidate)) && len(candidate) > len(match) {
+			match, matched, found = candidate, level, true
+		}
+	}
+	return matched, found
+}
+
+// callerPackage returns the import path of the function skip frames above its caller.
+func callerPackage(cache *sync.Map, skip int) string {
+	var pcs [1]uintptr
+	if runtime.Callers(skip+2, pcs[:]) == 0 {
+		return ""
+	}
+	if pkg, ok := cache.Load(pcs[0]); ok {
+		return pkg.(string)
+	}
+	frames := runtime.CallersFrames(pcs[:])
+	frame, _ := frames.Next()
+	pkg := packageOf(frame.Function)
+	cache.Store(pcs[0], pkg)
+	return pkg
+}
+
+// packageOf strips the receiver and function from a runtime function name,
+// e.g. "a/b/repository.(*Repo).List" -> "a/b/repository".
+func packageOf(function string) string {
+	slash := strings.LastIndex(function, "/")
+	if dot := strings.Index(function[slash+1:], "."); dot >= 0 {
+		return function[:slash+1+dot]
+	}
+	return function
+}
+
+// LevelState is the body of the /admin/loglevel endpoint.
+type LevelState struct {
+	Level    string            `json:"level,omitempty"`
+	Packages map[string]string `json:"packages,omitempty"` // An empty level removes the override
+}
+
+// LevelHandler serves GET (current levels) and PUT (change levels) for an admin endpoint such as
+// /admin/loglevel. It does no authentication; services mount it behind their auth middleware.
+func LevelHandler(levels *LevelController) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		switch r.Method {
+		case http.MethodGet:
+		case http.MethodPut, http.MethodPost:
+			var state LevelState
+			if err := json.NewDecoder(r.Body).Decode(&state); err != nil {
+				http.Error(w, "Invalid request body", http.StatusBadRequest)
+				return
+			}
+			if err := applyLevelState(levels, state); err != nil {
+				http.Error(w, err.Error(), http.StatusBadRequest)
+				return
+			}
+			FromContext(r.Context(), nil).Warn(nil, "Log levels changed", "level", strings.ToLower(levels.Level().String()), "packages", state.Packages)
+		default:
+			w.Header().Set("Allow", "GET, PUT")
+			http.Error(w, "Method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+
+		state := LevelState{Level: strings.ToLower(levels.Level().String()), Packages: map[string]string{}}
+		for pkg, level := range levels.PackageLevels() {
+			state.Packages[pkg] = strings.ToLower(level.String())
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(state)
+	})
+}
+
+// applyLevelState validates every level before changing any, so a bad request changes nothing.
+func applyLevelState(levels *LevelController, state LevelState) error {
+	var global *slog.Level
+	if state.Level != "" {
+		level, err := ParseLevel(state.Level)
+		if err != nil {
+			return err
+		}
+		global = &level
+	}
+	pkgs := make([]string, 0, len(state.Packages))
+	parsed := make(map[string]slog.Level, len(state.Packages))
+	for pkg, name := range state.Packages {
+		if strings.Trim(pkg, "/") == "" {
+			return fmt.Errorf("empty package name")
+		}
+		pkgs = append(pkgs, pkg)
+		if name == "" {
+			continue
+		}
+		level, err := ParseLevel(name)
+		if err != nil {
+			return fmt.Errorf("package %s: %w", pkg, err)
+		}
+		parsed[pkg] = level
+	}
+
+	if global != nil {
+		levels.SetLevel(*global)
+	}
+	sort.Strings(pkgs)
+	for _, pkg := range pkgs {
+		if level, ok := parsed[pkg]; ok {
+			levels.SetPackageLevel(pkg, level)
+		} else {
+			levels.ClearPackageLevel(pkg)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,136 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/logger"
+)
+
+func newLeveledLogger(t *testing.T, levels *logger.LevelController) (logger.Logger, *bytes.Buffer) {
+	t.Helper()
+	var buf bytes.Buffer
+	log, err := logger.NewSlogLogger(logger.Config{Output: &buf, Level: "info", Levels: levels})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return log, &buf
+}
+
+func TestLevelController_ChangesLevelAtRuntime(t *testing.T) {
+	levels := logger.NewLevelController(slog.LevelError)
+	log, buf := newLeveledLogger(t, levels)
+	if levels.Level() != slog.LevelInfo {
+		t.Fatalf("level = %s, want NewSlogLogger to apply Config.Level", levels.Level())
+	}
+
+	log.Debug("hidden")
+	log.Info("shown at info")
+	levels.SetLevel(slog.LevelDebug)
+	log.Debug("shown at debug")
+	levels.SetLevel(slog.LevelWarn)
+	log.Info("hidden at warn")
+	log.Warn(nil, "shown at warn")
+
+	out := buf.String()
+	for _, message := range []string{"shown at info", "shown at debug", "shown at warn"} {
+		if !strings.Contains(out, message) {
+			t.Errorf("%q missing from %s", message, out)
+		}
+	}
+	if strings.Contains(out, "hidden") {
+		t.Errorf("lines below the level were written: %s", out)
+	}
+}
+
+func TestLevelController_PackageOverrides(t *testing.T) {
+	levels := logger.NewLevelController(slog.LevelInfo)
+	log, buf := newLeveledLogger(t, levels)
+
+	// This test's package is github.com/omni-compos/digital-mono/libs/logger_test.
+	levels.SetPackageLevel("libs/logger_test", slog.LevelDebug)
+	log.Debug("debug from the overridden package")
+	levels.SetPackageLevel("/logger_test/", slog.LevelError) // Trimmed; the longer "libs/logger_test" still wins
+	log.Debug("debug with the longest match")
+	levels.ClearPackageLevel("libs/logger_test")
+	log.Warn(nil, "warn below the remaining override")
+	levels.SetPackageLevel("other/pkg", slog.LevelDebug)
+	levels.ClearPackageLevel("logger_test")
+	log.Debug("debug with only another package overridden")
+	log.Info("info at the service level")
+
+	out := buf.String()
+	for _, message := range []string{"debug from the overridden package", "debug with the longest match", "info at the service level"} {
+		if !strings.Contains(out, message) {
+			t.Errorf("%q missing from %s", message, out)
+		}
+	}
+	for _, message := range []string{"warn below the remaining override", "debug with only another package overridden"} {
+		if strings.Contains(out, message) {
+			t.Errorf("%q was written: %s", message, out)
+		}
+	}
+	if got := levels.PackageLevels(); len(got) != 1 || got["other/pkg"] != slog.LevelDebug {
+		t.Errorf("PackageLevels = %v, want only other/pkg at debug", got)
+	}
+}
+
+func TestLevelHandler(t *testing.T) {
+	levels := logger.NewLevelController(slog.LevelInfo)
+	handler := logger.LevelHandler(levels)
+	serve := func(method, body string) (int, logger.LevelState) {
+		t.Helper()
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, httptest.NewRequest(method, "/admin/loglevel", strings.NewReader(body)))
+		var state logger.LevelState
+		if rec.Code == http.StatusOK {
+			if err := json.NewDecoder(rec.Body).Decode(&state); err != nil {
+				t.Fatalf("invalid response body: %v", err)
+			}
+		}
+		return rec.Code, state
+	}
+
+	if status, state := serve(http.MethodGet, ""); status != http.StatusOK || state.Level != "info" || len(state.Packages) != 0 {
+		t.Errorf("GET = %d %+v, want 200 with level info", status, state)
+	}
+
+	status, state := serve(http.MethodPut, `{"level": "warn", "packages": {"seller/internal/repository": "debug"}}`)
+	if status != http.StatusOK || state.Level != "warn" || state.Packages["seller/internal/repository"] != "debug" {
+		t.Errorf("PUT = %d %+v, want the new levels", status, state)
+	}
+	if levels.Level() != slog.LevelWarn || levels.PackageLevels()["seller/internal/repository"] != slog.LevelDebug {
+		t.Errorf("controller not updated: %s %v", levels.Level(), levels.PackageLevels())
+	}
+
+	// Every level is validated before any is applied.
+	for name, body := range map[string]string{
+		"malformed JSON":        `{"level": `,
+		"unknown level":         `{"level": "verbose"}`,
+		"unknown package level": `{"level": "debug", "packages": {"repository": "loud"}}`,
+		"empty package":         `{"packages": {"/": "debug"}}`,
+	} {
+		if status, _ := serve(http.MethodPut, body); status != http.StatusBadRequest {
+			t.Errorf("%s: status = %d, want 400", name, status)
+		}
+	}
+	if levels.Level() != slog.LevelWarn || len(levels.PackageLevels()) != 1 {
+		t.Errorf("rejected requests changed the levels: %s %v", levels.Level(), levels.PackageLevels())
+	}
+
+	// An empty package level removes the override.
+	if status, state := serve(http.MethodPut, `{"packages": {"seller/internal/repository": ""}}`); status != http.StatusOK || state.Level != "warn" || len(state.Packages) != 0 {
+		t.Errorf("PUT = %d %+v, want the override removed and the level kept", status, state)
+	}
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, httptest.NewRequest(http.MethodDelete, "/admin/loglevel", nil))
+	if rec.Code != http.StatusMethodNotAllowed || rec.Header().Get("Allow") != "GET, PUT" {
+		t.Errorf("DELETE = %d, Allow %q, want 405 with GET, PUT", rec.Code, rec.Header().Get("Allow"))
+	}
+}
@@ -1,36 +1,77 @@
 package logger
 
-import "log" // Standard log, can be replaced with a structured logger like zerolog, zap
+import (
+	"fmt"
+	"log" // Standard log, can be replaced with a structured logger like zerolog, zap
+)
 
 // Logger defines a common interface for logging.
+// Fields are alternating key/value pairs, e.g. "seller_id", id.
 type Logger interface {
+	Debug(message string, fields ...interface{})
 	Info(message string, fields ...interface{})
 	Error(err error, message string, fields ...interface{})
 	Warn(err error, message string, fields ...interface{})
-	// Add other levels like Debug, Warn as needed
+	// With returns a Logger that adds the fields to every message.
+	With(fields ...interface{}) Logger
 }
 
 // stdLogger is a simple implementation of Logger using the standard log package.
-type stdLogger struct{}
+type stdLogger struct {
+	fields   []interface{} // Added by With, already redacted
+	redactor *Redactor
+}
 
 // NewStdLogger creates a new Logger that uses the standard log package.
 // This was the intended constructor name from previous steps.
 func NewStdLogger() Logger {
-	return &stdLogger{}
+	return &stdLogger{redactor: NewRedactor(nil)}
+}
+
+// Debug logs a debug message.
+func (l *stdLogger) Debug(message string, fields ...interface{}) {
+	fields = append(l.fields, l.redact(fields)...)
+	log.Printf("DEBUG: %s %v\n", l.redactor.RedactString(message), fields)
 }
 
 // Info logs an info message.
 func (l *stdLogger) Info(message string, fields ...interface{}) {
+	fields = append(l.fields, l.redact(fields)...)
 	// Basic formatting, a real structured logger would handle fields better
-	log.Printf("INFO: %s %v\n", message, fields)
+	log.Printf("INFO: %s %v\n", l.redactor.RedactString(message), fields)
 }
 
 // Error logs an error message.
 func (l *stdLogger) Error(err error, message string, fields ...interface{}) {
-	log.Printf("ERROR: %s: %v %v\n", message, err, fields)
+	fields = append(l.fields, l.redact(fields)...)
+	log.Printf("ERROR: %s: %v %v\n", l.redactor.RedactString(message), l.redactError(err), fields)
 }
 
-// Error logs an error message.
+// Warn logs a warning message.
 func (l *stdLogger) Warn(err error, message string, fields ...interface{}) {
-	log.Printf("WARN: %s: %v %v\n", message, err, fields)
-}
\ No newline at end of file
+	fields = append(l.fields, l.redact(fields)...)
+	log.Printf("WARN: %s: %v %v\n", l.redactor.RedactString(message), l.redactError(err), fields)
+}
+
+// With returns a stdLogger that prefixes the fields to every message.
+func (l *stdLogger) With(fields ...interface{}) Logger {
+	merged := append(append([]interface{}{}, l.fields...), l.redact(fields)...)
+	return &stdLogger{fields: merged[:len(merged):len(merged)], redactor: l.redactor} // Full slice so appends never share the array
+}
+
+// redact returns a copy of the key/value fields with sensitive values masked.
+func (l *stdLogger) redact(fields []interface{}) []interface{} {
+	redacted := make([]interface{}, len(fields))
+	copy(redacted, fields)
+	for i := 0; i+1 < len(redacted); i += 2 {
+		redacted[i+1] = l.redactor.RedactField(fmt.Sprintf("%v", redacted[i]), redacted[i+1])
+	}
+	return redacted
+}
+
+func (l *stdLogger) redactError(err error) interface{} {
+	if err == nil {
+		return nil
+	}
+	return l.redactor.RedactString(err.Error())
+}
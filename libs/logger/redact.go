@@ -0,0 +1,166 @@
+package logger
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"log/slog"
+	"regexp"
+	"strings"
+	"sync"
+)
+
+// RedactAction is what a Redactor does with a sensitive value.
+type RedactAction int
+
+const (
+	// RedactMask replaces the value with RedactedValue.
+	RedactMask RedactAction = iota
+	// RedactHash replaces the value with a keyed hash, so lines about the same value can still be correlated.
+	RedactHash
+)
+
+// RedactedValue replaces masked values.
+const RedactedValue = "[REDACTED]"
+
+// Default sensitive keys. Keys are matched case-insensitively, ignoring "_" and "-" (so phone_number matches phoneNumber).
+var (
+	defaultMaskedKeys = []string{
+		"address", "street", "address_line1", "address_line2", "phone", "phone_number", "mobile",
+		"password", "secret", "client_secret", "token", "access_token", "refresh_token", "api_key", "authorization",
+	}
+	defaultHashedKeys = []string{"email"}
+)
+
+// Default patterns applied to every string value, error and message regardless of key.
+var (
+	emailPattern = regexp.MustCompile(`[A-Za-z0-9._%+\-]+@[A-Za-z0-9.\-]+\.[A-Za-z]{2,}`)
+	// International numbers (+61 412 345 678, +1 (555) 123-4567) and whole Australian landline
+	// (02 9876 5432, (02) 9876 5432) and mobile (0412 345 678) numbers. Local numbers must have
+	// exactly ten digits, separated by spaces if at all, so dates, times, UUIDs and order numbers
+	// stay readable.
+	phonePattern = regexp.MustCompile(`\+\d{1,3}[\s.\-]?(\(\d{1,4}\)|\d{1,4})([\s.\-]?\d{2,4}){2,3}\b|` +
+		`(\(0[2378]\) ?|\b0[2378] ?)\d{4} ?\d{4}\b|\b04\d{2} ?\d{3} ?\d{3}\b`)
+)
+
+type redactPattern struct {
+	pattern *regexp.Regexp
+	action  RedactAction
+}
+
+// Redactor masks or hashes sensitive log fields, by key or by value pattern.
+// Street addresses have no reliable pattern and are only caught by key.
+type Redactor struct {
+	mu       sync.RWMutex
+	keys     map[string]RedactAction
+	patterns []redactPattern
+	hashKey  []byte
+}
+
+// NewRedactor creates a Redactor with the default rules: emails are hashed, phone numbers,
+// addresses and credentials are masked. hashKey keys the HMAC used by RedactHash; without it
+// hashes are plain SHA-256, which is guessable for low-entropy values like emails.
+func NewRedactor(hashKey []byte) *Redactor {
+	r := &Redactor{keys: map[string]RedactAction{}, hashKey: hashKey}
+	r.RegisterKeys(RedactMask, defaultMaskedKeys...)
+	r.RegisterKeys(RedactHash, defaultHashedKeys...)
+	r.RegisterPattern(emailPattern, RedactHash)
+	r.RegisterPattern(phonePattern, RedactMask)
+	return r
+}
+
+// RegisterKeys marks extra field keys as sensitive, e.g. a service's own PII fields.
+func (r *Redactor) RegisterKeys(action RedactAction, keys ...string) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range keys {
+		r.keys[normalizeKey(key)] = action
+	}
+	return r
+}
+
+// RegisterPattern redacts every match of pattern in string values, errors and messages.
+func (r *Redactor) RegisterPattern(pattern *regexp.Regexp, action RedactAction) *Redactor {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.patterns = append(r.patterns, redactPattern{pattern: pattern, action: action})
+	return r
+}
+
+// RedactField returns the value to log for key. Values of sensitive keys are masked or hashed;
+// other string values (and errors and Stringers) have pattern matches replaced, and groups
+// (slog.GroupValue, or a slog.LogValuer that resolves to one) are redacted attribute by attribute.
+func (r *Redactor) RedactField(key string, value interface{}) interface{} {
+	r.mu.RLock()
+	action, sensitive := r.keys[normalizeKey(key)]
+	r.mu.RUnlock()
+	if sensitive {
+		if value == nil {
+			return nil
+		}
+		return r.apply(action, fmt.Sprintf("%v", value))
+	}
+
+	if valuer, ok := value.(slog.LogValuer); ok {
+		value = slog.AnyValue(valuer).Resolve()
+	}
+	switch v := value.(type) {
+	case slog.Value:
+		if v.Kind() != slog.KindGroup {
+			return r.RedactField(key, v.Any())
+		}
+		attrs := v.Group()
+		redacted := make([]slog.Attr, len(attrs))
+		for i, attr := range attrs {
+			redacted[i] = slog.Any(attr.Key, r.RedactField(attr.Key, attr.Value.Resolve()))
+		}
+		return slog.GroupValue(redacted...)
+	case string:
+		return r.RedactString(v)
+	case error:
+		return r.RedactString(v.Error())
+	case fmt.Stringer:
+		return r.RedactString(v.String())
+	}
+	return value
+}
+
+// RedactString replaces every pattern match in s.
+func (r *Redactor) RedactString(s string) string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	for _, p := range r.patterns {
+		s = p.pattern.ReplaceAllStringFunc(s, func(match string) string {
+			return r.apply(p.action, match)
+		})
+	}
+	return s
+}
+
+func (r *Redactor) apply(action RedactAction, value string) string {
+	if action == RedactHash {
+		return r.hash(value)
+	}
+	return RedactedValue
+}
+
+// hash returns a short, stable digest; values are lowercased so "A@x.com" and "a@x.com" correlate.
+func (r *Redactor) hash(value string) string {
+	value = strings.ToLower(strings.TrimSpace(value))
+	var sum []byte
+	if len(r.hashKey) > 0 {
+		mac := hmac.New(sha256.New, r.hashKey)
+		mac.Write([]byte(value))
+		sum = mac.Sum(nil)
+	} else {
+		digest := sha256.Sum256([]byte(value))
+		sum = digest[:]
+	}
+	return "hash:" + hex.EncodeToString(sum[:8])
+}
+
+func normalizeKey(key string) string {
+	key = strings.ToLower(key)
+	return strings.NewReplacer("_", "", "-", "").Replace(key)
+}
@@ -0,0 +1,161 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"log/slog"
+	"strings"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/logger"
+)
+
+func TestRedactor_MasksSensitiveKeys(t *testing.T) {
+	redactor := logger.NewRedactor(nil)
+	for _, key := range []string{"password", "Password", "phone_number", "phoneNumber", "PHONE-NUMBER", "address_line1", "access_token", "Authorization"} {
+		if got := redactor.RedactField(key, "anything"); got != logger.RedactedValue {
+			t.Errorf("RedactField(%q) = %v, want %s", key, got, logger.RedactedValue)
+		}
+	}
+	if got := redactor.RedactField("password", nil); got != nil {
+		t.Errorf("RedactField(password, nil) = %v, want nil", got)
+	}
+	if got := redactor.RedactField("city", "Sydney"); got != "Sydney" {
+		t.Errorf("RedactField(city) = %v, want it unchanged", got)
+	}
+	if got := redactor.RedactField("count", 3); got != 3 {
+		t.Errorf("RedactField(count) = %v, want it unchanged", got)
+	}
+}
+
+func TestRedactor_HashesEmails(t *testing.T) {
+	keyed := logger.NewRedactor([]byte("key-1"))
+	hash := keyed.RedactField("email", "Jane@Example.com")
+	if s, ok := hash.(string); !ok || !strings.HasPrefix(s, "hash:") || strings.Contains(s, "example") {
+		t.Fatalf("RedactField(email) = %v, want a hash", hash)
+	}
+	if got := keyed.RedactField("email", " jane@example.com"); got != hash {
+		t.Errorf("hash of the same address differs by case or whitespace: %v != %v", got, hash)
+	}
+	if got := keyed.RedactString("invite sent to jane@example.com"); got != "invite sent to "+hash.(string) {
+		t.Errorf("RedactString = %q, want the address hashed in place", got)
+	}
+	if got := logger.NewRedactor([]byte("key-2")).RedactField("email", "jane@example.com"); got == hash {
+		t.Error("hashes with different keys are equal; the HMAC key is not used")
+	}
+	if got := logger.NewRedactor(nil).RedactField("email", "jane@example.com"); got == hash {
+		t.Error("unkeyed hash equals the keyed one")
+	}
+}
+
+func TestRedactor_PhonePattern(t *testing.T) {
+	redactor := logger.NewRedactor(nil)
+	for _, tc := range []struct {
+		in, want string
+	}{
+		// Phone numbers
+		{"call +61 412 345 678", "call [REDACTED]"},
+		{"call +61412345678", "call [REDACTED]"},
+		{"call +1 (555) 123-4567 now", "call [REDACTED] now"},
+		{"call 0412 345 678", "call [REDACTED]"},
+		{"call 0412345678", "call [REDACTED]"},
+		{"call 02 9876 5432", "call [REDACTED]"},
+		{"call (02) 9876 5432", "call [REDACTED]"},
+		{"call 0298765432.", "call [REDACTED]."},
+		// Not phone numbers
+		{"at 2024-05-01 09:30:00", "at 2024-05-01 09:30:00"},
+		{"at 2024-05-01T09:30:00+10:00", "at 2024-05-01T09:30:00+10:00"},
+		{"seller 01234567-0123-4567-0123-456789012345", "seller 01234567-0123-4567-0123-456789012345"},
+		{"seller 04123456-0412-3456-0412-345678901234", "seller 04123456-0412-3456-0412-345678901234"},
+		{"order 012345678", "order 012345678"},
+		{"order 02987654321", "order 02987654321"},
+		{"took 0.0412 s", "took 0.0412 s"},
+		{"postcode 2000", "postcode 2000"},
+	} {
+		if got := redactor.RedactString(tc.in); got != tc.want {
+			t.Errorf("RedactString(%q) = %q, want %q", tc.in, got, tc.want)
+		}
+	}
+}
+
+func TestRedactor_RedactsValuesOfOtherKeys(t *testing.T) {
+	redactor := logger.NewRedactor(nil)
+	if got := redactor.RedactField("note", "call 0412 345 678"); got != "call [REDACTED]" {
+		t.Errorf("string value = %v", got)
+	}
+	if got := redactor.RedactField("cause", errors.New("no user jane@example.com")); strings.Contains(got.(string), "jane") {
+		t.Errorf("error value = %v, want the address hashed", got)
+	}
+}
+
+func TestRedactor_RedactsNestedGroups(t *testing.T) {
+	redactor := logger.NewRedactor(nil)
+	group := slog.GroupValue(
+		slog.String("id", "user-1"),
+		slog.String("password", "hunter2"),
+		slog.Group("contact", slog.String("phone", "0412 345 678"), slog.String("note", "or +61 412 345 678"), slog.Int("attempts", 2)),
+	)
+
+	var buf bytes.Buffer
+	log, err := logger.NewSlogLogger(logger.Config{Output: &buf, Redactor: redactor})
+	if err != nil {
+		t.Fatal(err)
+	}
+	log.Info("signup", "user", group)
+
+	var line struct {
+		User struct {
+			ID       string `json:"id"`
+			Password string `json:"password"`
+			Contact  struct {
+				Phone    string `json:"phone"`
+				Note     string `json:"note"`
+				Attempts int    `json:"attempts"`
+			} `json:"contact"`
+		} `json:"user"`
+	}
+	if err := json.Unmarshal(buf.Bytes(), &line); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", buf.String(), err)
+	}
+	if line.User.ID != "user-1" || line.User.Contact.Attempts != 2 {
+		t.Errorf("plain attributes changed: %s", buf.String())
+	}
+	if line.User.Password != logger.RedactedValue || line.User.Contact.Phone != logger.RedactedValue {
+		t.Errorf("sensitive keys in groups not masked: %s", buf.String())
+	}
+	if line.User.Contact.Note != "or [REDACTED]" {
+		t.Errorf("patterns in groups not applied: %s", buf.String())
+	}
+}
+
+type account struct {
+	Email string
+}
+
+func (a account) LogValue() slog.Value {
+	return slog.GroupValue(slog.String("email", a.Email))
+}
+
+func TestRedactor_ResolvesLogValuers(t *testing.T) {
+	got, ok := logger.NewRedactor(nil).RedactField("account", account{Email: "jane@example.com"}).(slog.Value)
+	if !ok || got.Kind() != slog.KindGroup {
+		t.Fatalf("RedactField(LogValuer) = %v, want a group", got)
+	}
+	if email := got.Group()[0].Value.String(); !strings.HasPrefix(email, "hash:") {
+		t.Errorf("email in LogValuer = %q, want a hash", email)
+	}
+}
+
+func TestRedactor_RegisterKeys(t *testing.T) {
+	redactor := logger.NewRedactor(nil).RegisterKeys(logger.RedactMask, "name").RegisterKeys(logger.RedactHash, "customer_id")
+	if got := redactor.RedactField("Name", "Jane Citizen"); got != logger.RedactedValue {
+		t.Errorf("RedactField(Name) = %v, want it masked", got)
+	}
+	if got, _ := redactor.RedactField("customerId", "c-1").(string); !strings.HasPrefix(got, "hash:") {
+		t.Errorf("RedactField(customerId) = %v, want a hash", got)
+	}
+	if got := logger.NewRedactor(nil).RedactField("name", "Jane Citizen"); got != "Jane Citizen" {
+		t.Errorf("unregistered key masked: %v", got)
+	}
+}
@@ -0,0 +1,144 @@
+package logger
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/http"
+	"strings"
+
+	"go.opentelemetry.io/otel/trace"
+)
+
+// Headers used to correlate requests across services.
+const (
+	RequestIDHeader   = "X-Request-ID"
+	TraceparentHeader = "traceparent" // W3C Trace Context
+)
+
+const maxRequestIDLength = 128
+
+type requestIDKey struct{}
+type traceparentKey struct{}
+
+// RequestIDFromContext returns the request ID set by RequestMiddleware, or "".
+func RequestIDFromContext(ctx context.Context) string {
+	id, _ := ctx.Value(requestIDKey{}).(string)
+	return id
+}
+
+// TraceparentFromContext returns the W3C traceparent of the request, or "".
+func TraceparentFromContext(ctx context.Context) string {
+	traceparent, _ := ctx.Value(traceparentKey{}).(string)
+	return traceparent
+}
+
+// ContextWithRequestID returns a copy of ctx carrying the request ID and traceparent,
+// for work started outside an HTTP request (e.g. consumers) that should still be correlated.
+func ContextWithRequestID(ctx context.Context, requestID, traceparent string) context.Context {
+	ctx = context.WithValue(ctx, requestIDKey{}, requestID)
+	return context.WithValue(ctx, traceparentKey{}, traceparent)
+}
+
+// RequestMiddleware accepts or creates a request ID and trace context for each request and stores a
+// logger carrying request_id and trace_id in the request context (see FromContext).
+// The trace is the OpenTelemetry span in the request context if there is one (register
+// tracing.Middleware first), else the caller's traceparent header, else a generated one.
+// The request ID is taken from X-Request-ID, else the trace ID, and echoed in the response's
+// X-Request-ID header.
+func RequestMiddleware(base Logger) func(http.Handler) http.Handler {
+	return func(next http.Handler) http.Handler {
+		return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			traceID, traceparent := requestTrace(r)
+
+			requestID := r.Header.Get(RequestIDHeader)
+			if !validRequestID(requestID) {
+				requestID = traceID
+			}
+
+			ctx := ContextWithRequestID(r.Context(), requestID, traceparent)
+			ctx = NewContext(ctx, base.With("request_id", requestID, "trace_id", traceID))
+			w.Header().Set(RequestIDHeader, requestID)
+			next.ServeHTTP(w, r.WithContext(ctx))
+		})
+	}
+}
+
+// Transport returns an http.RoundTripper that forwards the request ID and trace context from the
+// outgoing request's context, so downstream services log the same IDs.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return roundTripperFunc(func(r *http.Request) (*http.Response, error) {
+		requestID := RequestIDFromContext(r.Context())
+		traceparent := TraceparentFromContext(r.Context())
+		if requestID == "" && traceparent == "" {
+			return base.RoundTrip(r)
+		}
+		r = r.Clone(r.Context())
+		if requestID != "" && r.Header.Get(RequestIDHeader) == "" {
+			r.Header.Set(RequestIDHeader, requestID)
+		}
+		if traceparent != "" && r.Header.Get(TraceparentHeader) == "" {
+			r.Header.Set(TraceparentHeader, traceparent)
+		}
+		return base.RoundTrip(r)
+	})
+}
+
+// requestTrace returns the trace ID and outgoing traceparent of the request. Without a span the
+// caller's trace is continued as a new hop, so log lines still match the caller's.
+func requestTrace(r *http.Request) (traceID, traceparent string) {
+	if span := trace.SpanContextFromContext(r.Context()); span.IsValid() {
+		traceID = span.TraceID().String()
+		return traceID, "00-" + traceID + "-" + span.SpanID().String() + "-" + span.TraceFlags().String()
+	}
+	traceID, flags, ok := parseTraceparent(r.Header.Get(TraceparentHeader))
+	if !ok {
+		traceID, flags = randomHex(16), "01"
+	}
+	return traceID, "00-" + traceID + "-" + randomHex(8) + "-" + flags
+}
+
+type roundTripperFunc func(*http.Request) (*http.Response, error)
+
+func (f roundTripperFunc) RoundTrip(r *http.Request) (*http.Response, error) { return f(r) }
+
+// parseTraceparent extracts the trace ID and flags from a version 00 traceparent header.
+func parseTraceparent(header string) (traceID, flags string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		return "", "", false
+	}
+	for _, part := range parts[1:] {
+		if _, err := hex.DecodeString(part); err != nil || strings.ToLower(part) != part {
+			return "", "", false
+		}
+	}
+	if strings.Trim(parts[1], "0") == "" || strings.Trim(parts[2], "0") == "" {
+		return "", "", false // All-zero IDs are invalid
+	}
+	return parts[1], parts[3], true
+}
+
+// validRequestID accepts short printable ASCII IDs so clients cannot inject into log lines.
+func validRequestID(id string) bool {
+	if id == "" || len(id) > maxRequestIDLength {
+		return false
+	}
+	for i := 0; i < len(id); i++ {
+		if id[i] < 0x21 || id[i] > 0x7e {
+			return false
+		}
+	}
+	return true
+}
+
+func randomHex(n int) string {
+	b := make([]byte, n)
+	if _, err := rand.Read(b); err != nil {
+		panic("logger: failed to read random bytes: " + err.Error())
+	}
+	return hex.EncodeToString(b)
+}
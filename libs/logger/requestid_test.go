@@ -0,0 +1,220 @@
+package logger_test
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const callerTraceparent = "00-4bf92f3577b34da6a3ce929d0e0e4736-00f067aa0ba902b7-01"
+
+// requestLine is what RequestMiddleware exposes to a handler: the IDs in the context and the
+// fields of a line logged through the request-scoped logger.
+type requestLine struct {
+	RequestID   string
+	Traceparent string
+	Response    string // X-Request-ID response header
+	Logged      struct {
+		RequestID string `json:"request_id"`
+		TraceID   string `json:"trace_id"`
+	}
+}
+
+func serveRequest(t *testing.T, r *http.Request) requestLine {
+	t.Helper()
+	var buf bytes.Buffer
+	base, err := logger.NewSlogLogger(logger.Config{Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	var line requestLine
+	handler := logger.RequestMiddleware(base)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		line.RequestID = logger.RequestIDFromContext(r.Context())
+		line.Traceparent = logger.TraceparentFromContext(r.Context())
+		logger.FromContext(r.Context(), nil).Info("handled")
+	}))
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, r)
+	line.Response = rec.Header().Get(logger.RequestIDHeader)
+	if err := json.Unmarshal(buf.Bytes(), &line.Logged); err != nil {
+		t.Fatalf("invalid JSON line %q: %v", buf.String(), err)
+	}
+	return line
+}
+
+func traceparentParts(t *testing.T, traceparent string) []string {
+	t.Helper()
+	parts := strings.Split(traceparent, "-")
+	if len(parts) != 4 || parts[0] != "00" || len(parts[1]) != 32 || len(parts[2]) != 16 || len(parts[3]) != 2 {
+		t.Fatalf("malformed traceparent %q", traceparent)
+	}
+	return parts
+}
+
+func TestRequestMiddleware_UsesSpanContext(t *testing.T) {
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x0a, 0xf7, 0x65, 0x19, 0x16, 0xcd, 0x43, 0xdd, 0x84, 0x48, 0xeb, 0x21, 0x1c, 0x80, 0x31, 0x9c},
+		SpanID:     trace.SpanID{0xb7, 0xad, 0x6b, 0x71, 0x69, 0x20, 0x33, 0x31},
+		TraceFlags: trace.FlagsSampled,
+	})
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(logger.TraceparentHeader, callerTraceparent) // Already continued by the span
+	r = r.WithContext(trace.ContextWithSpanContext(r.Context(), span))
+
+	line := serveRequest(t, r)
+	if want := span.TraceID().String(); line.Logged.TraceID != want || line.RequestID != want {
+		t.Errorf("trace_id = %s, request ID = %s, want the span's trace ID %s", line.Logged.TraceID, line.RequestID, want)
+	}
+	if want := "00-0af7651916cd43dd8448eb211c80319c-b7ad6b7169203331-01"; line.Traceparent != want {
+		t.Errorf("traceparent = %s, want the span's %s", line.Traceparent, want)
+	}
+}
+
+func TestRequestMiddleware_ContinuesCallerTrace(t *testing.T) {
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(logger.TraceparentHeader, callerTraceparent)
+
+	line := serveRequest(t, r)
+	if line.Logged.TraceID != "4bf92f3577b34da6a3ce929d0e0e4736" {
+		t.Errorf("trace_id = %s, want the caller's", line.Logged.TraceID)
+	}
+	parts := traceparentParts(t, line.Traceparent)
+	if parts[1] != line.Logged.TraceID || parts[2] == "00f067aa0ba902b7" || parts[3] != "01" {
+		t.Errorf("traceparent = %s, want the caller's trace and flags with a new parent ID", line.Traceparent)
+	}
+}
+
+func TestRequestMiddleware_GeneratesTraceAsFallback(t *testing.T) {
+	for name, header := range map[string]string{
+		"no traceparent":      "",
+		"invalid traceparent": "00-00000000000000000000000000000000-00f067aa0ba902b7-01",
+	} {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if header != "" {
+				r.Header.Set(logger.TraceparentHeader, header)
+			}
+			first, second := serveRequest(t, r), serveRequest(t, r)
+			parts := traceparentParts(t, first.Traceparent)
+			if parts[1] != first.Logged.TraceID || first.Logged.TraceID == "00000000000000000000000000000000" {
+				t.Errorf("trace_id = %s, traceparent = %s", first.Logged.TraceID, first.Traceparent)
+			}
+			if first.Logged.TraceID == second.Logged.TraceID {
+				t.Errorf("two requests got the same generated trace ID %s", first.Logged.TraceID)
+			}
+		})
+	}
+}
+
+func TestRequestMiddleware_RequestID(t *testing.T) {
+	tests := map[string]struct {
+		header string
+		want   string // Empty: the trace ID
+	}{
+		"none":           {"", ""},
+		"valid":          {"req-123", "req-123"},
+		"with spaces":    {"req 123\nforged=1", ""},
+		"non-ASCII":      {"reqé", ""},
+		"over 128 chars": {strings.Repeat("a", 129), ""},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			r := httptest.NewRequest(http.MethodGet, "/", nil)
+			if tt.header != "" {
+				r.Header.Set(logger.RequestIDHeader, tt.header)
+			}
+			line := serveRequest(t, r)
+			want := tt.want
+			if want == "" {
+				want = line.Logged.TraceID
+			}
+			if line.RequestID != want || line.Logged.RequestID != want || line.Response != want {
+				t.Errorf("request ID = %q, logged %q, echoed %q, want %q", line.RequestID, line.Logged.RequestID, line.Response, want)
+			}
+		})
+	}
+}
+
+func TestTransport_ForwardsRequestIDAndTraceparent(t *testing.T) {
+	var got http.Header
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer downstream.Close()
+	client := &http.Client{Transport: logger.Transport(nil)}
+
+	send := func(ctx context.Context, header http.Header) {
+		t.Helper()
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		for key, values := range header {
+			req.Header[key] = values
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+		if req.Header.Get(logger.RequestIDHeader) != header.Get(logger.RequestIDHeader) {
+			t.Error("Transport modified the caller's request")
+		}
+	}
+
+	ctx := logger.ContextWithRequestID(context.Background(), "req-123", callerTraceparent)
+	send(ctx, http.Header{})
+	if got.Get(logger.RequestIDHeader) != "req-123" || got.Get(logger.TraceparentHeader) != callerTraceparent {
+		t.Errorf("headers = %v, want the IDs from the context", got)
+	}
+
+	send(ctx, http.Header{logger.RequestIDHeader: {"explicit"}})
+	if got.Get(logger.RequestIDHeader) != "explicit" || got.Get(logger.TraceparentHeader) != callerTraceparent {
+		t.Errorf("headers = %v, want the explicit request ID kept", got)
+	}
+
+	send(context.Background(), http.Header{})
+	if got.Get(logger.RequestIDHeader) != "" || got.Get(logger.TraceparentHeader) != "" {
+		t.Errorf("headers = %v, want none without IDs in the context", got)
+	}
+}
+
+func TestTransport_ForwardsMiddlewareIDs(t *testing.T) {
+	var got http.Header
+	downstream := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = r.Header.Clone()
+	}))
+	defer downstream.Close()
+	client := &http.Client{Transport: logger.Transport(nil)}
+
+	var requestID, traceparent string
+	handler := logger.RequestMiddleware(logger.NewStdLogger())(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestID, traceparent = logger.RequestIDFromContext(r.Context()), logger.TraceparentFromContext(r.Context())
+		req, err := http.NewRequestWithContext(r.Context(), http.MethodGet, downstream.URL, nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp.Body.Close()
+	}))
+	r := httptest.NewRequest(http.MethodGet, "/", nil)
+	r.Header.Set(logger.TraceparentHeader, callerTraceparent)
+	handler.ServeHTTP(httptest.NewRecorder(), r)
+
+	if got.Get(logger.RequestIDHeader) != requestID || got.Get(logger.TraceparentHeader) != traceparent {
+		t.Errorf("downstream headers = %v, want request ID %s and traceparent %s", got, requestID, traceparent)
+	}
+	if !strings.Contains(traceparent, "4bf92f3577b34da6a3ce929d0e0e4736") {
+		t.Errorf("traceparent = %s, want the caller's trace continued downstream", traceparent)
+	}
+}
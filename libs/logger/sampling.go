@@ -0,0 +1,92 @@
+package logger
+
+import (
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// Sampling limits how often the same message is logged: the first Burst lines with a given
+// level and message in each Interval are written, then every Thereafter-th line; the rest are
+// dropped and counted. The next line written for that message carries the count as "sampled_dropped".
+type Sampling struct {
+	Interval   time.Duration // Defaults to one second
+	Burst      int           // Lines per message and interval; 0 disables sampling
+	Thereafter int           // After Burst, write every Thereafter-th line; 0 drops them all
+}
+
+// maxSampledMessages bounds the sampler's memory when messages are built dynamically.
+const maxSampledMessages = 10000
+
+type sampleKey struct {
+	level   slog.Level
+	message string
+}
+
+type sampleWindow struct {
+	start   time.Time
+	count   int
+	dropped int
+}
+
+type sampler struct {
+	interval   time.Duration
+	burst      int
+	thereafter int
+
+	mu      sync.Mutex
+	windows map[sampleKey]*sampleWindow
+}
+
+func newSampler(cfg Sampling) *sampler {
+	if cfg.Burst <= 0 {
+		return nil
+	}
+	if cfg.Interval <= 0 {
+		cfg.Interval = time.Second
+	}
+	return &sampler{interval: cfg.Interval, burst: cfg.Burst, thereafter: cfg.Thereafter, windows: map[sampleKey]*sampleWindow{}}
+}
+
+// allow reports whether the line should be written and how many lines of the same
+// message were dropped since the last one written. A nil sampler allows everything.
+func (s *sampler) allow(level slog.Level, message string) (ok bool, dropped int) {
+	if s == nil {
+		return true, 0
+	}
+	now := time.Now()
+	key := sampleKey{level: level, message: message}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	window, found := s.windows[key]
+	if !found {
+		if len(s.windows) >= maxSampledMessages {
+			s.prune(now)
+			if len(s.windows) >= maxSampledMessages {
+				return true, 0 // Too many distinct messages to track; do not sample
+			}
+		}
+		window = &sampleWindow{start: now}
+		s.windows[key] = window
+	}
+	if now.Sub(window.start) >= s.interval {
+		window.start, window.count = now, 0
+	}
+	window.count++
+	if window.count > s.burst && (s.thereafter <= 0 || (window.count-s.burst)%s.thereafter != 0) {
+		window.dropped++
+		return false, 0
+	}
+	dropped, window.dropped = window.dropped, 0
+	return true, dropped
+}
+
+// prune forgets windows that have expired; their dropped counts are lost.
+func (s *sampler) prune(now time.Time) {
+	for key, window := range s.windows {
+		if now.Sub(window.start) >= s.interval {
+			delete(s.windows, key)
+		}
+	}
+}
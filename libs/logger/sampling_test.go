@@ -0,0 +1,124 @@
+package logger_test
+
+import (
+	"bytes"
+	"encoding/json"
+	"log/slog"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/libs/logger"
+)
+
+func TestSampler_FirstBurstThenEveryThereafter(t *testing.T) {
+	sampler := logger.NewSampler(logger.Sampling{Burst: 3, Thereafter: 4, Interval: time.Minute})
+	var written []int
+	var droppedTotal int
+	for i := 1; i <= 15; i++ {
+		ok, dropped := sampler.Allow(slog.LevelError, "Error scanning seller row")
+		if ok {
+			written = append(written, i)
+			droppedTotal += dropped
+		}
+	}
+	// Lines 1-3 are the burst, then every 4th: 7, 11 and 15.
+	if want := []int{1, 2, 3, 7, 11, 15}; !equalInts(written, want) {
+		t.Errorf("written lines = %v, want %v", written, want)
+	}
+	if droppedTotal != 9 {
+		t.Errorf("reported %d dropped lines, want 9", droppedTotal)
+	}
+}
+
+func TestSampler_ThereafterZeroDropsAllPastBurst(t *testing.T) {
+	sampler := logger.NewSampler(logger.Sampling{Burst: 2, Interval: time.Minute})
+	for i := 1; i <= 10; i++ {
+		if ok, _ := sampler.Allow(slog.LevelInfo, "hot"); ok != (i <= 2) {
+			t.Errorf("line %d: allowed = %v", i, ok)
+		}
+	}
+
+	// A new interval starts a new burst and reports what the last one dropped.
+	sampler.Backdate(time.Minute)
+	if ok, dropped := sampler.Allow(slog.LevelInfo, "hot"); !ok || dropped != 8 {
+		t.Errorf("first line of the next interval: allowed = %v, dropped = %d, want true, 8", ok, dropped)
+	}
+	if ok, _ := sampler.Allow(slog.LevelInfo, "hot"); !ok {
+		t.Error("second line of the next interval was dropped")
+	}
+	if ok, _ := sampler.Allow(slog.LevelInfo, "hot"); ok {
+		t.Error("third line of the next interval was written")
+	}
+}
+
+func TestSampler_KeysByLevelAndMessage(t *testing.T) {
+	sampler := logger.NewSampler(logger.Sampling{Burst: 1, Interval: time.Minute})
+	for _, line := range []struct {
+		level   slog.Level
+		message string
+	}{{slog.LevelInfo, "a"}, {slog.LevelInfo, "b"}, {slog.LevelError, "a"}} {
+		if ok, _ := sampler.Allow(line.level, line.message); !ok {
+			t.Errorf("first %s %q was dropped", line.level, line.message)
+		}
+	}
+	if ok, _ := sampler.Allow(slog.LevelInfo, "a"); ok {
+		t.Error("second info \"a\" was written")
+	}
+}
+
+func TestSampler_DisabledWithoutBurst(t *testing.T) {
+	if sampler := logger.NewSampler(logger.Sampling{Thereafter: 10}); sampler != nil {
+		t.Fatal("expected no sampler without a burst")
+	}
+	var buf bytes.Buffer
+	log, err := logger.NewSlogLogger(logger.Config{Output: &buf})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 50; i++ {
+		log.Info("unsampled")
+	}
+	if got := strings.Count(buf.String(), "\n"); got != 50 {
+		t.Errorf("wrote %d lines, want all 50", got)
+	}
+}
+
+func TestSlogLogger_ReportsSampledDropped(t *testing.T) {
+	var buf bytes.Buffer
+	log, err := logger.NewSlogLogger(logger.Config{Output: &buf, Sampling: logger.Sampling{Burst: 2, Thereafter: 5, Interval: time.Minute}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 12; i++ {
+		log.Error(nil, "Error scanning seller row", "attempt", i)
+	}
+
+	var dropped []int
+	for _, raw := range strings.Split(strings.TrimSpace(buf.String()), "\n") {
+		var line struct {
+			Attempt int `json:"attempt"`
+			Dropped int `json:"sampled_dropped"`
+		}
+		if err := json.Unmarshal([]byte(raw), &line); err != nil {
+			t.Fatalf("invalid JSON line %q: %v", raw, err)
+		}
+		dropped = append(dropped, line.Attempt, line.Dropped)
+	}
+	// Attempts 0 and 1 are the burst; 6 and 11 are every 5th after it, each reporting the 4 before.
+	if want := []int{0, 0, 1, 0, 6, 4, 11, 4}; !equalInts(dropped, want) {
+		t.Errorf("(attempt, sampled_dropped) pairs = %v, want %v", dropped, want)
+	}
+}
+
+func equalInts(a, b []int) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
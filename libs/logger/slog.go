@@ -0,0 +1,196 @@
+package logger
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Output formats supported by NewSlogLogger.
+const (
+	FormatJSON = "json"
+	FormatText = "text"
+)
+
+// Config configures the slog backed Logger.
+type Config struct {
+	Format         string    // FormatJSON (default) or FormatText
+	Level          string    // Minimum level: debug, info (default), warn or error
+	Output         io.Writer // Defaults to os.Stdout
+	ServiceName    string    // Added to every line as "service"
+	ServiceVersion string    // Added to every line as "version" when set
+	Redactor       *Redactor // Masks sensitive fields; defaults to NewRedactor(nil)
+	// Levels, if set, is the runtime level control (see LevelHandler); NewSlogLogger sets it to Level.
+	Levels   *LevelController
+	Sampling Sampling // Rate limit for repeated messages
+}
+
+// Defaults for ConfigFromEnv's sampling.
+const (
+	DefaultSampleBurst      = 100
+	DefaultSampleThereafter = 100
+	DefaultSampleInterval   = time.Second
+)
+
+// ConfigFromEnv builds a Config from LOG_FORMAT, LOG_LEVEL, SERVICE_VERSION, LOG_REDACTION_KEY
+// (the HMAC key for hashed fields, shared by services so hashes correlate across them),
+// LOG_SAMPLE_BURST (0 disables sampling), LOG_SAMPLE_THEREAFTER and LOG_SAMPLE_INTERVAL (e.g. "1s").
+// Invalid sampling values fall back to the defaults.
+func ConfigFromEnv(serviceName string) Config {
+	sampling := Sampling{Burst: DefaultSampleBurst, Thereafter: DefaultSampleThereafter, Interval: DefaultSampleInterval}
+	if burst, err := strconv.Atoi(os.Getenv("LOG_SAMPLE_BURST")); err == nil && burst >= 0 {
+		sampling.Burst = burst
+	}
+	if thereafter, err := strconv.Atoi(os.Getenv("LOG_SAMPLE_THEREAFTER")); err == nil && thereafter >= 0 {
+		sampling.Thereafter = thereafter
+	}
+	if interval, err := time.ParseDuration(os.Getenv("LOG_SAMPLE_INTERVAL")); err == nil && interval > 0 {
+		sampling.Interval = interval
+	}
+	return Config{
+		Format:         os.Getenv("LOG_FORMAT"),
+		Level:          os.Getenv("LOG_LEVEL"),
+		ServiceName:    serviceName,
+		ServiceVersion: os.Getenv("SERVICE_VERSION"),
+		Redactor:       NewRedactor([]byte(os.Getenv("LOG_REDACTION_KEY"))),
+		Levels:         NewLevelController(slog.LevelInfo),
+		Sampling:       sampling,
+	}
+}
+
+// ParseLevel parses a level name (debug, info, warn/warning, error), case-insensitively.
+func ParseLevel(level string) (slog.Level, error) {
+	switch strings.ToLower(strings.TrimSpace(level)) {
+	case "debug":
+		return slog.LevelDebug, nil
+	case "", "info":
+		return slog.LevelInfo, nil
+	case "warn", "warning":
+		return slog.LevelWarn, nil
+	case "error":
+		return slog.LevelError, nil
+	}
+	return slog.LevelInfo, fmt.Errorf("unknown log level %q", level)
+}
+
+// slogLogger implements Logger on log/slog, turning key/value fields into attributes.
+type slogLogger struct {
+	logger   *slog.Logger
+	levels   *LevelController
+	redactor *Redactor
+	sampler  *sampler
+}
+
+// NewSlogLogger creates a structured Logger. Every line carries the service name, version and hostname.
+// Fields, errors and messages pass through cfg.Redactor before they are written.
+func NewSlogLogger(cfg Config) (Logger, error) {
+	level, err := ParseLevel(cfg.Level)
+	if err != nil {
+		return nil, err
+	}
+	levels := cfg.Levels
+	if levels == nil {
+		levels = NewLevelController(level)
+	}
+	levels.SetLevel(level)
+
+	output := cfg.Output
+	if output == nil {
+		output = os.Stdout
+	}
+	options := &slog.HandlerOptions{Level: slog.LevelDebug} // Levels are checked by the LevelController
+	var handler slog.Handler
+	switch strings.ToLower(cfg.Format) {
+	case "", FormatJSON:
+		handler = slog.NewJSONHandler(output, options)
+	case FormatText:
+		handler = slog.NewTextHandler(output, options)
+	default:
+		return nil, fmt.Errorf("unknown log format %q", cfg.Format)
+	}
+
+	attrs := []slog.Attr{slog.String("service", cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, slog.String("version", cfg.ServiceVersion))
+	}
+	if hostname, err := os.Hostname(); err == nil {
+		attrs = append(attrs, slog.String("hostname", hostname))
+	}
+	redactor := cfg.Redactor
+	if redactor == nil {
+		redactor = NewRedactor(nil)
+	}
+	return &slogLogger{logger: slog.New(handler.WithAttrs(attrs)), levels: levels, redactor: redactor, sampler: newSampler(cfg.Sampling)}, nil
+}
+
+// Debug logs a debug message.
+func (l *slogLogger) Debug(message string, fields ...interface{}) {
+	l.log(slog.LevelDebug, nil, message, fields)
+}
+
+// Info logs an info message.
+func (l *slogLogger) Info(message string, fields ...interface{}) {
+	l.log(slog.LevelInfo, nil, message, fields)
+}
+
+// Warn logs a warning message; err may be nil.
+func (l *slogLogger) Warn(err error, message string, fields ...interface{}) {
+	l.log(slog.LevelWarn, err, message, fields)
+}
+
+// Error logs an error message; err may be nil.
+func (l *slogLogger) Error(err error, message string, fields ...interface{}) {
+	l.log(slog.LevelError, err, message, fields)
+}
+
+// With returns a Logger that adds the fields as attributes to every line.
+func (l *slogLogger) With(fields ...interface{}) Logger {
+	attrs := fieldsToAttrs(l.redactor, fields)
+	args := make([]any, len(attrs))
+	for i, attr := range attrs {
+		args[i] = attr
+	}
+	return &slogLogger{logger: l.logger.With(args...), levels: l.levels, redactor: l.redactor, sampler: l.sampler}
+}
+
+// log must be called directly from the Logger methods: the level check looks two frames up for the caller's package.
+func (l *slogLogger) log(level slog.Level, err error, message string, fields []interface{}) {
+	if !l.levels.enabled(level, 2) {
+		return
+	}
+	allowed, dropped := l.sampler.allow(level, message)
+	if !allowed {
+		return
+	}
+	attrs := fieldsToAttrs(l.redactor, fields)
+	if dropped > 0 {
+		attrs = append(attrs, slog.Int("sampled_dropped", dropped))
+	}
+	if err != nil {
+		attrs = append(attrs, slog.String("error", l.redactor.RedactString(err.Error())))
+	}
+	l.logger.LogAttrs(context.Background(), level, l.redactor.RedactString(message), attrs...)
+}
+
+// fieldsToAttrs pairs alternating key/value fields into redacted attributes. Non-string keys are
+// formatted with %v and a trailing key without a value is logged as "(MISSING)".
+func fieldsToAttrs(redactor *Redactor, fields []interface{}) []slog.Attr {
+	attrs := make([]slog.Attr, 0, len(fields)/2+1)
+	for i := 0; i < len(fields); i += 2 {
+		key, ok := fields[i].(string)
+		if !ok {
+			key = fmt.Sprintf("%v", fields[i])
+		}
+		if i+1 == len(fields) {
+			attrs = append(attrs, slog.String(key, "(MISSING)"))
+			break
+		}
+		attrs = append(attrs, slog.Any(key, redactor.RedactField(key, fields[i+1])))
+	}
+	return attrs
+}
@@ -0,0 +1,138 @@
+package metrics
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+
+	"github.com/prometheus/client_golang/prometheus"
+	dto "github.com/prometheus/client_model/go"
+)
+
+// BusinessMetrics groups the domain counters of a service (sellers created, logins, ...) under
+// <namespace>_business_*. Declare counters with NewCounter and expose them with
+// PrometheusMetrics.Register(businessMetrics).
+//
+// Counters may be declared before or after registration: BusinessMetrics describes no metrics up
+// front, so Prometheus collects whatever has been declared at scrape time.
+type BusinessMetrics struct {
+	namespace string
+	mu        sync.RWMutex
+	counters  map[string]*prometheus.CounterVec
+}
+
+// NewBusinessMetrics creates an empty BusinessMetrics, e.g. NewBusinessMetrics("seller_service").
+func NewBusinessMetrics(namespace string) *BusinessMetrics {
+	return &BusinessMetrics{namespace: namespace, counters: map[string]*prometheus.CounterVec{}}
+}
+
+// Describe implements prometheus.Collector. It sends nothing, making BusinessMetrics an unchecked collector.
+func (b *BusinessMetrics) Describe(chan<- *prometheus.Desc) {}
+
+// Collect implements prometheus.Collector.
+func (b *BusinessMetrics) Collect(ch chan<- prometheus.Metric) {
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for _, counter := range b.counters {
+		counter.Collect(ch)
+	}
+}
+
+// Counter is a business counter whose labels are the fields of L, so every call site passes the
+// full, correctly named label set:
+//
+//	type sellerLabels struct {
+//		BrandID string `label:"brand_id"`
+//		Status  string `label:"status"`
+//	}
+//	created := metrics.NewCounter[sellerLabels](kpis, "sellers_created_total", "Sellers created.")
+//	created.Inc(sellerLabels{BrandID: s.BrandID, Status: s.Status})
+//
+// Label values must come from bounded sets (enums, validated IDs), never from free text.
+type Counter[L any] struct {
+	vec *prometheus.CounterVec
+}
+
+// NewCounter declares a counter named <namespace>_business_<name> on b. L must be a struct of string
+// fields, each tagged with its label name (`label:"brand_id"`); untagged fields use the lowercased
+// field name. A nil b gives a working counter that is not exported, for tests.
+// NewCounter panics if L is not such a struct or the name is declared twice, like prometheus.MustRegister.
+func NewCounter[L any](b *BusinessMetrics, name, help string) *Counter[L] {
+	labels := labelNames(reflect.TypeOf((*L)(nil)).Elem())
+	namespace := ""
+	if b != nil {
+		namespace = b.namespace
+	}
+	vec := prometheus.NewCounterVec(
+		prometheus.CounterOpts{
+			Namespace: namespace,
+			Subsystem: "business",
+			Name:      name,
+			Help:      help,
+		},
+		labels,
+	)
+	if b != nil {
+		b.mu.Lock()
+		defer b.mu.Unlock()
+		if _, exists := b.counters[name]; exists {
+			panic(fmt.Sprintf("metrics: business counter %s declared twice", name))
+		}
+		b.counters[name] = vec
+	}
+	return &Counter[L]{vec: vec}
+}
+
+// Inc adds one to the counter for labels. It is a no-op on a nil Counter.
+func (c *Counter[L]) Inc(labels L) {
+	c.Add(labels, 1)
+}
+
+// Add adds v (which must not be negative) to the counter for labels. It is a no-op on a nil Counter.
+func (c *Counter[L]) Add(labels L, v float64) {
+	if c == nil {
+		return
+	}
+	c.vec.WithLabelValues(labelValues(labels)...).Add(v)
+}
+
+// Value returns the current count for labels, for tests.
+func (c *Counter[L]) Value(labels L) float64 {
+	if c == nil {
+		return 0
+	}
+	metric := &dto.Metric{}
+	if err := c.vec.WithLabelValues(labelValues(labels)...).Write(metric); err != nil {
+		return 0
+	}
+	return metric.GetCounter().GetValue()
+}
+
+func labelValues(labels interface{}) []string {
+	value := reflect.ValueOf(labels)
+	values := make([]string, value.NumField())
+	for i := range values {
+		values[i] = value.Field(i).String()
+	}
+	return values
+}
+
+// labelNames returns the label names of a label struct type, in field order.
+func labelNames(t reflect.Type) []string {
+	if t.Kind() != reflect.Struct {
+		panic(fmt.Sprintf("metrics: counter labels must be a struct, got %s", t))
+	}
+	labels := make([]string, t.NumField())
+	for i := range labels {
+		field := t.Field(i)
+		if field.Type.Kind() != reflect.String {
+			panic(fmt.Sprintf("metrics: label field %s.%s must be a string", t, field.Name))
+		}
+		labels[i] = field.Tag.Get("label")
+		if labels[i] == "" {
+			labels[i] = strings.ToLower(field.Name)
+		}
+	}
+	return labels
+}
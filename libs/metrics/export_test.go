@@ -0,0 +1,36 @@
+package metrics
+
+import (
+	"fmt"
+
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/graphql-go/graphql/language/parser"
+)
+
+// QueryDepth parses query, which need not be valid against any schema, and returns the selection
+// depth of its first operation as recorded by InstrumentGraphQL.
+func QueryDepth(query string) (int, error) {
+	doc, err := parser.Parse(parser.ParseParams{Source: query})
+	if err != nil {
+		return 0, err
+	}
+	fragments := map[string]ast.Definition{}
+	var operation *ast.OperationDefinition
+	for _, definition := range doc.Definitions {
+		switch d := definition.(type) {
+		case *ast.FragmentDefinition:
+			fragments[d.Name.Value] = d
+		case *ast.OperationDefinition:
+			if operation == nil {
+				operation = d
+			}
+		}
+	}
+	if operation == nil {
+		return 0, fmt.Errorf("no operation in %q", query)
+	}
+	return selectionDepth(operation.SelectionSet, fragments, map[string]bool{}), nil
+}
+
+// MaxGraphQLOperations is the number of operation names labeled before OtherOperation is used.
+const MaxGraphQLOperations = maxGraphQLOperations
@@ -0,0 +1,288 @@
+package metrics
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Labels for GraphQL requests whose operation is unnamed, or unknown because the query did not parse.
+const (
+	AnonymousOperation   = "anonymous"
+	UnknownOperationType = "unknown"
+	// OtherOperation replaces operation names beyond maxGraphQLOperations, since clients choose them.
+	OtherOperation = "other"
+)
+
+const maxGraphQLOperations = 200
+
+// GraphQLOperation describes a completed GraphQL request, as observed by InstrumentGraphQL.
+type GraphQLOperation struct {
+	Name     string // Operation name, or AnonymousOperation
+	Type     string // query, mutation, subscription, or UnknownOperationType
+	Depth    int    // Deepest field nesting of the selection set; 0 if the query did not parse
+	Duration time.Duration
+	Errors   int
+}
+
+// GraphQLField describes one resolved field, as observed by InstrumentGraphQL.
+type GraphQLField struct {
+	ParentType string // e.g. Query, Seller
+	Field      string
+	Duration   time.Duration
+	Err        error
+}
+
+// InstrumentGraphQL returns a graphql-go extension calling onOperation after every request and
+// onField after every field resolver. It is the building block of PrometheusMetrics.GraphQLExtension;
+// add the extension with Schema.AddExtensions.
+func InstrumentGraphQL(onOperation func(GraphQLOperation), onField func(GraphQLField)) graphql.Extension {
+	return &graphQLExtension{onOperation: onOperation, onField: onField}
+}
+
+type graphQLExtension struct {
+	onOperation func(GraphQLOperation)
+	onField     func(GraphQLField)
+}
+
+type graphQLRequestKey struct{}
+
+// graphQLRequest is the per-request state, filled in as graphql-go moves through its phases.
+// The phases run one after another, so it needs no locking.
+type graphQLRequest struct {
+	start    time.Time
+	op       GraphQLOperation
+	resolved bool // Operation type and depth known
+	done     bool
+}
+
+func (e *graphQLExtension) Name() string { return "metrics" }
+
+func (e *graphQLExtension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	if ctx == nil {
+		ctx = context.Background() // graphql.Do allows Params without a context
+	}
+	name := p.OperationName
+	if name == "" {
+		name = AnonymousOperation
+	}
+	req := &graphQLRequest{start: time.Now(), op: GraphQLOperation{Name: name, Type: UnknownOperationType}}
+	return context.WithValue(ctx, graphQLRequestKey{}, req)
+}
+
+func (e *graphQLExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	return ctx, func(err error) {
+		if err != nil {
+			e.finish(ctx, 1)
+		}
+	}
+}
+
+func (e *graphQLExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	return ctx, func(errs []gqlerrors.FormattedError) {
+		if len(errs) > 0 {
+			e.finish(ctx, len(errs))
+		}
+	}
+}
+
+func (e *graphQLExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	return ctx, func(result *graphql.Result) {
+		errs := 0
+		if result != nil {
+			errs = len(result.Errors)
+		}
+		e.finish(ctx, errs)
+	}
+}
+
+func (e *graphQLExtension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	if req, ok := ctx.Value(graphQLRequestKey{}).(*graphQLRequest); ok && !req.resolved {
+		req.resolved = true
+		if op, ok := info.Operation.(*ast.OperationDefinition); ok {
+			req.op.Type = op.Operation
+			if op.Name != nil && op.Name.Value != "" {
+				req.op.Name = op.Name.Value
+			}
+			req.op.Depth = selectionDepth(op.SelectionSet, info.Fragments, map[string]bool{})
+		}
+	}
+	start := time.Now()
+	return ctx, func(_ interface{}, err error) {
+		e.onField(GraphQLField{ParentType: info.ParentType.Name(), Field: info.FieldName, Duration: time.Since(start), Err: err})
+	}
+}
+
+func (e *graphQLExtension) HasResult() bool { return false }
+
+func (e *graphQLExtension) GetResult(context.Context) interface{} { return nil }
+
+func (e *graphQLExtension) finish(ctx context.Context, errs int) {
+	req, ok := ctx.Value(graphQLRequestKey{}).(*graphQLRequest)
+	if !ok || req.done {
+		return
+	}
+	req.done = true
+	req.op.Duration = time.Since(req.start)
+	req.op.Errors = errs
+	e.onOperation(req.op)
+}
+
+// selectionDepth returns how deeply fields nest in set, following fragments; visited guards against
+// fragment cycles in queries that failed validation.
+func selectionDepth(set *ast.SelectionSet, fragments map[string]ast.Definition, visited map[string]bool) int {
+	if set == nil {
+		return 0
+	}
+	depth := 0
+	for _, selection := range set.Selections {
+		d := 0
+		switch s := selection.(type) {
+		case *ast.Field:
+			d = 1 + selectionDepth(s.SelectionSet, fragments, visited)
+		case *ast.InlineFragment:
+			d = selectionDepth(s.SelectionSet, fragments, visited)
+		case *ast.FragmentSpread:
+			name := s.Name.Value
+			if fragment, ok := fragments[name].(*ast.FragmentDefinition); ok && !visited[name] {
+				visited[name] = true
+				d = selectionDepth(fragment.SelectionSet, fragments, visited)
+				delete(visited, name)
+			}
+		}
+		if d > depth {
+			depth = d
+		}
+	}
+	return depth
+}
+
+// graphQLMetrics holds the Prometheus metrics recorded by promMetrics.GraphQLExtension.
+type graphQLMetrics struct {
+	operations        *prometheus.CounterVec
+	operationDuration *prometheus.HistogramVec
+	operationErrors   *prometheus.CounterVec
+	queryDepth        *prometheus.HistogramVec
+	fieldDuration     *prometheus.HistogramVec
+	fieldErrors       *prometheus.CounterVec
+
+	mu    sync.Mutex
+	names map[string]bool // Operation names seen so far, capped at maxGraphQLOperations
+}
+
+func newGraphQLMetrics(serviceName, subsystem string, constLabels prometheus.Labels) *graphQLMetrics {
+	return &graphQLMetrics{
+		operations: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_operations_total",
+				Help:        "Total number of GraphQL operations by operation name and type.",
+			},
+			[]string{"operation", "type"},
+		),
+		operationDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_operation_duration_seconds",
+				Help:        "GraphQL operation latency in seconds, from parsing to the last resolver, by operation name and type.",
+				Buckets:     prometheus.DefBuckets,
+			},
+			[]string{"operation", "type"},
+		),
+		operationErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_operation_errors_total",
+				Help:        "Total number of errors returned in GraphQL responses by operation name and type.",
+			},
+			[]string{"operation", "type"},
+		),
+		queryDepth: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_query_depth",
+				Help:        "Field nesting depth of GraphQL operations by operation name and type.",
+				Buckets:     prometheus.LinearBuckets(1, 1, 10),
+			},
+			[]string{"operation", "type"},
+		),
+		fieldDuration: prometheus.NewHistogramVec(
+			prometheus.HistogramOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_field_duration_seconds",
+				Help:        "GraphQL resolver latency in seconds by parent type and field.",
+				Buckets:     []float64{.0001, .0005, .001, .005, .01, .05, .1, .5, 1},
+			},
+			[]string{"parent_type", "field"},
+		),
+		fieldErrors: prometheus.NewCounterVec(
+			prometheus.CounterOpts{
+				Namespace:   serviceName,
+				Subsystem:   subsystem,
+				ConstLabels: constLabels,
+				Name:        "graphql_field_errors_total",
+				Help:        "Total number of GraphQL resolver errors by parent type and field.",
+			},
+			[]string{"parent_type", "field"},
+		),
+		names: map[string]bool{},
+	}
+}
+
+func (g *graphQLMetrics) collectors() []prometheus.Collector {
+	return []prometheus.Collector{g.operations, g.operationDuration, g.operationErrors, g.queryDepth, g.fieldDuration, g.fieldErrors}
+}
+
+func (g *graphQLMetrics) observeOperation(op GraphQLOperation) {
+	name := g.operationLabel(op.Name)
+	g.operations.WithLabelValues(name, op.Type).Inc()
+	g.operationDuration.WithLabelValues(name, op.Type).Observe(op.Duration.Seconds())
+	if op.Errors > 0 {
+		g.operationErrors.WithLabelValues(name, op.Type).Add(float64(op.Errors))
+	}
+	if op.Depth > 0 {
+		g.queryDepth.WithLabelValues(name, op.Type).Observe(float64(op.Depth))
+	}
+}
+
+func (g *graphQLMetrics) observeField(field GraphQLField) {
+	g.fieldDuration.WithLabelValues(field.ParentType, field.Field).Observe(field.Duration.Seconds())
+	if field.Err != nil {
+		g.fieldErrors.WithLabelValues(field.ParentType, field.Field).Inc()
+	}
+}
+
+// operationLabel keeps the label set bounded however many operation names clients send.
+func (g *graphQLMetrics) operationLabel(name string) string {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	if g.names[name] {
+		return name
+	}
+	if len(g.names) >= maxGraphQLOperations {
+		return OtherOperation
+	}
+	g.names[name] = true
+	return name
+}
+
+// GraphQLExtension records operation counts, latency, errors and query depth, and per-field resolver
+// latency and errors, for a graphql-go schema. Add it with Schema.AddExtensions.
+func (pm *promMetrics) GraphQLExtension() graphql.Extension {
+	return InstrumentGraphQL(pm.graphql.observeOperation, pm.graphql.observeField)
+}
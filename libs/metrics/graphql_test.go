@@ -0,0 +1,154 @@
+package metrics_test
+
+import (
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/libs/metrics/metricstest"
+)
+
+func newSellerSchema(t *testing.T, extensions ...graphql.Extension) graphql.Schema {
+	t.Helper()
+	seller := graphql.NewObject(graphql.ObjectConfig{Name: "Seller", Fields: graphql.Fields{
+		"name": &graphql.Field{Type: graphql.String},
+	}})
+	seller.AddFieldConfig("parent", &graphql.Field{Type: seller})
+	resolveSeller := func(p graphql.ResolveParams) (interface{}, error) {
+		return map[string]interface{}{"name": "Acme", "parent": map[string]interface{}{"name": "Acme Group"}}, nil
+	}
+	schema, err := graphql.NewSchema(graphql.SchemaConfig{
+		Query: graphql.NewObject(graphql.ObjectConfig{Name: "Query", Fields: graphql.Fields{
+			"seller": &graphql.Field{Type: seller, Resolve: resolveSeller},
+			"broken": &graphql.Field{Type: graphql.String, Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				return nil, errors.New("resolver failed")
+			}},
+		}}),
+		Mutation: graphql.NewObject(graphql.ObjectConfig{Name: "Mutation", Fields: graphql.Fields{
+			"createSeller": &graphql.Field{Type: seller, Resolve: resolveSeller},
+		}}),
+	})
+	if err != nil {
+		t.Fatalf("NewSchema: %v", err)
+	}
+	schema.AddExtensions(extensions...)
+	return schema
+}
+
+func TestSelectionDepth(t *testing.T) {
+	tests := map[string]struct {
+		query string
+		want  int
+	}{
+		"flat":            {`{ seller { name } }`, 2},
+		"nested":          {`{ seller { name parent { parent { name } } } }`, 4},
+		"widest branch":   {`{ seller { name } broken }`, 2},
+		"inline fragment": {`{ seller { ... on Seller { parent { name } } } }`, 3},
+		"named fragment": {`
+			{ seller { ...withParent } }
+			fragment withParent on Seller { parent { ...names } }
+			fragment names on Seller { name }`, 3},
+		"fragment used twice": {`
+			{ seller { ...names parent { ...names } } }
+			fragment names on Seller { name }`, 3},
+		"unknown fragment": {`{ seller { ...missing name } }`, 2},
+		"fragment cycle": {`
+			{ seller { ...a } }
+			fragment a on Seller { parent { ...b } }
+			fragment b on Seller { parent { ...a } }`, 3},
+		"self-referencing fragment": {`
+			{ seller { ...a } }
+			fragment a on Seller { name parent { ...a } }`, 2},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			depth, err := metrics.QueryDepth(tt.query)
+			if err != nil {
+				t.Fatal(err)
+			}
+			if depth != tt.want {
+				t.Errorf("depth = %d, want %d", depth, tt.want)
+			}
+		})
+	}
+}
+
+func TestInstrumentGraphQL_FinishesOncePerRequest(t *testing.T) {
+	tests := map[string]struct {
+		query, operationName string
+		name, opType         string
+		depth, errors        int
+	}{
+		"success":          {`query GetSeller { seller { name parent { name } } }`, "", "GetSeller", "query", 3, 0},
+		"anonymous":        {`{ seller { name } }`, "", metrics.AnonymousOperation, "query", 2, 0},
+		"mutation":         {`mutation { createSeller { name } }`, "", metrics.AnonymousOperation, "mutation", 2, 0},
+		"selected by name": {`query A { seller { name } } query B { broken }`, "B", "B", "query", 1, 1},
+		"parse error":      {`query Broken { seller {`, "", metrics.AnonymousOperation, metrics.UnknownOperationType, 0, 1},
+		"validation error": {`{ seller { missing other } }`, "", metrics.AnonymousOperation, metrics.UnknownOperationType, 0, 2},
+		"execution error":  {`query Failing { broken seller { name } }`, "", "Failing", "query", 2, 1},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			recorder := metricstest.NewRecorder()
+			schema := newSellerSchema(t, recorder.GraphQLExtension())
+			graphql.Do(graphql.Params{Schema: schema, RequestString: tt.query, OperationName: tt.operationName})
+
+			ops := recorder.GraphQLOperations()
+			if len(ops) != 1 {
+				t.Fatalf("recorded %d operations, want exactly 1: %+v", len(ops), ops)
+			}
+			op := recorder.AssertGraphQLOperation(t, tt.name, tt.opType)
+			if op.Depth != tt.depth || op.Errors != tt.errors {
+				t.Errorf("depth = %d, errors = %d, want %d and %d", op.Depth, op.Errors, tt.depth, tt.errors)
+			}
+		})
+	}
+}
+
+func TestInstrumentGraphQL_Fields(t *testing.T) {
+	recorder := metricstest.NewRecorder()
+	schema := newSellerSchema(t, recorder.GraphQLExtension())
+	graphql.Do(graphql.Params{Schema: schema, RequestString: `{ broken seller { name } }`})
+
+	resolved := map[string]error{}
+	for _, field := range recorder.GraphQLFields() {
+		resolved[field.ParentType+"."+field.Field] = field.Err
+	}
+	if len(resolved) != 3 {
+		t.Errorf("resolved fields = %v, want Query.broken, Query.seller and Seller.name", resolved)
+	}
+	if resolved["Query.broken"] == nil || resolved["Query.seller"] != nil {
+		t.Errorf("field errors = %v, want only Query.broken to fail", resolved)
+	}
+}
+
+func TestPrometheusMetrics_CapsOperationNames(t *testing.T) {
+	pm := metrics.NewPrometheusMetrics("test", "seller")
+	schema := newSellerSchema(t, pm.GraphQLExtension())
+	for i := 0; i < metrics.MaxGraphQLOperations+5; i++ {
+		graphql.Do(graphql.Params{Schema: schema, RequestString: fmt.Sprintf(`query Op%d { seller { name } }`, i)})
+	}
+	graphql.Do(graphql.Params{Schema: schema, RequestString: `query Op0 { seller { name } }`})
+
+	scrape := httptest.NewRecorder()
+	pm.Handler().ServeHTTP(scrape, httptest.NewRequest(http.MethodGet, "/metrics", nil))
+	out := scrape.Body.String()
+	for _, want := range []string{
+		`test_seller_graphql_operations_total{operation="Op0",type="query"} 2`,
+		fmt.Sprintf(`test_seller_graphql_operations_total{operation="Op%d",type="query"} 1`, metrics.MaxGraphQLOperations-1),
+		`test_seller_graphql_operations_total{operation="other",type="query"} 5`,
+		`test_seller_graphql_query_depth_count{operation="Op0",type="query"} 2`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+	if strings.Contains(out, fmt.Sprintf(`operation="Op%d"`, metrics.MaxGraphQLOperations)) {
+		t.Errorf("operation name beyond the cap of %d was used as a label", metrics.MaxGraphQLOperations)
+	}
+}
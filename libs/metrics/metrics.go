@@ -5,7 +5,9 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/graphql-go/graphql"
 	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/collectors"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 )
 
@@ -20,86 +22,175 @@ type PrometheusMetrics interface {
 	IncResponsesTotal(operation, handlerType, statusCode string) // Simplified based on seller_handler usage
 	NewRequestDurationTimer(operation, handlerType string) RequestDurationTimer
 	Handler() http.Handler // To expose metrics endpoint
+	// Middleware records RED metrics for every request routed by a gorilla/mux router (see middleware.go).
+	Middleware(next http.Handler) http.Handler
+	// GraphQLExtension records operation and resolver metrics for a graphql-go schema (see graphql.go).
+	GraphQLExtension() graphql.Extension
+	// Register exposes additional collectors (e.g. database metrics) on Handler.
+	Register(collectors ...prometheus.Collector) error
 	// Add other metrics methods like IncErrors, ObserveRequestDuration, etc.
 }
 
 // promMetrics is a concrete implementation using Prometheus (currently placeholder).
 type promMetrics struct {
-	requests *prometheus.CounterVec // Example
-	requestsTotal   *prometheus.CounterVec
-	responsesTotal  *prometheus.CounterVec // Added to store the responsesTotal metric
-	requestDuration *prometheus.HistogramVec // To track request durations
-	serviceName     string
-	subsystem       string
+	requests         *prometheus.CounterVec // HTTP requests by route template, method and status code
+	httpDuration     *prometheus.HistogramVec
+	httpRequestSize  *prometheus.HistogramVec
+	httpResponseSize *prometheus.HistogramVec
+	requestsTotal    *prometheus.CounterVec
+	responsesTotal   *prometheus.CounterVec   // Added to store the responsesTotal metric
+	requestDuration  *prometheus.HistogramVec // To track request durations
+	graphql          *graphQLMetrics
+	registry         *prometheus.Registry
 }
 
-// NewPrometheusMetrics creates a new PrometheusMetrics instance.
-func NewPrometheusMetrics(serviceName, subsystem string) PrometheusMetrics {
-	// In a real scenario, you would initialize and register Prometheus collectors here.
-	// Example:
+// Option configures NewPrometheusMetrics.
+type Option func(*options)
+
+type options struct {
+	registry    *prometheus.Registry
+	constLabels prometheus.Labels
+}
+
+// WithRegistry registers the metrics on registry, e.g. to expose other collectors on the same
+// /metrics endpoint. By default each PrometheusMetrics has its own registry with Go and process collectors.
+func WithRegistry(registry *prometheus.Registry) Option {
+	return func(o *options) { o.registry = registry }
+}
+
+// WithConstLabels adds the labels (e.g. {"env": "prod"}) to every metric.
+func WithConstLabels(labels prometheus.Labels) Option {
+	return func(o *options) { o.constLabels = labels }
+}
+
+// NewPrometheusMetrics creates a new PrometheusMetrics instance. Metrics are registered on the instance's
+// own registry, so several instances (e.g. one per test) can coexist in a process.
+func NewPrometheusMetrics(serviceName, subsystem string, opts ...Option) PrometheusMetrics {
+	o := &options{}
+	for _, opt := range opts {
+		opt(o)
+	}
+	if o.registry == nil {
+		o.registry = prometheus.NewRegistry()
+		o.registry.MustRegister(collectors.NewGoCollector(), collectors.NewProcessCollector(collectors.ProcessCollectorOpts{}))
+	}
+
+	// HTTP RED metrics recorded by Middleware, labeled by route template rather than raw path
 	requests := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Namespace: serviceName,
-			Subsystem: subsystem,
-			Name:      "http_requests_total",
-			Help:      "Total number of HTTP requests.",
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "http_requests_total",
+			Help:        "Total number of HTTP requests by route, method and status code.",
+		},
+		[]string{"route", "method", "code"},
+	)
+	httpDuration := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "http_request_duration_seconds",
+			Help:        "HTTP request latency in seconds by route, method and status code.",
+			Buckets:     prometheus.DefBuckets,
+		},
+		[]string{"route", "method", "code"},
+	)
+	httpRequestSize := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "http_request_size_bytes",
+			Help:        "HTTP request body size in bytes by route and method.",
+			Buckets:     sizeBuckets,
+		},
+		[]string{"route", "method"},
+	)
+	httpResponseSize := prometheus.NewHistogramVec(
+		prometheus.HistogramOpts{
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "http_response_size_bytes",
+			Help:        "HTTP response body size in bytes by route and method.",
+			Buckets:     sizeBuckets,
 		},
-		[]string{"path", "method", "code"},
+		[]string{"route", "method"},
 	)
 	// Note: The seller_handler uses operation/handlerType, not path/method/code for IncRequestsTotal.
 	// Let's adjust the metric definition to match the handler's usage for simplicity.
 	requestsTotal := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Namespace: serviceName,
-			Subsystem: subsystem,
-			Name:      "requests_total",
-			Help:      "Total number of requests by operation and handler type.",
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "requests_total",
+			Help:        "Total number of requests by operation and handler type.",
 		},
 		[]string{"operation", "handler_type"},
 	)
 
 	responsesTotal := prometheus.NewCounterVec(
 		prometheus.CounterOpts{
-			Namespace: serviceName,
-			Subsystem: subsystem,
-			Name:      "responses_total",
-			Help:      "Total number of responses by operation, handler type, and status code.",
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "responses_total",
+			Help:        "Total number of responses by operation, handler type, and status code.",
 		},
 		[]string{"operation", "handler_type", "code"},
 	)
 
 	requestDuration := prometheus.NewHistogramVec(
 		prometheus.HistogramOpts{
-			Namespace: serviceName,
-			Subsystem: subsystem,
-			Name:      "request_duration_seconds",
-			Help:      "Request duration in seconds by operation and handler type.",
-			Buckets:   prometheus.DefBuckets, // Default buckets (0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10)
+			Namespace:   serviceName,
+			Subsystem:   subsystem,
+			ConstLabels: o.constLabels,
+			Name:        "request_duration_seconds",
+			Help:        "Request duration in seconds by operation and handler type.",
+			Buckets:     prometheus.DefBuckets, // Default buckets (0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10)
 		},
 		[]string{"operation", "handler_type"},
 	)
 
-	prometheus.MustRegister(requests,requestsTotal, responsesTotal, requestDuration)
+	graphQL := newGraphQLMetrics(serviceName, subsystem, o.constLabels)
+
+	o.registry.MustRegister(requests, httpDuration, httpRequestSize, httpResponseSize, requestsTotal, responsesTotal, requestDuration)
+	o.registry.MustRegister(graphQL.collectors()...)
 
-	fmt.Printf("Metrics: Initialized for service %s, subsystem %s\n", serviceName, subsystem)
 	return &promMetrics{
-		requests : requests,
-		requestsTotal:   requestsTotal,
-		responsesTotal:  responsesTotal, // Assign the created metric
-		requestDuration: requestDuration,
-		serviceName:     serviceName,
-		subsystem:       subsystem,
+		requests:         requests,
+		httpDuration:     httpDuration,
+		httpRequestSize:  httpRequestSize,
+		httpResponseSize: httpResponseSize,
+		requestsTotal:    requestsTotal,
+		responsesTotal:   responsesTotal, // Assign the created metric
+		requestDuration:  requestDuration,
+		graphql:          graphQL,
+		registry:         o.registry,
 	}
 }
 
 // Handler returns an http.Handler for exposing Prometheus metrics.
 func (pm *promMetrics) Handler() http.Handler {
-	return promhttp.Handler() // Uncomment when using actual prometheus client
+	return promhttp.HandlerFor(pm.registry, promhttp.HandlerOpts{Registry: pm.registry, EnableOpenMetrics: true})
 	// return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
 	// 	fmt.Fprintln(w, "# Placeholder metrics endpoint")
 	// })
 }
 
+// Register adds collectors to the instance's registry.
+func (pm *promMetrics) Register(collectors ...prometheus.Collector) error {
+	for _, c := range collectors {
+		if err := pm.registry.Register(c); err != nil {
+			return fmt.Errorf("failed to register collector: %w", err)
+		}
+	}
+	return nil
+}
+
 // IncRequestsTotal increments a request counter by operation and handler type.
 func (pm *promMetrics) IncRequestsTotal(operation, handlerType string) {
 	pm.requestsTotal.WithLabelValues(operation, handlerType).Inc()
@@ -112,23 +203,20 @@ func (pm *promMetrics) IncResponsesTotal(operation, handlerType, statusCode stri
 
 // promTimer is a concrete implementation of RequestDurationTimer.
 type promTimer struct {
-	start time.Time
+	start    time.Time
 	observer prometheus.Observer // Can be a Histogram or Summary
 }
 
 // ObserveDuration records the elapsed time.
 func (pt *promTimer) ObserveDuration() {
-	duration := time.Since(pt.start).Seconds()
-	fmt.Printf("Metrics: Observing duration %.4f seconds\n", duration)
-	pt.observer.Observe(duration)
+	pt.observer.Observe(time.Since(pt.start).Seconds())
 }
 
 // NewRequestDurationTimer starts a timer for a given operation and handler type.
 func (pm *promMetrics) NewRequestDurationTimer(operation, handlerType string) RequestDurationTimer {
-	fmt.Printf("Metrics (%s - %s): Starting timer for operation: %s, handler_type: %s\n", pm.serviceName, pm.subsystem, operation, handlerType)
 	observer := pm.requestDuration.WithLabelValues(operation, handlerType)
 	return &promTimer{
-		start: time.Now(),
+		start:    time.Now(),
 		observer: observer,
 	}
-}
\ No newline at end of file
+}
@@ -0,0 +1,226 @@
+// Package metricstest provides an in-memory metrics.PrometheusMetrics for unit tests.
+package metricstest
+
+import (
+	"fmt"
+	"net/http"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/graphql-go/graphql"
+	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/prometheus/client_golang/prometheus"
+)
+
+// Recorder records every metric emitted through it. Use it in place of NewPrometheusMetrics
+// and check the results with the Assert helpers.
+type Recorder struct {
+	mu        sync.Mutex
+	requests  map[string]int // "operation handler_type"
+	responses map[string]int // "operation handler_type code"
+	durations map[string]int // "operation handler_type"
+	http      []metrics.HTTPRequest
+	graphql   []metrics.GraphQLOperation
+	fields    []metrics.GraphQLField
+}
+
+// NewRecorder creates an empty Recorder.
+func NewRecorder() *Recorder {
+	return &Recorder{requests: map[string]int{}, responses: map[string]int{}, durations: map[string]int{}}
+}
+
+var _ metrics.PrometheusMetrics = (*Recorder)(nil)
+
+// IncRequestsTotal records a request.
+func (r *Recorder) IncRequestsTotal(operation, handlerType string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.requests[key(operation, handlerType)]++
+}
+
+// IncResponsesTotal records a response.
+func (r *Recorder) IncResponsesTotal(operation, handlerType, statusCode string) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.responses[key(operation, handlerType, statusCode)]++
+}
+
+// NewRequestDurationTimer returns a timer that records one observation when stopped.
+func (r *Recorder) NewRequestDurationTimer(operation, handlerType string) metrics.RequestDurationTimer {
+	return timer(func() {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.durations[key(operation, handlerType)]++
+	})
+}
+
+// Handler serves a plain-text dump of the recorded metrics, for debugging.
+func (r *Recorder) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, _ *http.Request) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		for k, n := range r.requests {
+			fmt.Fprintf(w, "requests_total{%s} %d\n", k, n)
+		}
+		for k, n := range r.responses {
+			fmt.Fprintf(w, "responses_total{%s} %d\n", k, n)
+		}
+		for _, req := range r.http {
+			fmt.Fprintf(w, "http_request{%s %s %d}\n", req.Method, req.Route, req.StatusCode)
+		}
+		for _, op := range r.graphql {
+			fmt.Fprintf(w, "graphql_operation{%s %s} errors=%d depth=%d\n", op.Type, op.Name, op.Errors, op.Depth)
+		}
+	})
+}
+
+// Middleware records every HTTP request like metrics.PrometheusMetrics.Middleware.
+func (r *Recorder) Middleware(next http.Handler) http.Handler {
+	return metrics.InstrumentHandler(next, func(req metrics.HTTPRequest) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.http = append(r.http, req)
+	})
+}
+
+// GraphQLExtension records every GraphQL operation and resolved field like
+// metrics.PrometheusMetrics.GraphQLExtension.
+func (r *Recorder) GraphQLExtension() graphql.Extension {
+	return metrics.InstrumentGraphQL(func(op metrics.GraphQLOperation) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.graphql = append(r.graphql, op)
+	}, func(field metrics.GraphQLField) {
+		r.mu.Lock()
+		defer r.mu.Unlock()
+		r.fields = append(r.fields, field)
+	})
+}
+
+// Register accepts and ignores collectors.
+func (r *Recorder) Register(...prometheus.Collector) error {
+	return nil
+}
+
+// RequestCount returns how often IncRequestsTotal was called with the labels.
+func (r *Recorder) RequestCount(operation, handlerType string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.requests[key(operation, handlerType)]
+}
+
+// ResponseCount returns how often IncResponsesTotal was called with the labels.
+func (r *Recorder) ResponseCount(operation, handlerType, statusCode string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.responses[key(operation, handlerType, statusCode)]
+}
+
+// DurationCount returns how many timers for the labels were stopped.
+func (r *Recorder) DurationCount(operation, handlerType string) int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.durations[key(operation, handlerType)]
+}
+
+// HTTPRequests returns the requests recorded by Middleware, oldest first.
+func (r *Recorder) HTTPRequests() []metrics.HTTPRequest {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]metrics.HTTPRequest(nil), r.http...)
+}
+
+// HTTPRequestCount returns how many requests Middleware recorded for the route, method and status code.
+func (r *Recorder) HTTPRequestCount(route, method string, statusCode int) int {
+	count := 0
+	for _, req := range r.HTTPRequests() {
+		if req.Route == route && req.Method == method && req.StatusCode == statusCode {
+			count++
+		}
+	}
+	return count
+}
+
+// GraphQLOperations returns the operations recorded by GraphQLExtension, oldest first.
+func (r *Recorder) GraphQLOperations() []metrics.GraphQLOperation {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]metrics.GraphQLOperation(nil), r.graphql...)
+}
+
+// GraphQLFields returns the resolved fields recorded by GraphQLExtension, oldest first.
+func (r *Recorder) GraphQLFields() []metrics.GraphQLField {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]metrics.GraphQLField(nil), r.fields...)
+}
+
+// AssertRequest fails the test unless IncRequestsTotal was called with the labels.
+func (r *Recorder) AssertRequest(t testing.TB, operation, handlerType string) {
+	t.Helper()
+	if r.RequestCount(operation, handlerType) == 0 {
+		t.Errorf("expected request metric {%s}, got %v", key(operation, handlerType), r.snapshot(r.requests))
+	}
+}
+
+// AssertResponse fails the test unless IncResponsesTotal was called with the labels.
+func (r *Recorder) AssertResponse(t testing.TB, operation, handlerType, statusCode string) {
+	t.Helper()
+	if r.ResponseCount(operation, handlerType, statusCode) == 0 {
+		t.Errorf("expected response metric {%s}, got %v", key(operation, handlerType, statusCode), r.snapshot(r.responses))
+	}
+}
+
+// AssertHTTPRequest fails the test unless Middleware recorded exactly one request with the labels.
+func (r *Recorder) AssertHTTPRequest(t testing.TB, route, method string, statusCode int) {
+	t.Helper()
+	if n := r.HTTPRequestCount(route, method, statusCode); n != 1 {
+		t.Errorf("expected 1 HTTP request {%s %s %d}, got %d; recorded %v", method, route, statusCode, n, r.HTTPRequests())
+	}
+}
+
+// AssertGraphQLOperation fails the test unless GraphQLExtension recorded exactly one operation with
+// the name and type, and returns it for further checks (depth, errors).
+func (r *Recorder) AssertGraphQLOperation(t testing.TB, name, opType string) metrics.GraphQLOperation {
+	t.Helper()
+	var found []metrics.GraphQLOperation
+	for _, op := range r.GraphQLOperations() {
+		if op.Name == name && op.Type == opType {
+			found = append(found, op)
+		}
+	}
+	if len(found) != 1 {
+		t.Errorf("expected 1 GraphQL operation {%s %s}, got %d; recorded %v", opType, name, len(found), r.GraphQLOperations())
+		return metrics.GraphQLOperation{}
+	}
+	return found[0]
+}
+
+// AssertNoMetrics fails the test if anything was recorded.
+func (r *Recorder) AssertNoMetrics(t testing.TB) {
+	t.Helper()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if len(r.requests)+len(r.responses)+len(r.durations)+len(r.http)+len(r.graphql)+len(r.fields) > 0 {
+		t.Errorf("expected no metrics, got requests %v, responses %v, HTTP %v, GraphQL %v", r.requests, r.responses, r.http, r.graphql)
+	}
+}
+
+func (r *Recorder) snapshot(m map[string]int) map[string]int {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := make(map[string]int, len(m))
+	for k, v := range m {
+		copied[k] = v
+	}
+	return copied
+}
+
+func key(labels ...string) string {
+	return strings.Join(labels, " ")
+}
+
+type timer func()
+
+func (t timer) ObserveDuration() { t() }
@@ -0,0 +1,140 @@
+package metrics
+
+import (
+	"io"
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/prometheus/client_golang/prometheus"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// UnmatchedRoute labels requests that did not match a mux route, keeping raw paths out of labels.
+const UnmatchedRoute = "unmatched"
+
+// sizeBuckets spans 128 B to 2 MiB.
+var sizeBuckets = prometheus.ExponentialBuckets(128, 4, 8)
+
+// HTTPRequest describes a completed request, as observed by InstrumentHandler.
+type HTTPRequest struct {
+	Route        string // mux route template, or UnmatchedRoute
+	Method       string
+	StatusCode   int
+	Duration     time.Duration
+	RequestSize  int64
+	ResponseSize int64
+	TraceID      string // Sampled trace of the request, if any; attached to the duration as an exemplar
+}
+
+// InstrumentHandler calls observe after every request served by next. It is the building block of
+// Middleware and of other PrometheusMetrics implementations.
+func InstrumentHandler(next http.Handler, observe func(HTTPRequest)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		body := &countingReader{ReadCloser: r.Body}
+		if r.Body != nil {
+			r.Body = body
+		}
+		recorder := &responseRecorder{ResponseWriter: w, status: http.StatusOK}
+
+		next.ServeHTTP(recorder, r)
+
+		traceID := ""
+		if sc := trace.SpanContextFromContext(r.Context()); sc.IsSampled() {
+			traceID = sc.TraceID().String()
+		}
+		requestSize := r.ContentLength
+		if requestSize < 0 {
+			requestSize = body.n // Chunked body; count what the handler read
+		}
+		observe(HTTPRequest{
+			Route:        RouteTemplate(r),
+			Method:       r.Method,
+			StatusCode:   recorder.status,
+			Duration:     time.Since(start),
+			RequestSize:  requestSize,
+			ResponseSize: recorder.bytes,
+			TraceID:      traceID,
+		})
+	})
+}
+
+// Middleware records request count, status code, latency and request and response sizes for every
+// request, labeled by the mux route template (e.g. /api/v1/sellers/{id}). Register it with
+// mux.Router.Use so the matched route is known; handlers then need no metrics calls of their own.
+func (pm *promMetrics) Middleware(next http.Handler) http.Handler {
+	return InstrumentHandler(next, func(req HTTPRequest) {
+		code := strconv.Itoa(req.StatusCode)
+		pm.requests.WithLabelValues(req.Route, req.Method, code).Inc()
+		observeWithTrace(pm.httpDuration.WithLabelValues(req.Route, req.Method, code), req.Duration.Seconds(), req.TraceID)
+		pm.httpRequestSize.WithLabelValues(req.Route, req.Method).Observe(float64(req.RequestSize))
+		pm.httpResponseSize.WithLabelValues(req.Route, req.Method).Observe(float64(req.ResponseSize))
+	})
+}
+
+// observeWithTrace records v with the trace ID as an exemplar, so a slow bucket in Grafana links to
+// an example trace. Exemplars are only exposed in the OpenMetrics format.
+func observeWithTrace(observer prometheus.Observer, v float64, traceID string) {
+	if exemplar, ok := observer.(prometheus.ExemplarObserver); ok && traceID != "" {
+		exemplar.ObserveWithExemplar(v, prometheus.Labels{"trace_id": traceID})
+		return
+	}
+	observer.Observe(v)
+}
+
+// RouteTemplate returns the path template of the mux route that matched r, or UnmatchedRoute.
+func RouteTemplate(r *http.Request) string {
+	if route := mux.CurrentRoute(r); route != nil {
+		if template, err := route.GetPathTemplate(); err == nil {
+			return template
+		}
+	}
+	return UnmatchedRoute
+}
+
+// responseRecorder captures the status code and body size written by a handler.
+type responseRecorder struct {
+	http.ResponseWriter
+	status      int
+	bytes       int64
+	wroteHeader bool
+}
+
+func (rr *responseRecorder) WriteHeader(status int) {
+	if !rr.wroteHeader {
+		rr.status, rr.wroteHeader = status, true
+	}
+	rr.ResponseWriter.WriteHeader(status)
+}
+
+func (rr *responseRecorder) Write(b []byte) (int, error) {
+	rr.wroteHeader = true
+	n, err := rr.ResponseWriter.Write(b)
+	rr.bytes += int64(n)
+	return n, err
+}
+
+// Flush supports streaming handlers.
+func (rr *responseRecorder) Flush() {
+	if flusher, ok := rr.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer.
+func (rr *responseRecorder) Unwrap() http.ResponseWriter {
+	return rr.ResponseWriter
+}
+
+type countingReader struct {
+	io.ReadCloser
+	n int64
+}
+
+func (c *countingReader) Read(p []byte) (int, error) {
+	n, err := c.ReadCloser.Read(p)
+	c.n += int64(n)
+	return n, err
+}
@@ -0,0 +1,121 @@
+package metrics_test
+
+import (
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/libs/metrics/metricstest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+func newSellerRouter(mw mux.MiddlewareFunc) *mux.Router {
+	r := mux.NewRouter()
+	r.Use(mw)
+	r.HandleFunc("/sellers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, `{"id": "`+mux.Vars(r)["id"]+`"}`)
+	}).Methods(http.MethodGet)
+	r.HandleFunc("/sellers", func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+		w.WriteHeader(http.StatusCreated)
+		w.WriteHeader(http.StatusInternalServerError) // Superfluous; the first status was sent
+	}).Methods(http.MethodPost)
+	r.HandleFunc("/sellers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		io.WriteString(w, "deleted")
+		w.WriteHeader(http.StatusInternalServerError) // Too late, 200 was sent with the body
+	}).Methods(http.MethodDelete)
+	r.HandleFunc("/sellers/{id}/archive", func(w http.ResponseWriter, r *http.Request) {
+		http.Error(w, "Seller not found", http.StatusNotFound)
+	})
+	return r
+}
+
+func TestInstrumentHandler_RouteTemplateAndStatus(t *testing.T) {
+	recorder := metricstest.NewRecorder()
+	router := newSellerRouter(recorder.Middleware)
+
+	serve := func(method, path, body string) {
+		t.Helper()
+		router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(method, path, strings.NewReader(body)))
+	}
+	serve(http.MethodGet, "/sellers/1", "")
+	serve(http.MethodGet, "/sellers/2", "")
+	serve(http.MethodPost, "/sellers", `{"name": "Acme"}`)
+	serve(http.MethodDelete, "/sellers/3", "")
+	serve(http.MethodPut, "/sellers/4/archive", "")
+
+	if n := recorder.HTTPRequestCount("/sellers/{id}", http.MethodGet, http.StatusOK); n != 2 {
+		t.Errorf("GET /sellers/{id} 200 recorded %d times, want 2 under one route template; recorded %v", n, recorder.HTTPRequests())
+	}
+	recorder.AssertHTTPRequest(t, "/sellers", http.MethodPost, http.StatusCreated)
+	recorder.AssertHTTPRequest(t, "/sellers/{id}", http.MethodDelete, http.StatusOK)
+	recorder.AssertHTTPRequest(t, "/sellers/{id}/archive", http.MethodPut, http.StatusNotFound)
+
+	for _, req := range recorder.HTTPRequests() {
+		switch {
+		case req.Method == http.MethodGet && req.ResponseSize != int64(len(`{"id": "1"}`)):
+			t.Errorf("GET response size = %d", req.ResponseSize)
+		case req.Method == http.MethodPost && req.RequestSize != int64(len(`{"name": "Acme"}`)):
+			t.Errorf("POST request size = %d", req.RequestSize)
+		}
+	}
+}
+
+func TestInstrumentHandler_UnmatchedAndChunked(t *testing.T) {
+	var observed []metrics.HTTPRequest
+	handler := metrics.InstrumentHandler(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		io.ReadAll(r.Body)
+	}), func(req metrics.HTTPRequest) { observed = append(observed, req) })
+
+	req := httptest.NewRequest(http.MethodPost, "/not/routed", strings.NewReader("chunked body"))
+	req.ContentLength = -1
+	handler.ServeHTTP(httptest.NewRecorder(), req)
+
+	if len(observed) != 1 {
+		t.Fatalf("observed %d requests, want 1", len(observed))
+	}
+	if got := observed[0]; got.Route != metrics.UnmatchedRoute || got.StatusCode != http.StatusOK || got.RequestSize != int64(len("chunked body")) {
+		t.Errorf("observed %+v, want the unmatched route, status 200 and the bytes read", got)
+	}
+}
+
+func TestPrometheusMetrics_Middleware(t *testing.T) {
+	pm := metrics.NewPrometheusMetrics("test", "seller")
+	router := newSellerRouter(pm.Middleware)
+
+	span := trace.NewSpanContext(trace.SpanContextConfig{
+		TraceID:    trace.TraceID{0x4b, 0xf9, 0x2f, 0x35, 0x77, 0xb3, 0x4d, 0xa6, 0xa3, 0xce, 0x92, 0x9d, 0x0e, 0x0e, 0x47, 0x36},
+		SpanID:     trace.SpanID{0x00, 0xf0, 0x67, 0xaa, 0x0b, 0xa9, 0x02, 0xb7},
+		TraceFlags: trace.FlagsSampled,
+	})
+	for _, path := range []string{"/sellers/1", "/sellers/2"} {
+		req := httptest.NewRequest(http.MethodGet, path, nil)
+		router.ServeHTTP(httptest.NewRecorder(), req.WithContext(trace.ContextWithSpanContext(req.Context(), span)))
+	}
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodPut, "/sellers/1/archive", nil))
+
+	scrape := httptest.NewRecorder()
+	scrapeReq := httptest.NewRequest(http.MethodGet, "/metrics", nil)
+	scrapeReq.Header.Set("Accept", "application/openmetrics-text; version=1.0.0")
+	pm.Handler().ServeHTTP(scrape, scrapeReq)
+	out := scrape.Body.String()
+
+	for _, want := range []string{
+		`test_seller_http_requests_total{code="200",method="GET",route="/sellers/{id}"} 2`,
+		`test_seller_http_requests_total{code="404",method="PUT",route="/sellers/{id}/archive"} 1`,
+		`test_seller_http_request_duration_seconds_count{code="200",method="GET",route="/sellers/{id}"} 2`,
+		`test_seller_http_response_size_bytes_count{method="GET",route="/sellers/{id}"} 2`,
+		`# {trace_id="4bf92f3577b34da6a3ce929d0e0e4736"}`,
+	} {
+		if !strings.Contains(out, want) {
+			t.Errorf("scrape is missing %s", want)
+		}
+	}
+	if strings.Contains(out, `route="/sellers/1"`) {
+		t.Error("raw paths must not be used as labels")
+	}
+}
@@ -0,0 +1,97 @@
+package tracing
+
+import (
+	"context"
+
+	"github.com/graphql-go/graphql"
+	"github.com/graphql-go/graphql/gqlerrors"
+	"github.com/graphql-go/graphql/language/ast"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/codes"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// GraphQLExtension returns a graphql-go extension that records spans for parsing, validation and
+// execution of each operation and for every top-level field resolver. Add it with
+// Schema.AddExtensions; resolvers then run inside the execution span.
+func GraphQLExtension() graphql.Extension {
+	return graphQLExtension{}
+}
+
+type graphQLExtension struct{}
+
+type operationNameKey struct{}
+
+// executionKey holds the context of the execution span. graphql-go passes the context returned by
+// each hook on to the next, so spans are started from it to stay siblings rather than nest.
+type executionKey struct{}
+
+func (graphQLExtension) Name() string { return "tracing" }
+
+func (graphQLExtension) Init(ctx context.Context, p *graphql.Params) context.Context {
+	if ctx == nil {
+		ctx = context.Background() // graphql.Do allows Params without a context
+	}
+	return context.WithValue(ctx, operationNameKey{}, p.OperationName)
+}
+
+func (graphQLExtension) ParseDidStart(ctx context.Context) (context.Context, graphql.ParseFinishFunc) {
+	_, span := Start(ctx, "graphql.parse")
+	return ctx, func(err error) {
+		endSpan(span, err)
+	}
+}
+
+func (graphQLExtension) ValidationDidStart(ctx context.Context) (context.Context, graphql.ValidationFinishFunc) {
+	_, span := Start(ctx, "graphql.validate")
+	return ctx, func(errs []gqlerrors.FormattedError) {
+		if len(errs) > 0 {
+			span.SetStatus(codes.Error, errs[0].Message)
+		}
+		span.End()
+	}
+}
+
+func (graphQLExtension) ExecutionDidStart(ctx context.Context) (context.Context, graphql.ExecutionFinishFunc) {
+	name, _ := ctx.Value(operationNameKey{}).(string)
+	ctx, span := Start(ctx, "graphql.execute", attribute.String("graphql.operation.name", name))
+	ctx = context.WithValue(ctx, executionKey{}, ctx)
+	return ctx, func(result *graphql.Result) {
+		if result != nil && result.HasErrors() {
+			span.SetAttributes(attribute.Int("graphql.errors", len(result.Errors)))
+			span.SetStatus(codes.Error, result.Errors[0].Message)
+		}
+		span.End()
+	}
+}
+
+// ResolveFieldDidStart traces top-level fields only; nested fields are mostly plain struct reads and
+// run inside their top-level field's span.
+func (graphQLExtension) ResolveFieldDidStart(ctx context.Context, info *graphql.ResolveInfo) (context.Context, graphql.ResolveFieldFinishFunc) {
+	if info.Path != nil && info.Path.Prev != nil {
+		return ctx, func(interface{}, error) {}
+	}
+	attrs := []attribute.KeyValue{attribute.String("graphql.field.name", info.FieldName)}
+	if op, ok := info.Operation.(*ast.OperationDefinition); ok {
+		attrs = append(attrs, attribute.String("graphql.operation.type", op.Operation))
+	}
+	if parent, ok := ctx.Value(executionKey{}).(context.Context); ok {
+		ctx = parent
+	}
+	ctx, span := Start(ctx, "graphql.resolve "+info.ParentType.Name()+"."+info.FieldName, attrs...)
+	return ctx, func(_ interface{}, err error) {
+		endSpan(span, err)
+	}
+}
+
+func (graphQLExtension) HasResult() bool { return false }
+
+func (graphQLExtension) GetResult(context.Context) interface{} { return nil }
+
+func endSpan(span trace.Span, err error) {
+	if err != nil {
+		span.RecordError(err)
+		span.SetStatus(codes.Error, err.Error())
+	}
+	span.End()
+}
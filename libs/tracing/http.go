@@ -0,0 +1,45 @@
+package tracing
+
+import (
+	"net/http"
+
+	"github.com/gorilla/mux"
+	"go.opentelemetry.io/contrib/instrumentation/github.com/gorilla/mux/otelmux"
+	"go.opentelemetry.io/contrib/instrumentation/net/http/otelhttp"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+)
+
+// untracedPaths are scraped or probed constantly and would drown out real traffic.
+var untracedPaths = map[string]bool{"/metrics": true, "/health": true, "/ready": true}
+
+// Middleware starts a server span for every request routed by a gorilla/mux router, named after the
+// method and route template (e.g. "GET /api/v1/sellers/{id}") and continuing the caller's trace.
+// Register it before logger.RequestMiddleware and the metrics middleware, so log lines and
+// histogram exemplars carry the span's trace ID.
+func Middleware(serviceName string) mux.MiddlewareFunc {
+	traced := otelmux.Middleware(serviceName,
+		otelmux.WithSpanNameFormatter(func(route string, r *http.Request) string {
+			return r.Method + " " + route
+		}),
+		otelmux.WithFilter(func(r *http.Request) bool {
+			return !untracedPaths[r.URL.Path]
+		}),
+	)
+	return func(next http.Handler) http.Handler {
+		return traced(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			// logger.RequestMiddleware reads the trace from the traceparent header
+			otel.GetTextMapPropagator().Inject(r.Context(), propagation.HeaderCarrier(r.Header))
+			next.ServeHTTP(w, r)
+		}))
+	}
+}
+
+// Transport returns an http.RoundTripper that records a client span for every outgoing request and
+// sends the trace context to the called service. A nil base means http.DefaultTransport.
+func Transport(base http.RoundTripper) http.RoundTripper {
+	if base == nil {
+		base = http.DefaultTransport
+	}
+	return otelhttp.NewTransport(base)
+}
@@ -0,0 +1,124 @@
+// Package tracing sets up OpenTelemetry tracing for a service and instruments the shared building
+// blocks (mux routers, outgoing HTTP, GraphQL), so the spans of one request connect across handlers,
+// services, repositories and other services.
+package tracing
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/attribute"
+	"go.opentelemetry.io/otel/exporters/otlp/otlptrace/otlptracehttp"
+	"go.opentelemetry.io/otel/exporters/stdout/stdouttrace"
+	"go.opentelemetry.io/otel/propagation"
+	"go.opentelemetry.io/otel/sdk/resource"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	semconv "go.opentelemetry.io/otel/semconv/v1.26.0"
+	"go.opentelemetry.io/otel/trace"
+)
+
+const instrumentationName = "github.com/omni-compos/digital-mono/libs/tracing"
+
+// Exporters supported by Setup.
+const (
+	ExporterNone   = "none"   // Propagate trace context but record nothing
+	ExporterOTLP   = "otlp"   // OTLP over HTTP; the endpoint comes from OTEL_EXPORTER_OTLP_ENDPOINT
+	ExporterStdout = "stdout" // Pretty-printed JSON on stdout, for local debugging
+	ExporterFile   = "file"   // JSON spans appended to Config.FilePath
+)
+
+// Config configures Setup.
+type Config struct {
+	ServiceName    string
+	ServiceVersion string
+	Exporter       string  // One of the Exporter constants; defaults to ExporterNone
+	FilePath       string  // Used by ExporterFile
+	SampleRatio    float64 // Fraction of new traces recorded; callers' sampling decisions are always kept
+}
+
+// ConfigFromEnv reads OTEL_TRACES_EXPORTER (otlp|stdout|file|none), OTEL_TRACES_FILE,
+// OTEL_TRACES_SAMPLER_ARG and SERVICE_VERSION. The exporter defaults to otlp when
+// OTEL_EXPORTER_OTLP_ENDPOINT is set and to none otherwise.
+func ConfigFromEnv(serviceName string) Config {
+	cfg := Config{
+		ServiceName:    serviceName,
+		ServiceVersion: os.Getenv("SERVICE_VERSION"),
+		Exporter:       strings.ToLower(os.Getenv("OTEL_TRACES_EXPORTER")),
+		FilePath:       os.Getenv("OTEL_TRACES_FILE"),
+		SampleRatio:    1,
+	}
+	if cfg.Exporter == "" {
+		cfg.Exporter = ExporterNone
+		if os.Getenv("OTEL_EXPORTER_OTLP_ENDPOINT") != "" || os.Getenv("OTEL_EXPORTER_OTLP_TRACES_ENDPOINT") != "" {
+			cfg.Exporter = ExporterOTLP
+		}
+	}
+	if ratio, err := strconv.ParseFloat(os.Getenv("OTEL_TRACES_SAMPLER_ARG"), 64); err == nil && ratio >= 0 && ratio <= 1 {
+		cfg.SampleRatio = ratio
+	}
+	return cfg
+}
+
+// Setup installs the global tracer provider and W3C trace-context propagator. Call the returned
+// function on shutdown to flush buffered spans.
+func Setup(ctx context.Context, cfg Config) (shutdown func(context.Context) error, err error) {
+	otel.SetTextMapPropagator(propagation.NewCompositeTextMapPropagator(propagation.TraceContext{}, propagation.Baggage{}))
+
+	var exporter sdktrace.SpanExporter
+	closeExporter := func() error { return nil }
+	switch cfg.Exporter {
+	case "", ExporterNone:
+		return func(context.Context) error { return nil }, nil
+	case ExporterOTLP:
+		exporter, err = otlptracehttp.New(ctx)
+	case ExporterStdout:
+		exporter, err = stdouttrace.New(stdouttrace.WithPrettyPrint())
+	case ExporterFile:
+		if cfg.FilePath == "" {
+			return nil, fmt.Errorf("trace file exporter requires a file path")
+		}
+		var file *os.File
+		if file, err = os.OpenFile(cfg.FilePath, os.O_CREATE|os.O_APPEND|os.O_WRONLY, 0o644); err != nil {
+			return nil, fmt.Errorf("failed to open trace file: %w", err)
+		}
+		closeExporter = file.Close
+		exporter, err = stdouttrace.New(stdouttrace.WithWriter(file))
+	default:
+		return nil, fmt.Errorf("unknown trace exporter %q", cfg.Exporter)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to create %s trace exporter: %w", cfg.Exporter, err)
+	}
+
+	attrs := []attribute.KeyValue{semconv.ServiceName(cfg.ServiceName)}
+	if cfg.ServiceVersion != "" {
+		attrs = append(attrs, semconv.ServiceVersion(cfg.ServiceVersion))
+	}
+	res, err := resource.New(ctx, resource.WithFromEnv(), resource.WithTelemetrySDK(), resource.WithHost(), resource.WithAttributes(attrs...))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create trace resource: %w", err)
+	}
+
+	provider := sdktrace.NewTracerProvider(
+		sdktrace.WithBatcher(exporter),
+		sdktrace.WithResource(res),
+		sdktrace.WithSampler(sdktrace.ParentBased(sdktrace.TraceIDRatioBased(cfg.SampleRatio))),
+	)
+	otel.SetTracerProvider(provider)
+	return func(ctx context.Context) error {
+		if err := provider.Shutdown(ctx); err != nil {
+			return fmt.Errorf("failed to shut down tracer provider: %w", err)
+		}
+		return closeExporter()
+	}, nil
+}
+
+// Start starts a span named name as a child of the span in ctx; the caller must end it.
+// Services use it to mark their own operations, e.g. "SellerService.CreateSeller".
+func Start(ctx context.Context, name string, attrs ...attribute.KeyValue) (context.Context, trace.Span) {
+	return otel.Tracer(instrumentationName).Start(ctx, name, trace.WithAttributes(attrs...))
+}
@@ -0,0 +1,159 @@
+package tracing_test
+
+import (
+	"context"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	"github.com/omni-compos/digital-mono/libs/tracing"
+	"go.opentelemetry.io/otel"
+	"go.opentelemetry.io/otel/propagation"
+	sdktrace "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+	"go.opentelemetry.io/otel/trace"
+)
+
+// recordSpans installs a tracer provider exporting synchronously to memory, as Setup would with a
+// real exporter, and restores the global provider and propagator when the test ends.
+func recordSpans(t *testing.T) *tracetest.InMemoryExporter {
+	t.Helper()
+	exporter := tracetest.NewInMemoryExporter()
+	provider := sdktrace.NewTracerProvider(sdktrace.WithSyncer(exporter))
+	previousProvider, previousPropagator := otel.GetTracerProvider(), otel.GetTextMapPropagator()
+	otel.SetTracerProvider(provider)
+	otel.SetTextMapPropagator(propagation.TraceContext{})
+	t.Cleanup(func() {
+		provider.Shutdown(context.Background())
+		otel.SetTracerProvider(previousProvider)
+		otel.SetTextMapPropagator(previousPropagator)
+	})
+	return exporter
+}
+
+func spansByName(t *testing.T, exporter *tracetest.InMemoryExporter) map[string]tracetest.SpanStub {
+	t.Helper()
+	spans := map[string]tracetest.SpanStub{}
+	for _, span := range exporter.GetSpans() {
+		if _, ok := spans[span.Name]; ok {
+			t.Fatalf("span %q recorded twice", span.Name)
+		}
+		spans[span.Name] = span
+	}
+	return spans
+}
+
+func TestTracing_ConnectsHandlerServiceRepositoryAndDownstream(t *testing.T) {
+	exporter := recordSpans(t)
+
+	// The downstream service, e.g. the product service called by the seller service.
+	var downstreamTraceparent string
+	downstreamRouter := mux.NewRouter()
+	downstreamRouter.Use(tracing.Middleware("product-service"))
+	downstreamRouter.HandleFunc("/products/{id}", func(w http.ResponseWriter, r *http.Request) {
+		downstreamTraceparent = r.Header.Get("traceparent")
+	})
+	downstream := httptest.NewServer(downstreamRouter)
+	defer downstream.Close()
+	client := &http.Client{Transport: tracing.Transport(nil)}
+
+	// The seller service: handler -> service -> repository, and a call to the downstream service.
+	var handlerTraceparent string
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware("seller-service"))
+	router.HandleFunc("/sellers/{id}", func(w http.ResponseWriter, r *http.Request) {
+		handlerTraceparent = r.Header.Get("traceparent")
+		ctx, serviceSpan := tracing.Start(r.Context(), "SellerService.GetSellerByID")
+		defer serviceSpan.End()
+
+		_, repositorySpan := tracing.Start(ctx, "SellerRepository.GetByID")
+		repositorySpan.End()
+
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, downstream.URL+"/products/1", nil)
+		if err != nil {
+			t.Fatal(err)
+		}
+		resp, err := client.Do(req)
+		if err != nil {
+			t.Fatal(err)
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+	})
+
+	const callerTraceID, callerSpanID = "4bf92f3577b34da6a3ce929d0e0e4736", "00f067aa0ba902b7"
+	req := httptest.NewRequest(http.MethodGet, "/sellers/42", nil)
+	req.Header.Set("traceparent", "00-"+callerTraceID+"-"+callerSpanID+"-01")
+	router.ServeHTTP(httptest.NewRecorder(), req)
+
+	spans := spansByName(t, exporter)
+	server, service, repository := spans["GET /sellers/{id}"], spans["SellerService.GetSellerByID"], spans["SellerRepository.GetByID"]
+	clientSpan, downstreamServer := spans["HTTP GET"], spans["GET /products/{id}"]
+	if len(spans) != 5 {
+		names := make([]string, 0, len(spans))
+		for name := range spans {
+			names = append(names, name)
+		}
+		t.Fatalf("recorded spans %v, want the server, service, repository, client and downstream server spans", names)
+	}
+
+	parents := []struct {
+		child, parent string
+		childSpan     tracetest.SpanStub
+		parentSpanID  trace.SpanID
+	}{
+		{"service", "server", service, server.SpanContext.SpanID()},
+		{"repository", "service", repository, service.SpanContext.SpanID()},
+		{"client", "service", clientSpan, service.SpanContext.SpanID()},
+		{"downstream server", "client", downstreamServer, clientSpan.SpanContext.SpanID()},
+	}
+	for _, p := range parents {
+		if got := p.childSpan.Parent.SpanID(); got != p.parentSpanID {
+			t.Errorf("%s span's parent = %s, want the %s span %s", p.child, got, p.parent, p.parentSpanID)
+		}
+	}
+	if !server.Parent.IsRemote() || server.Parent.SpanID().String() != callerSpanID {
+		t.Errorf("server span's parent = %s, want the caller's remote span %s", server.Parent.SpanID(), callerSpanID)
+	}
+	for name, span := range spans {
+		if span.SpanContext.TraceID().String() != callerTraceID {
+			t.Errorf("span %q is in trace %s, want the caller's trace %s", name, span.SpanContext.TraceID(), callerTraceID)
+		}
+	}
+	if server.SpanKind != trace.SpanKindServer || clientSpan.SpanKind != trace.SpanKindClient {
+		t.Errorf("span kinds = %s and %s, want server and client", server.SpanKind, clientSpan.SpanKind)
+	}
+
+	// Transport sent the client span to the downstream service (checked by the parenting above);
+	// each Middleware then puts its own server span into the traceparent header for logger.RequestMiddleware.
+	if want := "00-" + callerTraceID + "-" + server.SpanContext.SpanID().String() + "-01"; handlerTraceparent != want {
+		t.Errorf("handler saw traceparent %s, want %s", handlerTraceparent, want)
+	}
+	if want := "00-" + callerTraceID + "-" + downstreamServer.SpanContext.SpanID().String() + "-01"; downstreamTraceparent != want {
+		t.Errorf("downstream handler saw traceparent %s, want %s", downstreamTraceparent, want)
+	}
+}
+
+func TestMiddleware_SkipsProbesAndStartsNewTraces(t *testing.T) {
+	exporter := recordSpans(t)
+	router := mux.NewRouter()
+	router.Use(tracing.Middleware("seller-service"))
+	router.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {})
+	router.HandleFunc("/sellers", func(w http.ResponseWriter, r *http.Request) {})
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/health", nil))
+	if spans := exporter.GetSpans(); len(spans) != 0 {
+		t.Errorf("recorded %d spans for /health, want none", len(spans))
+	}
+
+	router.ServeHTTP(httptest.NewRecorder(), httptest.NewRequest(http.MethodGet, "/sellers", nil))
+	spans := exporter.GetSpans()
+	if len(spans) != 1 || spans[0].Name != "GET /sellers" {
+		t.Fatalf("recorded %v, want one GET /sellers span", spans)
+	}
+	if spans[0].Parent.IsValid() {
+		t.Errorf("span without an incoming traceparent has parent %s, want a new trace", spans[0].Parent.SpanID())
+	}
+}
@@ -1,60 +1,97 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/graphql-go/handler"
-	_ "github.com/lib/pq" // PostgreSQL driver
 	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 
 	commonDB "github.com/omni-compos/digital-mono/libs/database"
 	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
 	commonMetrics "github.com/omni-compos/digital-mono/libs/metrics"
+	commonTracing "github.com/omni-compos/digital-mono/libs/tracing"
 
 	productGraphQL "github.com/omni-compos/digital-mono/services/product/internal/handler/graphql"
 	productREST "github.com/omni-compos/digital-mono/services/product/internal/handler/rest"
-	productRepo "github.com/omni-compos/digital-mono/services/product/internal/repository"
 	productService "github.com/omni-compos/digital-mono/services/product/internal/service"
 )
 
 func main() {
 	// Configuration
-	dbDSN := os.Getenv("DB_DSN")
-	if dbDSN == "" {
-		dbDSN = "host=localhost port=5432 user=omni_user password=strong_password dbname=digital_mono_db sslmode=disable"
-		log.Println("Warning: DB_DSN not set, using default for product service.")
-	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-super-secret-key-for-user-service"
-		log.Println("Warning: JWT_SECRET not set, using default for product service.")
+	// Tokens are issued by the user service; verify them against its published signing keys
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = "http://localhost:8081/.well-known/jwks.json"
+		log.Println("Warning: JWKS_URL not set, using default for product service.")
 	}
 
 	// Initialize common libraries
-	appLogger := commonLogger.NewStdLogger()
+	// Structured logs; LOG_FORMAT (json|text), LOG_LEVEL and SERVICE_VERSION configure the output
+	logConfig := commonLogger.ConfigFromEnv("product-service")
+	appLogger, err := commonLogger.NewSlogLogger(logConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	commonLogger.SetDefault(appLogger)
 	appLogger.Info("Starting product service...")
 
-	db, err := commonDB.NewPostgresDB(dbDSN)
+	// Traces; OTEL_TRACES_EXPORTER (otlp|stdout|file|none) and OTEL_EXPORTER_OTLP_ENDPOINT configure the export
+	shutdownTracing, err := commonTracing.Setup(context.Background(), commonTracing.ConfigFromEnv("product-service"))
 	if err != nil {
-		appLogger.Error(err, "Failed to connect to database")
-		log.Fatalf("Failed to connect to database: %v", err)
+		appLogger.Error(err, "Failed to initialize tracing")
+		log.Fatalf("Failed to initialize tracing: %v", err)
 	}
-	defer db.Close()
-	appLogger.Info("Successfully connected to database")
+	defer shutdownTracing(context.Background())
+	// Outgoing calls (e.g. JWKS and OIDC fetches) carry the trace and request ID
+	http.DefaultTransport = commonTracing.Transport(commonLogger.Transport(http.DefaultTransport))
 
 	promMetrics := commonMetrics.NewPrometheusMetrics("product_service", "api")
-	authenticator := commonAuth.NewJWTAuthenticator(jwtSecret)
+	// Connection pool stats and per-query latency/errors, served on /metrics
+	queryMetrics := commonDB.NewQueryMetrics("product_service")
+	// Domain KPIs (e.g. products created), served on /metrics under product_service_business_*
+	kpis := commonMetrics.NewBusinessMetrics("product_service")
+	if err := promMetrics.Register(queryMetrics, kpis); err != nil {
+		appLogger.Error(err, "Failed to register query and business metrics")
+		log.Fatalf("Failed to register query and business metrics: %v", err)
+	}
+
+	// Postgres by default; STORAGE=memory runs without a database
+	store, err := openStorage(context.Background(), appLogger, queryMetrics)
+	if err != nil {
+		appLogger.Error(err, "Failed to set up storage")
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+	defer store.close()
+	if store.db != nil {
+		if err := promMetrics.Register(commonDB.NewDBStatsCollector(store.db, "products")); err != nil {
+			appLogger.Error(err, "Failed to register database metrics")
+			log.Fatalf("Failed to register database metrics: %v", err)
+		}
+	}
+	// Service tokens must be addressed to this service
+	authenticator := commonAuth.NewJWKSAuthenticator(jwksURL, 5*time.Minute).
+		WithRevocationStore(store.revocations).
+		WithAPIKeyStore(store.apiKeys).
+		WithAudience("product-service")
+
+	// Role to permission mapping; AUTHZ_POLICY_FILE overrides the policy bundled with libs/auth
+	policy, err := commonAuth.LoadPolicy(os.Getenv("AUTHZ_POLICY_FILE"))
+	if err != nil {
+		appLogger.Error(err, "Failed to load authorization policy")
+		log.Fatalf("Failed to load authorization policy: %v", err)
+	}
 
 	// Dependency Injection
-	repo := productRepo.NewPGProductRepository(db)
-	service := productService.NewProductService(repo, appLogger)
+	service := productService.NewProductService(store.repo, appLogger, kpis, store.tx, store.outbox)
 
-	restHandler := productREST.NewProductRESTHandler(service, appLogger, promMetrics)
-	gqlHandler, err := productGraphQL.NewProductGraphQLHandler(service, appLogger)
+	restHandler := productREST.NewProductRESTHandler(service, appLogger, policy)
+	gqlHandler, err := productGraphQL.NewProductGraphQLHandler(service, appLogger, policy)
 	if err != nil {
 		appLogger.Error(err, "Failed to create GraphQL handler")
 		log.Fatalf("Failed to create GraphQL handler: %v", err)
@@ -62,19 +99,33 @@ func main() {
 
 	// Router
 	r := mux.NewRouter()
+	r.Use(commonTracing.Middleware("product-service")) // Server spans; first, so logs and metrics see the trace
+	r.Use(commonLogger.RequestMiddleware(appLogger))   // Request-scoped logger with X-Request-ID/traceparent
+	r.Use(promMetrics.Middleware)                      // RED metrics labeled by route template
 
 	apiRouter := r.PathPrefix("/api/v1").Subrouter()
 	apiRouter.Use(authenticator.Middleware)
 	restHandler.RegisterRoutes(apiRouter)
 
+	// Spans for parse, validation, execution and resolvers; operation, resolver and error metrics
+	gqlHandler.Schema.AddExtensions(commonTracing.GraphQLExtension(), promMetrics.GraphQLExtension())
 	graphqlHTTPHandler := handler.New(&handler.Config{
 		Schema:   &gqlHandler.Schema,
 		Pretty:   true,
 		GraphiQL: true,
 	})
-	r.Handle("/graphql", graphqlHTTPHandler)
+	r.Handle("/graphql", authenticator.Middleware(graphqlHTTPHandler)) // Resolvers check permissions from the JWT claims
+	// Runtime log levels, e.g. PUT {"level":"debug","packages":{"repository":"debug"}}
+	r.Handle("/admin/loglevel", authenticator.Middleware(policy.RequirePermission(commonAuth.PermLoggingAdmin)(commonLogger.LevelHandler(logConfig.Levels)))).Methods(http.MethodGet, http.MethodPut)
 	r.Handle("/metrics", promMetrics.Handler())
 
+	// Liveness and readiness; /ready answers 503 while the database is unreachable, always OK with in-memory storage
+	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}).Methods("GET")
+	r.Handle("/ready", store.ready).Methods("GET")
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8082" // Default port for product service
@@ -1,6 +1,12 @@
 package domain
 
-import "time"
+import (
+	"errors"
+	"time"
+)
+
+// ErrInvalidProduct is returned when an import holds a product that fails validation.
+var ErrInvalidProduct = errors.New("invalid product")
 
 // Product represents a product in the system.
 type Product struct {
@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"github.com/graphql-go/graphql"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	"github.com/omni-compos/digital-mono/libs/logger"
 	"github.com/omni-compos/digital-mono/services/product/internal/service"
 )
@@ -30,7 +31,7 @@ type ProductGraphQLHandler struct {
 }
 
 // NewProductGraphQLHandler creates a new GraphQL handler for products.
-func NewProductGraphQLHandler(productService service.ProductService, log logger.Logger) (*ProductGraphQLHandler, error) {
+func NewProductGraphQLHandler(productService service.ProductService, log logger.Logger, policy *commonAuth.Policy) (*ProductGraphQLHandler, error) {
 	rootQuery := graphql.NewObject(graphql.ObjectConfig{
 		Name: "RootQuery",
 		Fields: graphql.Fields{
@@ -39,13 +40,13 @@ func NewProductGraphQLHandler(productService service.ProductService, log logger.
 				Args: graphql.FieldConfigArgument{
 					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermProductsRead, func(p graphql.ResolveParams) (interface{}, error) {
 					id, ok := p.Args["id"].(string)
 					if !ok {
 						return nil, nil
 					}
 					return productService.GetProduct(p.Context, id)
-				},
+				}),
 			},
 		},
 	})
@@ -60,7 +61,7 @@ func NewProductGraphQLHandler(productService service.ProductService, log logger.
 					"description": &graphql.ArgumentConfig{Type: graphql.String}, // Optional
 					"sku":         &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermProductsWrite, func(p graphql.ResolveParams) (interface{}, error) {
 					name := p.Args["name"].(string)
 					sku := p.Args["sku"].(string)
 					description := ""
@@ -73,7 +74,7 @@ func NewProductGraphQLHandler(productService service.ProductService, log logger.
 						return nil, err
 					}
 					return product, nil
-				},
+				}),
 			},
 		},
 	})
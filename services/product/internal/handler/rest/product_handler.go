@@ -2,12 +2,13 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
 
 	"github.com/gorilla/mux"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	"github.com/omni-compos/digital-mono/libs/logger"
-	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/services/product/internal/domain"
 	"github.com/omni-compos/digital-mono/services/product/internal/service"
 )
 
@@ -15,18 +16,22 @@ import (
 type ProductRESTHandler struct {
 	service service.ProductService
 	logger  logger.Logger
-	metrics metrics.PrometheusMetrics
+	policy  *commonAuth.Policy
 }
 
 // NewProductRESTHandler creates a new ProductRESTHandler.
-func NewProductRESTHandler(productService service.ProductService, log logger.Logger, promMetrics metrics.PrometheusMetrics) *ProductRESTHandler {
-	return &ProductRESTHandler{service: productService, logger: log, metrics: promMetrics}
+func NewProductRESTHandler(productService service.ProductService, log logger.Logger, policy *commonAuth.Policy) *ProductRESTHandler {
+	return &ProductRESTHandler{service: productService, logger: log, policy: policy}
 }
 
 // RegisterRoutes registers product REST routes.
 func (h *ProductRESTHandler) RegisterRoutes(router *mux.Router) {
-	router.HandleFunc("/products", h.CreateProductHandler).Methods(http.MethodPost)
-	router.HandleFunc("/products/{id}", h.GetProductHandler).Methods(http.MethodGet)
+	read := h.policy.RequirePermission(commonAuth.PermProductsRead)
+	write := h.policy.RequirePermission(commonAuth.PermProductsWrite)
+
+	router.Handle("/products", write(http.HandlerFunc(h.CreateProductHandler))).Methods(http.MethodPost)
+	router.Handle("/products/import", write(http.HandlerFunc(h.ImportProductsHandler))).Methods(http.MethodPost)
+	router.Handle("/products/{id}", read(http.HandlerFunc(h.GetProductHandler))).Methods(http.MethodGet)
 }
 
 type CreateProductRequest struct {
@@ -36,46 +41,69 @@ type CreateProductRequest struct {
 }
 
 func (h *ProductRESTHandler) CreateProductHandler(w http.ResponseWriter, r *http.Request) {
-	h.metrics.IncRequestsTotal("create-product",  "rest")
 	var req CreateProductRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		h.metrics.IncResponsesTotal("create-product",  "rest",  strconv.Itoa(http.StatusBadRequest))
 		return
 	}
 
 	product, err := h.service.CreateProduct(r.Context(), req.Name, req.Description, req.SKU)
 	if err != nil {
-		h.logger.Error(err, "Failed to create product")
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to create product")
 		http.Error(w, "Failed to create product", http.StatusInternalServerError)
-		h.metrics.IncResponsesTotal(r.URL.Path,  "rest",strconv.Itoa(http.StatusInternalServerError))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(product)
-	h.metrics.IncResponsesTotal(r.URL.Path,  "rest",  strconv.Itoa(http.StatusCreated))
+}
+
+// maxImportBody bounds the body of an import, enough for service.MaxImportSize products.
+const maxImportBody = 32 << 20
+
+// ImportProductsHandler creates the products in the JSON array body, each a CreateProductRequest, all or none.
+func (h *ProductRESTHandler) ImportProductsHandler(w http.ResponseWriter, r *http.Request) {
+	var reqs []CreateProductRequest
+	if err := json.NewDecoder(http.MaxBytesReader(w, r.Body, maxImportBody)).Decode(&reqs); err != nil {
+		http.Error(w, "Invalid request body", http.StatusBadRequest)
+		return
+	}
+	products := make([]*domain.Product, len(reqs))
+	for i, req := range reqs {
+		products[i] = &domain.Product{Name: req.Name, Description: req.Description, SKU: req.SKU}
+	}
+
+	imported, err := h.service.ImportProducts(r.Context(), products)
+	if err != nil {
+		if errors.Is(err, domain.ErrInvalidProduct) {
+			http.Error(w, err.Error(), http.StatusBadRequest)
+			return
+		}
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to import products")
+		http.Error(w, "Failed to import products", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(imported)
 }
 
 func (h *ProductRESTHandler) GetProductHandler(w http.ResponseWriter, r *http.Request) {
-	h.metrics.IncRequestsTotal("get-product",  "rest")
 	vars := mux.Vars(r)
 	id := vars["id"]
 
 	product, err := h.service.GetProduct(r.Context(), id)
 	if err != nil {
 		http.Error(w, "Failed to get product", http.StatusInternalServerError)
-		h.metrics.IncResponsesTotal(r.URL.Path, "rest",  strconv.Itoa(http.StatusInternalServerError))
 		return
 	}
 	if product == nil {
 		http.Error(w, "Product not found", http.StatusNotFound)
-		h.metrics.IncResponsesTotal(r.URL.Path, "rest", strconv.Itoa(http.StatusNotFound))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(product)
-	h.metrics.IncResponsesTotal(r.URL.Path,  "rest",  strconv.Itoa(http.StatusOK))
 }
\ No newline at end of file
@@ -0,0 +1,11 @@
+-- Baseline: databases bootstrapped from deployments/docker-compose/postgres/initdb already have the
+-- table, so IF NOT EXISTS adopts it. There is no down migration; reverting the baseline
+-- would drop data the migrations never created.
+CREATE TABLE IF NOT EXISTS products (
+    id VARCHAR(36) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    description TEXT,
+    sku VARCHAR(100) UNIQUE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    updated_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
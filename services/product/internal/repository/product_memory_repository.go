@@ -0,0 +1,69 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/omni-compos/digital-mono/services/product/internal/domain"
+)
+
+type memoryProductRepository struct {
+	mu       sync.RWMutex
+	products map[string]*domain.Product
+	skus     map[string]string // SKU to product ID, enforcing the unique sku column
+}
+
+// NewMemoryProductRepository creates an in-memory product repository for local development and tests.
+// Products are stored as copies and lost on restart.
+func NewMemoryProductRepository() ProductRepository {
+	return &memoryProductRepository{products: map[string]*domain.Product{}, skus: map[string]string{}}
+}
+
+func (r *memoryProductRepository) CreateProduct(ctx context.Context, product *domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.products[product.ID]; exists {
+		return fmt.Errorf("duplicate product ID %s", product.ID)
+	}
+	if _, exists := r.skus[product.SKU]; exists {
+		return fmt.Errorf("duplicate product SKU %s", product.SKU)
+	}
+	stored := *product
+	r.products[product.ID] = &stored
+	r.skus[product.SKU] = product.ID
+	return nil
+}
+
+func (r *memoryProductRepository) CreateProducts(ctx context.Context, products []*domain.Product) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make(map[string]bool, len(products))
+	skus := make(map[string]bool, len(products))
+	for _, product := range products {
+		if _, exists := r.products[product.ID]; exists || ids[product.ID] {
+			return fmt.Errorf("duplicate product ID %s", product.ID)
+		}
+		if _, exists := r.skus[product.SKU]; exists || skus[product.SKU] {
+			return fmt.Errorf("duplicate product SKU %s", product.SKU)
+		}
+		ids[product.ID], skus[product.SKU] = true, true
+	}
+	for _, product := range products {
+		stored := *product
+		r.products[product.ID] = &stored
+		r.skus[product.SKU] = product.ID
+	}
+	return nil
+}
+
+func (r *memoryProductRepository) GetProductByID(ctx context.Context, id string) (*domain.Product, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	product, ok := r.products[id]
+	if !ok {
+		return nil, nil
+	}
+	found := *product
+	return &found, nil
+}
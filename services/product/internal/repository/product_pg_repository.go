@@ -3,38 +3,77 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"fmt"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/omni-compos/digital-mono/libs/database"
 	"github.com/omni-compos/digital-mono/services/product/internal/domain"
 )
 
 // ProductRepository defines the interface for product data operations.
+// repositorytest.RunProductRepositoryTests checks that an implementation keeps this contract.
 type ProductRepository interface {
+	// CreateProduct fails if the ID or SKU is taken.
 	CreateProduct(ctx context.Context, product *domain.Product) error
+	// CreateProducts creates all of products or, if any of them cannot be created, none. It is meant for imports.
+	CreateProducts(ctx context.Context, products []*domain.Product) error
+	// GetProductByID returns nil and no error if the product does not exist.
 	GetProductByID(ctx context.Context, id string) (*domain.Product, error)
 }
 
 type pgProductRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	queries *database.QueryMetrics
 }
 
-// NewPGProductRepository creates a new PostgreSQL product repository.
-func NewPGProductRepository(db *sql.DB) ProductRepository {
-	return &pgProductRepository{db: db}
+// NewPGProductRepository creates a new PostgreSQL product repository on pgx; db must come from
+// database.NewPgxDB. queries may be nil.
+func NewPGProductRepository(db *sql.DB, queries *database.QueryMetrics) ProductRepository {
+	return &pgProductRepository{db: db, queries: queries}
 }
 
 func (r *pgProductRepository) CreateProduct(ctx context.Context, product *domain.Product) error {
 	query := `INSERT INTO products (id, name, description, sku, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
-	_, err := r.db.ExecContext(ctx, query, product.ID, product.Name, product.Description, product.SKU, product.CreatedAt, product.UpdatedAt)
+	start := time.Now()
+	err := database.WithPgx(ctx, r.db, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, query, productValues(product)...)
+		return err
+	})
+	r.queries.Observe("products.create", start, err)
 	return err
 }
 
+// CreateProducts inserts products with COPY, which is much faster than an INSERT per product for large imports.
+func (r *pgProductRepository) CreateProducts(ctx context.Context, products []*domain.Product) error {
+	if len(products) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := database.WithPgx(ctx, r.db, func(conn *pgx.Conn) error {
+		_, err := conn.CopyFrom(ctx, pgx.Identifier{"products"}, productColumns, pgx.CopyFromSlice(len(products), func(i int) ([]any, error) {
+			return productValues(products[i]), nil
+		}))
+		return err
+	})
+	r.queries.Observe("products.create_many", start, err)
+	if err != nil {
+		return fmt.Errorf("failed to create %d products: %w", len(products), err)
+	}
+	return nil
+}
+
 func (r *pgProductRepository) GetProductByID(ctx context.Context, id string) (*domain.Product, error) {
 	product := &domain.Product{}
 	query := `SELECT id, name, description, sku, created_at, updated_at FROM products WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
-	err := row.Scan(&product.ID, &product.Name, &product.Description, &product.SKU, &product.CreatedAt, &product.UpdatedAt)
+	start := time.Now()
+	err := database.WithPgx(ctx, r.db, func(conn *pgx.Conn) error {
+		return conn.QueryRow(ctx, query, id).Scan(&product.ID, &product.Name, &product.Description, &product.SKU, &product.CreatedAt, &product.UpdatedAt)
+	})
+	r.queries.Observe("products.get_by_id", start, err)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // Or a custom domain.ErrNotFound
 		}
 		return nil, err
@@ -42,6 +81,13 @@ func (r *pgProductRepository) GetProductByID(ctx context.Context, id string) (*d
 	return product, nil
 }
 
+// productColumns are the columns of the products table, in the order of productValues.
+var productColumns = []string{"id", "name", "description", "sku", "created_at", "updated_at"}
+
+func productValues(product *domain.Product) []any {
+	return []any{product.ID, product.Name, product.Description, product.SKU, product.CreatedAt, product.UpdatedAt}
+}
+
 // TODO: Add methods for UpdateProduct, DeleteProduct, ListProducts, etc.
 // TODO: Ensure you have a `products` table in your PostgreSQL database.
 // CREATE TABLE products (
@@ -0,0 +1,104 @@
+// Package repositorytest holds conformance suites for the product repository interfaces. Every
+// implementation runs them, so the in-memory repository cannot drift from the Postgres one.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/services/product/internal/domain"
+	"github.com/omni-compos/digital-mono/services/product/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunProductRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.ProductRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunProductRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.ProductRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		product := newProduct("product-1", "SKU-1")
+		require.NoError(t, repo.CreateProduct(ctx, product))
+
+		found, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, product.ID, found.ID)
+		assert.Equal(t, product.Name, found.Name)
+		assert.Equal(t, product.Description, found.Description)
+		assert.Equal(t, product.SKU, found.SKU)
+		assert.True(t, product.CreatedAt.Equal(found.CreatedAt))
+		assert.True(t, product.UpdatedAt.Equal(found.UpdatedAt))
+	})
+
+	t.Run("CreateDuplicateID", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateProduct(ctx, newProduct("product-1", "SKU-1")))
+		assert.Error(t, repo.CreateProduct(ctx, newProduct("product-1", "SKU-2")))
+	})
+
+	t.Run("CreateDuplicateSKU", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateProduct(ctx, newProduct("product-1", "SKU-1")))
+		assert.Error(t, repo.CreateProduct(ctx, newProduct("product-2", "SKU-1")))
+
+		found, err := repo.GetProductByID(ctx, "product-2")
+		require.NoError(t, err)
+		assert.Nil(t, found, "a rejected product must not be stored")
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		repo := newRepo(t)
+		products := []*domain.Product{newProduct("product-1", "SKU-1"), newProduct("product-2", "SKU-2")}
+		require.NoError(t, repo.CreateProducts(ctx, products))
+		require.NoError(t, repo.CreateProducts(ctx, nil))
+
+		for _, product := range products {
+			found, err := repo.GetProductByID(ctx, product.ID)
+			require.NoError(t, err)
+			require.NotNil(t, found)
+			assert.Equal(t, product.SKU, found.SKU)
+			assert.True(t, product.CreatedAt.Equal(found.CreatedAt))
+		}
+	})
+
+	t.Run("CreateManyAllOrNothing", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateProduct(ctx, newProduct("product-1", "SKU-1")))
+		assert.Error(t, repo.CreateProducts(ctx, []*domain.Product{newProduct("product-2", "SKU-2"), newProduct("product-3", "SKU-1")}))
+		assert.Error(t, repo.CreateProducts(ctx, []*domain.Product{newProduct("product-4", "SKU-4"), newProduct("product-4", "SKU-5")}))
+
+		for _, id := range []string{"product-2", "product-3", "product-4"} {
+			found, err := repo.GetProductByID(ctx, id)
+			require.NoError(t, err)
+			assert.Nil(t, found, "a rejected import must not store any product")
+		}
+	})
+
+	t.Run("StoresCopies", func(t *testing.T) {
+		repo := newRepo(t)
+		product := newProduct("product-1", "SKU-1")
+		require.NoError(t, repo.CreateProduct(ctx, product))
+		product.Name = "Changed after create"
+
+		found, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Widget", found.Name)
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		found, err := repo.GetProductByID(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
+func newProduct(id, sku string) *domain.Product {
+	// Postgres keeps microseconds
+	now := time.Date(2024, 5, 1, 9, 30, 0, 123456000, time.UTC)
+	return &domain.Product{ID: id, Name: "Widget", Description: "A widget", SKU: sku, CreatedAt: now, UpdatedAt: now}
+}
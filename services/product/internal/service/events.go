@@ -0,0 +1,8 @@
+package service
+
+// Domain events the product service records in its outbox, with the product as payload.
+const (
+	productAggregate = "product"
+
+	EventProductCreated = "product.created"
+)
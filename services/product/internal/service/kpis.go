@@ -0,0 +1,17 @@
+package service
+
+import "github.com/omni-compos/digital-mono/libs/metrics"
+
+// noLabels is the label set of counters that are not broken down any further.
+type noLabels struct{}
+
+// productKPIs are the business metrics of the product service.
+type productKPIs struct {
+	created *metrics.Counter[noLabels]
+}
+
+func newProductKPIs(kpis *metrics.BusinessMetrics) productKPIs {
+	return productKPIs{
+		created: metrics.NewCounter[noLabels](kpis, "products_created_total", "Products created."),
+	}
+}
@@ -2,10 +2,14 @@ package service
 
 import (
 	"context"
+	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	"github.com/omni-compos/digital-mono/libs/database"
 	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/libs/tracing"
 	"github.com/omni-compos/digital-mono/services/product/internal/domain"
 	"github.com/omni-compos/digital-mono/services/product/internal/repository"
 )
@@ -13,21 +17,32 @@ import (
 // ProductService defines the interface for product business logic.
 type ProductService interface {
 	CreateProduct(ctx context.Context, name, description, sku string) (*domain.Product, error)
+	// ImportProducts creates products in bulk from their name, description and SKU, all of them or none.
+	ImportProducts(ctx context.Context, products []*domain.Product) ([]*domain.Product, error)
 	GetProduct(ctx context.Context, id string) (*domain.Product, error)
 }
 
 type productService struct {
 	repo   repository.ProductRepository
 	logger logger.Logger
+	kpis   productKPIs
+	tx     database.Transactor
+	outbox *database.Outbox
 }
 
-// NewProductService creates a new product service.
-func NewProductService(repo repository.ProductRepository, log logger.Logger) ProductService {
-	return &productService{repo: repo, logger: log}
+// NewProductService creates a new product service. kpis may be nil. Products and their domain
+// events in outbox are written in one transaction of tx; nil tx and outbox are allowed.
+func NewProductService(repo repository.ProductRepository, log logger.Logger, kpis *metrics.BusinessMetrics, tx database.Transactor, outbox *database.Outbox) ProductService {
+	if tx == nil {
+		tx = database.NopTransactor{}
+	}
+	return &productService{repo: repo, logger: log, kpis: newProductKPIs(kpis), tx: tx, outbox: outbox}
 }
 
 func (s *productService) CreateProduct(ctx context.Context, name, description, sku string) (*domain.Product, error) {
-	s.logger.Info("Creating product", "name", name, "sku", sku)
+	ctx, span := tracing.Start(ctx, "ProductService.CreateProduct")
+	defer span.End()
+	logger.FromContext(ctx, s.logger).Info("Creating product", "name", name, "sku", sku)
 	now := time.Now()
 	product := &domain.Product{
 		ID:          uuid.NewString(),
@@ -37,10 +52,65 @@ func (s *productService) CreateProduct(ctx context.Context, name, description, s
 		CreatedAt:   now,
 		UpdatedAt:   now,
 	}
-	return product, s.repo.CreateProduct(ctx, product)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateProduct(ctx, product); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, productAggregate, product.ID, EventProductCreated, product)
+	})
+	if err != nil {
+		return product, err
+	}
+	s.kpis.created.Inc(noLabels{})
+	return product, nil
+}
+
+// MaxImportSize is the most products ImportProducts accepts at once.
+const MaxImportSize = 10000
+
+// ImportProducts validates every product before writing any; the products and their events are
+// written in one transaction.
+func (s *productService) ImportProducts(ctx context.Context, products []*domain.Product) ([]*domain.Product, error) {
+	ctx, span := tracing.Start(ctx, "ProductService.ImportProducts")
+	defer span.End()
+	if len(products) == 0 || len(products) > MaxImportSize {
+		return nil, fmt.Errorf("an import holds 1 to %d products, got %d: %w", MaxImportSize, len(products), domain.ErrInvalidProduct)
+	}
+	now := time.Now()
+	skus := make(map[string]bool, len(products))
+	events := make([]database.NewEvent, len(products))
+	for i, product := range products {
+		if product.Name == "" || product.SKU == "" {
+			return nil, fmt.Errorf("product %d: name and SKU are required: %w", i, domain.ErrInvalidProduct)
+		}
+		if skus[product.SKU] {
+			return nil, fmt.Errorf("product %d: duplicate SKU %s: %w", i, product.SKU, domain.ErrInvalidProduct)
+		}
+		skus[product.SKU] = true
+		product.ID = uuid.NewString()
+		product.CreatedAt = now
+		product.UpdatedAt = now
+		events[i] = database.NewEvent{AggregateType: productAggregate, AggregateID: product.ID, Type: EventProductCreated, Payload: product}
+	}
+	logger.FromContext(ctx, s.logger).Info("Importing products", "count", len(products))
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateProducts(ctx, products); err != nil {
+			return err
+		}
+		return s.outbox.AddAll(ctx, events)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for range products {
+		s.kpis.created.Inc(noLabels{})
+	}
+	return products, nil
 }
 
 func (s *productService) GetProduct(ctx context.Context, id string) (*domain.Product, error) {
-	s.logger.Info("Getting product", "id", id)
+	ctx, span := tracing.Start(ctx, "ProductService.GetProduct")
+	defer span.End()
+	logger.FromContext(ctx, s.logger).Info("Getting product", "id", id)
 	return s.repo.GetProductByID(ctx, id)
 }
\ No newline at end of file
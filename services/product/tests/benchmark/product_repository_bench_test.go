@@ -0,0 +1,135 @@
+// Package benchmark compares the pgx product repository with the database/sql path it replaced
+// (lib/pq, one statement per product). It runs against the database in TEST_DATABASE_DSN and is
+// skipped without it:
+//
+//	TEST_DATABASE_DSN=... go test -run '^$' -bench . -benchmem ./tests/benchmark
+package benchmark
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/services/product/internal/domain"
+	"github.com/omni-compos/digital-mono/services/product/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/product/internal/repository"
+)
+
+// importSize is the number of products per import.
+const importSize = 1000
+
+const insertProduct = `INSERT INTO products (id, name, description, sku, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
+
+func BenchmarkCreateProduct(b *testing.B) {
+	ctx := context.Background()
+	pgxDB, sqlDB := openDBs(b)
+
+	b.Run("database_sql", func(b *testing.B) {
+		databasetest.Truncate(b, sqlDB, "products")
+		for i := 0; i < b.N; i++ {
+			if _, err := sqlDB.ExecContext(ctx, insertProduct, productArgs(newProduct(i))...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pgx", func(b *testing.B) {
+		databasetest.Truncate(b, pgxDB, "products")
+		repo := repository.NewPGProductRepository(pgxDB, nil)
+		for i := 0; i < b.N; i++ {
+			if err := repo.CreateProduct(ctx, newProduct(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkImportProducts inserts importSize products per operation in one transaction.
+func BenchmarkImportProducts(b *testing.B) {
+	ctx := context.Background()
+	pgxDB, sqlDB := openDBs(b)
+
+	b.Run("database_sql_inserts", func(b *testing.B) {
+		tx := commonDB.NewTxManager(sqlDB, commonDB.TxOptions{})
+		benchmarkImport(b, sqlDB, func(products []*domain.Product) error {
+			return tx.WithinTx(ctx, func(ctx context.Context) error {
+				for _, product := range products {
+					if _, err := commonDB.Executor(ctx, sqlDB).ExecContext(ctx, insertProduct, productArgs(product)...); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	})
+
+	b.Run("pgx_batch", func(b *testing.B) {
+		tx := commonDB.NewTxManager(pgxDB, commonDB.TxOptions{})
+		benchmarkImport(b, pgxDB, func(products []*domain.Product) error {
+			return tx.WithinTx(ctx, func(ctx context.Context) error {
+				return commonDB.WithPgx(ctx, pgxDB, func(conn *pgx.Conn) error {
+					batch := &pgx.Batch{}
+					for _, product := range products {
+						batch.Queue(insertProduct, productArgs(product)...)
+					}
+					return conn.SendBatch(ctx, batch).Close()
+				})
+			})
+		})
+	})
+
+	b.Run("pgx_copy", func(b *testing.B) {
+		repo := repository.NewPGProductRepository(pgxDB, nil)
+		benchmarkImport(b, pgxDB, func(products []*domain.Product) error {
+			return repo.CreateProducts(ctx, products)
+		})
+	})
+}
+
+func benchmarkImport(b *testing.B, db *sql.DB, insert func(products []*domain.Product) error) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		databasetest.Truncate(b, db, "products")
+		products := make([]*domain.Product, importSize)
+		for j := range products {
+			products[j] = newProduct(j)
+		}
+		b.StartTimer()
+		if err := insert(products); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*importSize)/b.Elapsed().Seconds(), "products/s")
+}
+
+// openDBs returns the migrated test database opened through pgx and through lib/pq.
+func openDBs(b *testing.B) (pgxDB, sqlDB *sql.DB) {
+	pgxDB = databasetest.Open(b, migrations.FS, migrations.Table)
+	sqlDB, err := commonDB.NewPostgresDB(context.Background(), databasetest.Config(b))
+	if err != nil {
+		b.Fatalf("failed to connect to test database: %v", err)
+	}
+	b.Cleanup(func() { sqlDB.Close() })
+	return pgxDB, sqlDB
+}
+
+func newProduct(i int) *domain.Product {
+	now := time.Now()
+	return &domain.Product{
+		ID:          fmt.Sprintf("product-%08d", i),
+		Name:        fmt.Sprintf("Widget %d", i),
+		Description: "A widget",
+		SKU:         fmt.Sprintf("SKU-%08d", i),
+		CreatedAt:   now,
+		UpdatedAt:   now,
+	}
+}
+
+func productArgs(product *domain.Product) []any {
+	return []any{product.ID, product.Name, product.Description, product.SKU, product.CreatedAt, product.UpdatedAt}
+}
@@ -0,0 +1,25 @@
+package conformance
+
+import (
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/services/product/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/product/internal/repository"
+	"github.com/omni-compos/digital-mono/services/product/internal/repository/repositorytest"
+)
+
+func TestMemoryProductRepository(t *testing.T) {
+	repositorytest.RunProductRepositoryTests(t, func(t *testing.T) repository.ProductRepository {
+		return repository.NewMemoryProductRepository()
+	})
+}
+
+// TestPGProductRepository runs against the database in TEST_DATABASE_DSN and is skipped without it.
+func TestPGProductRepository(t *testing.T) {
+	repositorytest.RunProductRepositoryTests(t, func(t *testing.T) repository.ProductRepository {
+		db := databasetest.Open(t, migrations.FS, migrations.Table)
+		databasetest.Truncate(t, db, "products")
+		return repository.NewPGProductRepository(db, nil)
+	})
+}
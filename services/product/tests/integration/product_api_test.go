@@ -43,16 +43,24 @@ func (m *MockProductService) CreateProduct(ctx context.Context, arg1 string, arg
 	}
 	return args.Get(0).(*domain.Product), args.Error(1)
 }
+func (m *MockProductService) ImportProducts(ctx context.Context, products []*domain.Product) ([]*domain.Product, error) {
+	args := m.Called(ctx, products)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).([]*domain.Product), args.Error(1)
+}
+
 // Add other mock methods as needed for your product service
 
 func setupProductTestRouter(service *MockProductService, authenticator *commonAuth.JWTAuthenticator) *mux.Router {
 	testLogger := commonLogger.NewStdLogger()
 	promMetrics := commonMetrics.NewPrometheusMetrics("test_product", "api")
 
-	// Ensure productREST.NewProductRESTHandler matches its actual signature
-	restHandler := productREST.NewProductRESTHandler(service, testLogger, promMetrics) // Adjust if signature differs
+	restHandler := productREST.NewProductRESTHandler(service, testLogger, commonAuth.DefaultPolicy())
 
 	router := mux.NewRouter()
+	router.Use(promMetrics.Middleware) // RED metrics, as in cmd/api
 	apiRouter := router.PathPrefix("/api/v1").Subrouter()
 	apiRouter.Use(authenticator.Middleware) // Apply JWT auth middleware
 	restHandler.RegisterRoutes(apiRouter)   // Assuming RegisterRoutes exists
@@ -126,4 +134,4 @@ func TestProductAPI_GetProduct_Integration(t *testing.T) {
 
 // TODO: Add more integration tests for other product endpoints (create, update, delete)
 // ensuring they also handle authentication correctly.
-// Consider testing role-based access if your claims and handlers use roles.
\ No newline at end of file
+// Consider testing role-based access if your claims and handlers use roles.
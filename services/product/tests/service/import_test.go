@@ -0,0 +1,95 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/product/internal/domain"
+	"github.com/omni-compos/digital-mono/services/product/internal/handler/rest"
+	"github.com/omni-compos/digital-mono/services/product/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/product/internal/repository"
+	"github.com/omni-compos/digital-mono/services/product/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestImportProducts_CreatesAll(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryProductRepository()
+	svc := service.NewProductService(repo, logger.NewStdLogger(), nil, nil, nil)
+
+	imported, err := svc.ImportProducts(ctx, []*domain.Product{{Name: "Widget", SKU: "SKU-1"}, {Name: "Gadget", SKU: "SKU-2"}})
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	for _, product := range imported {
+		assert.NotEmpty(t, product.ID)
+		assert.False(t, product.CreatedAt.IsZero())
+		stored, err := repo.GetProductByID(ctx, product.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored)
+	}
+}
+
+func TestImportProducts_RejectsInvalidProducts(t *testing.T) {
+	svc := service.NewProductService(repository.NewMemoryProductRepository(), logger.NewStdLogger(), nil, nil, nil)
+	for name, products := range map[string][]*domain.Product{
+		"empty":         nil,
+		"missing name":  {{SKU: "SKU-1"}},
+		"missing SKU":   {{Name: "Widget"}},
+		"duplicate SKU": {{Name: "Widget", SKU: "SKU-1"}, {Name: "Gadget", SKU: "SKU-1"}},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.ImportProducts(context.Background(), products)
+			assert.ErrorIs(t, err, domain.ErrInvalidProduct)
+		})
+	}
+}
+
+func TestImportProductsEndpoint(t *testing.T) {
+	svc := service.NewProductService(repository.NewMemoryProductRepository(), logger.NewStdLogger(), nil, nil, nil)
+	handler := rest.NewProductRESTHandler(svc, logger.NewStdLogger(), nil)
+
+	for name, tc := range map[string]struct {
+		body string
+		want int
+	}{
+		"created":      {`[{"name":"Widget","sku":"SKU-1"}]`, http.StatusCreated},
+		"invalid":      {`[{"name":"Widget"}]`, http.StatusBadRequest},
+		"not an array": {`{"name":"Widget","sku":"SKU-2"}`, http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			rec := httptest.NewRecorder()
+			handler.ImportProductsHandler(rec, httptest.NewRequest(http.MethodPost, "/products/import", strings.NewReader(tc.body)))
+			assert.Equal(t, tc.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+// TestImportProducts_WritesOutboxEvents runs against the database in TEST_DATABASE_DSN and is skipped without it.
+func TestImportProducts_WritesOutboxEvents(t *testing.T) {
+	ctx := context.Background()
+	db := databasetest.Open(t, migrations.FS, migrations.Table)
+	databasetest.Truncate(t, db, "products", migrations.OutboxTable)
+	outbox, err := commonDB.NewOutbox(db, migrations.OutboxTable)
+	require.NoError(t, err)
+	svc := service.NewProductService(repository.NewPGProductRepository(db, nil), logger.NewStdLogger(), nil, commonDB.NewTxManager(db, commonDB.TxOptions{}), outbox)
+
+	imported, err := svc.ImportProducts(ctx, []*domain.Product{{Name: "Widget", SKU: "SKU-1"}, {Name: "Gadget", SKU: "SKU-2"}})
+	require.NoError(t, err)
+
+	var count int
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM `+migrations.OutboxTable+` WHERE event_type = $1`, service.EventProductCreated).Scan(&count))
+	assert.Equal(t, len(imported), count, "one event per product")
+
+	// A failing import writes neither products nor events
+	_, err = svc.ImportProducts(ctx, []*domain.Product{{Name: "Widget again", SKU: "SKU-1"}})
+	require.Error(t, err)
+	require.NoError(t, db.QueryRowContext(ctx, `SELECT count(*) FROM `+migrations.OutboxTable).Scan(&count))
+	assert.Equal(t, len(imported), count)
+}
@@ -1,63 +1,99 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/graphql-go/handler"
-	_ "github.com/lib/pq" // PostgreSQL driver
 	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	commonDB "github.com/omni-compos/digital-mono/libs/database"
 	commonLoc "github.com/omni-compos/digital-mono/libs/localization"
 	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
 	commonMetrics "github.com/omni-compos/digital-mono/libs/metrics"
+	commonTracing "github.com/omni-compos/digital-mono/libs/tracing"
 
 	sellerGraphQL "github.com/omni-compos/digital-mono/services/seller/internal/handler/graphql"
 	sellerREST "github.com/omni-compos/digital-mono/services/seller/internal/handler/rest"
-	sellerRepo "github.com/omni-compos/digital-mono/services/seller/internal/repository"
 	sellerService "github.com/omni-compos/digital-mono/services/seller/internal/service"
 )
 
 func main() {
 	// Configuration
-	dbDSN := os.Getenv("DB_DSN")
-	if dbDSN == "" {
-		// Using a default DSN for local development if not set
-		dbDSN = "host=localhost port=5432 user=omni_user password=strong_password dbname=digital_mono_db sslmode=disable"
-		log.Println("Warning: DB_DSN not set, using default for seller service.")
-	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		// Using a default JWT secret for local development if not set
-		jwtSecret = "your-super-secret-key-for-user-service"
-		log.Println("Warning: JWT_SECRET not set, using default for seller service.")
+	// Tokens are issued by the user service; verify them against its published signing keys
+	jwksURL := os.Getenv("JWKS_URL")
+	if jwksURL == "" {
+		jwksURL = "http://localhost:8081/.well-known/jwks.json"
+		log.Println("Warning: JWKS_URL not set, using default for seller service.")
 	}
 
 	// Initialize common libraries
-	appLogger := commonLogger.NewStdLogger()
+	// Structured logs; LOG_FORMAT (json|text), LOG_LEVEL and SERVICE_VERSION configure the output
+	logConfig := commonLogger.ConfigFromEnv("seller-service")
+	logConfig.Redactor.RegisterKeys(commonLogger.RedactMask, "postcode", "latitude", "longitude") // Seller locations
+	appLogger, err := commonLogger.NewSlogLogger(logConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	commonLogger.SetDefault(appLogger)
 	appLogger.Info("Starting seller service...")
 
-	db, err := commonDB.NewPostgresDB(dbDSN)
+	// Traces; OTEL_TRACES_EXPORTER (otlp|stdout|file|none) and OTEL_EXPORTER_OTLP_ENDPOINT configure the export
+	shutdownTracing, err := commonTracing.Setup(context.Background(), commonTracing.ConfigFromEnv("seller-service"))
 	if err != nil {
-		appLogger.Error(err, "Failed to connect to database")
-		log.Fatalf("Failed to connect to database: %v", err)
+		appLogger.Error(err, "Failed to initialize tracing")
+		log.Fatalf("Failed to initialize tracing: %v", err)
 	}
-	defer db.Close()
-	appLogger.Info("Successfully connected to database")
+	defer shutdownTracing(context.Background())
+	// Outgoing calls (e.g. JWKS and OIDC fetches) carry the trace and request ID
+	http.DefaultTransport = commonTracing.Transport(commonLogger.Transport(http.DefaultTransport))
 
 	promMetrics := commonMetrics.NewPrometheusMetrics("seller_service", "api")
-	authenticator := commonAuth.NewJWTAuthenticator(jwtSecret)
+	// Connection pool stats and per-query latency/errors, served on /metrics
+	queryMetrics := commonDB.NewQueryMetrics("seller_service")
+	// Domain KPIs (e.g. sellers created, status transitions), served on /metrics under seller_service_business_*
+	kpis := commonMetrics.NewBusinessMetrics("seller_service")
+	if err := promMetrics.Register(queryMetrics, kpis); err != nil {
+		appLogger.Error(err, "Failed to register query and business metrics")
+		log.Fatalf("Failed to register query and business metrics: %v", err)
+	}
+
+	// Postgres by default; STORAGE=memory runs without a database
+	store, err := openStorage(context.Background(), appLogger, queryMetrics)
+	if err != nil {
+		appLogger.Error(err, "Failed to set up storage")
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+	defer store.close()
+	if store.db != nil {
+		if err := promMetrics.Register(commonDB.NewDBStatsCollector(store.db, "sellers")); err != nil {
+			appLogger.Error(err, "Failed to register database metrics")
+			log.Fatalf("Failed to register database metrics: %v", err)
+		}
+	}
+	// Service tokens must be addressed to this service
+	authenticator := commonAuth.NewJWKSAuthenticator(jwksURL, 5*time.Minute).
+		WithRevocationStore(store.revocations).
+		WithAPIKeyStore(store.apiKeys).
+		WithAudience("seller-service")
+
+	// Role to permission mapping; AUTHZ_POLICY_FILE overrides the policy bundled with libs/auth
+	policy, err := commonAuth.LoadPolicy(os.Getenv("AUTHZ_POLICY_FILE"))
+	if err != nil {
+		appLogger.Error(err, "Failed to load authorization policy")
+		log.Fatalf("Failed to load authorization policy: %v", err)
+	}
 
 	// Initialize service-specific components
-	repo := sellerRepo.NewPGSellerRepository(db)
-	locService := commonLoc.NewDummyLocationalisationService() // Use the dummy locationalisation service
-	service := sellerService.NewSellerService(repo, locService, appLogger)
+	locService := commonLoc.NewTracedLocalisationService(commonLoc.NewDummyLocationalisationService()) // Use the dummy locationalisation service, traced
+	service := sellerService.NewSellerService(store.repo, locService, appLogger, kpis, store.tx, store.outbox)
 
-	restHandler := sellerREST.NewSellerRESTHandler(service, appLogger, promMetrics)
-	gqlHandler, err := sellerGraphQL.NewSellerGraphQLHandler(service, appLogger)
+	restHandler := sellerREST.NewSellerRESTHandler(service, appLogger, policy)
+	gqlHandler, err := sellerGraphQL.NewSellerGraphQLHandler(service, appLogger, policy)
 	if err != nil {
 		appLogger.Error(err, "Failed to create GraphQL handler")
 		log.Fatalf("Failed to create GraphQL handler: %v", err)
@@ -65,6 +101,9 @@ func main() {
 
 	// Router
 	r := mux.NewRouter()
+	r.Use(commonTracing.Middleware("seller-service")) // Server spans; first, so logs and metrics see the trace
+	r.Use(commonLogger.RequestMiddleware(appLogger))  // Request-scoped logger with X-Request-ID/traceparent
+	r.Use(promMetrics.Middleware)                     // RED metrics labeled by route template
 
 	// REST API routes with JWT authentication middleware
 	apiRouter := r.PathPrefix("/api/v1").Subrouter()
@@ -75,6 +114,8 @@ func main() {
 	// Note: GraphQL handler needs access to context for JWT claims if not handled by middleware
 	// The graphql-go/handler can wrap middleware, or you can access context in resolvers
 	// We'll rely on accessing context in resolvers as shown in seller_handler.go
+	// Spans for parse, validation, execution and resolvers; operation, resolver and error metrics
+	gqlHandler.Schema.AddExtensions(commonTracing.GraphQLExtension(), promMetrics.GraphQLExtension())
 	graphqlHTTPHandler := handler.New(&handler.Config{
 		Schema:   &gqlHandler.Schema,
 		Pretty:   true,
@@ -83,6 +124,9 @@ func main() {
 	// Apply JWT middleware to GraphQL endpoint as well
 	r.Handle("/graphql", authenticator.Middleware(graphqlHTTPHandler))
 
+	// Runtime log levels, e.g. PUT {"level":"debug","packages":{"repository":"debug"}}
+	r.Handle("/admin/loglevel", authenticator.Middleware(policy.RequirePermission(commonAuth.PermLoggingAdmin)(commonLogger.LevelHandler(logConfig.Levels)))).Methods(http.MethodGet, http.MethodPut)
+
 	// Metrics endpoint (usually doesn't require auth)
 	r.Handle("/metrics", promMetrics.Handler())
 
@@ -91,6 +135,8 @@ func main() {
 		w.WriteHeader(http.StatusOK)
 		w.Write([]byte("OK"))
 	}).Methods("GET")
+	// Readiness: 503 while the database is unreachable; always ready with in-memory storage
+	r.Handle("/ready", store.ready).Methods("GET")
 
 	// Start the server
 	port := os.Getenv("PORT")
@@ -0,0 +1,165 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
+
+	sellerMigrations "github.com/omni-compos/digital-mono/services/seller/internal/migrations"
+	sellerRepo "github.com/omni-compos/digital-mono/services/seller/internal/repository"
+)
+
+// storage is what the service persists to: Postgres, or process memory with STORAGE=memory.
+type storage struct {
+	repo        sellerRepo.SellerRepository
+	tx          commonDB.Transactor
+	outbox      *commonDB.Outbox           // nil in memory; domain events are not recorded
+	revocations commonAuth.RevocationStore // nil in memory; revoked tokens stay valid until they expire
+	apiKeys     commonAuth.APIKeyStore     // nil in memory; API keys are not accepted
+	db          *sql.DB                    // Primary database, nil in memory
+	ready       http.Handler
+	close       func()
+}
+
+// openStorage returns the storage selected by STORAGE (postgres|memory), defaulting to postgres.
+func openStorage(ctx context.Context, appLogger commonLogger.Logger, queryMetrics *commonDB.QueryMetrics) (*storage, error) {
+	switch mode := os.Getenv("STORAGE"); mode {
+	case "", "postgres":
+		return newPostgresStorage(ctx, appLogger, queryMetrics)
+	case "memory":
+		appLogger.Info("Using in-memory storage; data is lost on restart")
+		return newMemoryStorage(), nil
+	default:
+		return nil, fmt.Errorf("unknown storage %q, want postgres or memory", mode)
+	}
+}
+
+// newMemoryStorage needs no database, for local development and demos. Tokens are still verified
+// against the user service's keys.
+func newMemoryStorage() *storage {
+	return &storage{
+		repo: sellerRepo.NewMemorySellerRepository(),
+		tx:   commonDB.NopTransactor{},
+		ready: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		}),
+		close: func() {},
+	}
+}
+
+// newPostgresStorage connects to the database configured by DB_* (see libs/database ConfigFromEnv),
+// migrates it and starts the outbox relay.
+func newPostgresStorage(ctx context.Context, appLogger commonLogger.Logger, queryMetrics *commonDB.QueryMetrics) (*storage, error) {
+	dbConfig, err := commonDB.ConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	if os.Getenv("DB_DSN") == "" && os.Getenv("DB_HOST") == "" {
+		log.Println("Warning: DB_DSN not set, using local development database for seller service.")
+	}
+
+	// Waits for Postgres to accept connections, e.g. while it starts next to the service
+	dbConfig.OnRetry = func(attempt int, wait time.Duration, err error) {
+		appLogger.Warn(err, "Database not reachable yet, retrying", "attempt", attempt, "wait", wait.String())
+	}
+	db, err := commonDB.NewPgxDB(ctx, dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	appLogger.Info("Successfully connected to database")
+
+	// Schema migrations embedded in the service; DB_MIGRATE_ON_STARTUP=false leaves them to cmd/migrate
+	if os.Getenv("DB_MIGRATE_ON_STARTUP") != "false" {
+		migrator, err := commonDB.NewMigrator(db, sellerMigrations.FS, sellerMigrations.Table)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load database migrations: %w", err)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		appLogger.Info("Database schema is up to date", "applied_migrations", len(applied))
+	}
+
+	// The revocation list is written by the user service; without its tables, fail here rather than on every request
+	revocations := commonAuth.NewSQLRevocationStore(db)
+	if err := revocations.CheckSchema(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("revocation list unavailable: %w", err)
+	}
+
+	// API keys are managed by the user service too
+	apiKeys := commonAuth.NewSQLAPIKeyStore(db)
+	if err := apiKeys.CheckSchema(ctx); err != nil {
+		db.Close()
+		return nil, fmt.Errorf("API keys unavailable: %w", err)
+	}
+
+	// Reads go to the replicas in DB_REPLICA_DSNS, if any; callers read the primary shortly after writing
+	replicas, err := commonDB.NewPgxReplicaDBs(dbConfig)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to open read replicas: %w", err)
+	}
+	cluster := commonDB.NewCluster(db, replicas, commonDB.ClusterConfig{
+		SessionKey: func(ctx context.Context) string {
+			claims, ok := commonAuth.GetClaimsFromContext(ctx)
+			if !ok {
+				return ""
+			}
+			if claims.UserID != "" {
+				return claims.UserID
+			}
+			return claims.ClientID
+		},
+		OnReplicaStateChange: func(replica int, healthy bool, err error) {
+			if healthy {
+				appLogger.Info("Read replica back in rotation", "replica", replica)
+				return
+			}
+			appLogger.Warn(err, "Read replica taken out of rotation", "replica", replica)
+		},
+	})
+	go cluster.Run(ctx)
+
+	// Domain events are written with each change and published by the relay; logged until a broker is wired in
+	outbox, err := commonDB.NewOutbox(db, sellerMigrations.OutboxTable)
+	if err != nil {
+		cluster.Close()
+		db.Close()
+		return nil, fmt.Errorf("failed to create outbox: %w", err)
+	}
+	relay := commonDB.NewRelay(outbox, commonDB.PublisherFunc(func(ctx context.Context, event commonDB.OutboxEvent) error {
+		appLogger.Info("Published domain event", "event_id", event.ID, "event_type", event.Type, "aggregate_id", event.AggregateID)
+		return nil
+	}), commonDB.RelayConfig{OnError: func(err error) { appLogger.Error(err, "Outbox relay failed") }})
+	// Publishers act for the system, not for a caller, so they may read every brand's sellers
+	go relay.Run(commonAuth.WithSystemTenant(ctx))
+
+	return &storage{
+		repo: sellerRepo.NewPGSellerRepository(cluster, queryMetrics),
+		// Repeatable read: concurrent updates of a seller fail with a serialization error and are retried
+		tx:          commonDB.NewTxManager(db, commonDB.TxOptions{Isolation: sql.LevelRepeatableRead}),
+		outbox:      outbox,
+		revocations: revocations,
+		apiKeys:     apiKeys,
+		db:          db,
+		// Readiness: 503 while the database is unreachable
+		ready: commonDB.ReadinessHandler(db),
+		close: func() {
+			cluster.Close()
+			db.Close()
+		},
+	}, nil
+}
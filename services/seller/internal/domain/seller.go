@@ -1,6 +1,7 @@
 package domain
 
 import (
+	"errors"
 	"time"
 )
 
@@ -13,6 +14,17 @@ const (
 
 var ValidBrandIDs = []string{BrandIDBrandA, BrandIDBrandB, BrandIDBrandC}
 
+var (
+	// ErrSellerNotFound is returned when a seller does not exist or belongs to a brand outside the caller's tenants.
+	ErrSellerNotFound = errors.New("seller not found")
+	// ErrBrandNotAllowed is returned when creating or moving a seller into a brand outside the caller's tenants.
+	ErrBrandNotAllowed = errors.New("brand not allowed for caller")
+	// ErrInvalidSeller is returned when an import holds a seller that fails validation.
+	ErrInvalidSeller = errors.New("invalid seller")
+	// ErrInvalidPagination is returned when listing sellers with a negative limit or offset.
+	ErrInvalidPagination = errors.New("limit and offset must not be negative")
+)
+
 const (
 	StatusActive   = "ACTIVE"
 	StatusInactive = "INACTIVE"
@@ -35,7 +47,7 @@ type Seller struct {
 	PhoneNumber   string     `json:"phoneNumber"`
 	Latitude      float64    `json:"latitude"`
 	Longitude     float64    `json:"longitude"`
-	LastUpdatedBy string     `json:"lastUpdatedBy"` // User ID from JWT
+	LastUpdatedBy string     `json:"lastUpdatedBy"` // User ID, or "service:<client_id>" for service tokens, from JWT
 	LastUpdateTime time.Time `json:"lastUpdateTime"`
 }
 
@@ -1,6 +1,7 @@
 package graphql
 
 import (
+	"errors"
 	"fmt"
 
 	"github.com/graphql-go/graphql"
@@ -17,7 +18,7 @@ type SellerGraphQLHandler struct {
 }
 
 // NewProductGraphQLHandler creates a new SellerGraphQLHandler.
-func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger) (*SellerGraphQLHandler, error) {
+func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger, policy *commonAuth.Policy) (*SellerGraphQLHandler, error) {
 	// Define the Seller object type
 	sellerType := graphql.NewObject(
 		graphql.ObjectConfig{
@@ -52,18 +53,20 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 						Type: graphql.NewNonNull(graphql.String),
 					},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermSellersRead, func(p graphql.ResolveParams) (interface{}, error) {
 					id, ok := p.Args["id"].(string)
 					if !ok {
 						return nil, fmt.Errorf("invalid seller ID")
 					}
-					// Authentication check (optional here if middleware handles it, but good practice in resolvers too)
-					// _, authOK := p.Context.Value(commonAuth.UserIDContextKey).(string)
-					// if !authOK {
-					// 	return nil, fmt.Errorf("unauthorized")
-					// }
-					return service.GetSellerByID(p.Context, id)
-				},
+					seller, err := service.GetSellerByID(p.Context, id)
+					if err != nil {
+						return nil, err
+					}
+					if seller == nil {
+						return nil, mapServiceError(domain.ErrSellerNotFound)
+					}
+					return seller, nil
+				}),
 			},
 			"sellers": &graphql.Field{
 				Type: graphql.NewList(sellerType),
@@ -77,16 +80,11 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 						DefaultValue: 0,
 					},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermSellersRead, func(p graphql.ResolveParams) (interface{}, error) {
 					limit, _ := p.Args["limit"].(int)
 					offset, _ := p.Args["offset"].(int)
-					// Authentication check
-					// _, authOK := p.Context.Value(commonAuth.UserIDContextKey).(string)
-					// if !authOK {
-					// 	return nil, fmt.Errorf("unauthorized")
-					// }
 					return service.ListSellers(p.Context, limit, offset)
-				},
+				}),
 			},
 		},
 	})
@@ -109,7 +107,7 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 					"phoneNumber": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
 					// lat/lng, lastUpdatedBy, lastUpdateTime are set by the service
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermSellersWrite, func(p graphql.ResolveParams) (interface{}, error) {
 					// Get UserID from JWT claims in context
 					// Get UserID from JWT claims in context 
 					claims, ok := commonAuth.GetClaimsFromContext(p.Context)
@@ -131,8 +129,12 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 					newSeller.Email, _ = p.Args["email"].(string)
 					newSeller.PhoneNumber, _ = p.Args["phoneNumber"].(string)
 
-					return service.CreateSeller(p.Context, newSeller, claims.UserID)
-				},
+					seller, err := service.CreateSeller(p.Context, newSeller, claims.Principal()) // user ID or "service:<client_id>"
+					if err != nil {
+						return nil, mapServiceError(err)
+					}
+					return seller, nil
+				}),
 			},
 			"updateSeller": &graphql.Field{
 				Type: sellerType, // Return the updated seller
@@ -149,7 +151,7 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 					"phoneNumber": &graphql.ArgumentConfig{Type: graphql.String},
 					// lat/lng, lastUpdatedBy, lastUpdateTime are set by the service
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermSellersWrite, func(p graphql.ResolveParams) (interface{}, error) {
 					id, ok := p.Args["id"].(string)
 					if !ok {
 						return nil, fmt.Errorf("invalid seller ID")
@@ -190,8 +192,12 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 						updates.PhoneNumber = phoneNumber
 					}
 
-					return service.UpdateSeller(p.Context, id, updates, claims.UserID)
-				},
+					seller, err := service.UpdateSeller(p.Context, id, updates, claims.Principal())
+					if err != nil {
+						return nil, mapServiceError(err)
+					}
+					return seller, nil
+				}),
 			},
 			"deleteSeller": &graphql.Field{
 				Type: graphql.Boolean, // Or a custom success type
@@ -200,22 +206,17 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 						Type: graphql.NewNonNull(graphql.String),
 					},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermSellersDelete, func(p graphql.ResolveParams) (interface{}, error) {
 					id, ok := p.Args["id"].(string)
 					if !ok {
 						return false, fmt.Errorf("invalid seller ID")
 					}
-					// Authentication check
-					// _, authOK := p.Context.Value(commonAuth.UserIDContextKey).(string)
-					// if !authOK {
-					// 	return false, fmt.Errorf("unauthorized")
-					// }
 					err := service.DeleteSeller(p.Context, id)
 					if err != nil {
-						return false, err // Return false and the error
+						return false, mapServiceError(err) // Return false and the error
 					}
 					return true, nil // Return true on success
-				},
+				}),
 			},
 		},
 	})
@@ -236,4 +237,31 @@ func NewSellerGraphQLHandler(service service.SellerService, logger logger.Logger
 		Schema: schema,
 		logger: logger,
 	}, nil
-}
\ No newline at end of file
+}
+
+// graphQLError exposes a status in the GraphQL error extensions, e.g. {"code": "NOT_FOUND", "status": 404},
+// so clients can tell missing sellers apart from server errors as they would over REST.
+type graphQLError struct {
+	err    error
+	code   string
+	status int
+}
+
+func (e *graphQLError) Error() string { return e.err.Error() }
+
+func (e *graphQLError) Unwrap() error { return e.err }
+
+func (e *graphQLError) Extensions() map[string]interface{} {
+	return map[string]interface{}{"code": e.code, "status": e.status}
+}
+
+// mapServiceError turns not-found and tenant errors from the service into coded GraphQL errors.
+func mapServiceError(err error) error {
+	switch {
+	case errors.Is(err, domain.ErrSellerNotFound):
+		return &graphQLError{err: domain.ErrSellerNotFound, code: "NOT_FOUND", status: 404}
+	case errors.Is(err, domain.ErrBrandNotAllowed):
+		return &graphQLError{err: err, code: "FORBIDDEN", status: 403}
+	}
+	return err
+}
@@ -0,0 +1,17 @@
+// Package migrations embeds the seller service's versioned schema migrations, applied by the
+// libs/database Migrator on startup or through cmd/migrate.
+package migrations
+
+import "embed"
+
+// FS holds the <version>_<name>.up.sql and .down.sql files.
+//
+//go:embed *.sql
+var FS embed.FS
+
+const (
+	// Table records which of these migrations have been applied.
+	Table = "seller_schema_migrations"
+	// OutboxTable holds the service's domain events until the outbox relay publishes them.
+	OutboxTable = "seller_outbox"
+)
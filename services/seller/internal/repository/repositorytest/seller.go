@@ -0,0 +1,215 @@
+// Package repositorytest holds conformance suites for the seller repository interfaces. Every
+// implementation runs them, so the in-memory repository cannot drift from the Postgres one.
+package repositorytest
+
+import (
+	"context"
+	"fmt"
+	"testing"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
+	"github.com/omni-compos/digital-mono/services/seller/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunSellerRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.SellerRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunSellerRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.SellerRepository) {
+	ctx := commonAuth.WithSystemTenant(context.Background())
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		seller := newSeller("seller-1", model.BrandIDBrandA)
+		require.NoError(t, repo.CreateSeller(ctx, seller))
+
+		found, err := repo.GetSellerByID(ctx, seller.ID)
+		require.NoError(t, err)
+		assertSellerEqual(t, seller, found)
+	})
+
+	t.Run("CreateDuplicateID", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-1", model.BrandIDBrandA)))
+		assert.Error(t, repo.CreateSeller(ctx, newSeller("seller-1", model.BrandIDBrandB)))
+	})
+
+	t.Run("CreateMany", func(t *testing.T) {
+		repo := newRepo(t)
+		sellers := []*model.Seller{newSeller("seller-2", model.BrandIDBrandA), newSeller("seller-1", model.BrandIDBrandB)}
+		require.NoError(t, repo.CreateSellers(ctx, sellers))
+		require.NoError(t, repo.CreateSellers(ctx, nil))
+
+		for _, seller := range sellers {
+			found, err := repo.GetSellerByID(ctx, seller.ID)
+			require.NoError(t, err)
+			assertSellerEqual(t, seller, found)
+		}
+	})
+
+	t.Run("CreateManyAllOrNothing", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-2", model.BrandIDBrandA)))
+		err := repo.CreateSellers(ctx, []*model.Seller{newSeller("seller-1", model.BrandIDBrandA), newSeller("seller-2", model.BrandIDBrandA)})
+		assert.Error(t, err)
+		assert.Error(t, repo.CreateSellers(ctx, []*model.Seller{newSeller("seller-3", model.BrandIDBrandA), newSeller("seller-3", model.BrandIDBrandA)}))
+
+		page, err := repo.ListSellers(ctx, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seller-2"}, sellerIDs(page))
+	})
+
+	t.Run("StoresCopies", func(t *testing.T) {
+		repo := newRepo(t)
+		seller := newSeller("seller-1", model.BrandIDBrandA)
+		require.NoError(t, repo.CreateSeller(ctx, seller))
+		seller.City = "Changed after create"
+
+		found, err := repo.GetSellerByID(ctx, seller.ID)
+		require.NoError(t, err)
+		assert.Equal(t, "Sydney", found.City)
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		found, err := repo.GetSellerByID(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("Update", func(t *testing.T) {
+		repo := newRepo(t)
+		seller := newSeller("seller-1", model.BrandIDBrandA)
+		require.NoError(t, repo.CreateSeller(ctx, seller))
+
+		seller.Status = model.StatusActive
+		seller.City = "Melbourne"
+		seller.LastUpdatedBy = "user-2"
+		seller.LastUpdateTime = seller.LastUpdateTime.Add(time.Minute)
+		require.NoError(t, repo.UpdateSeller(ctx, seller))
+
+		found, err := repo.GetSellerByID(ctx, seller.ID)
+		require.NoError(t, err)
+		assertSellerEqual(t, seller, found)
+	})
+
+	t.Run("UpdateNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		err := repo.UpdateSeller(ctx, newSeller("missing", model.BrandIDBrandA))
+		assert.ErrorIs(t, err, model.ErrSellerNotFound)
+	})
+
+	t.Run("Delete", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-1", model.BrandIDBrandA)))
+		require.NoError(t, repo.DeleteSeller(ctx, "seller-1"))
+
+		found, err := repo.GetSellerByID(ctx, "seller-1")
+		require.NoError(t, err)
+		assert.Nil(t, found)
+		assert.ErrorIs(t, repo.DeleteSeller(ctx, "seller-1"), model.ErrSellerNotFound)
+	})
+
+	t.Run("ListOrderedByIDWithPagination", func(t *testing.T) {
+		repo := newRepo(t)
+		for _, id := range []string{"seller-3", "seller-1", "seller-5", "seller-2", "seller-4"} {
+			require.NoError(t, repo.CreateSeller(ctx, newSeller(id, model.BrandIDBrandA)))
+		}
+
+		page, err := repo.ListSellers(ctx, 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seller-1", "seller-2"}, sellerIDs(page))
+		page, err = repo.ListSellers(ctx, 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seller-3", "seller-4"}, sellerIDs(page))
+		page, err = repo.ListSellers(ctx, 2, 4)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seller-5"}, sellerIDs(page))
+		page, err = repo.ListSellers(ctx, 2, 10)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+		page, err = repo.ListSellers(ctx, 0, 0)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+
+	t.Run("ListRejectsNegativePagination", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-1", model.BrandIDBrandA)))
+
+		_, err := repo.ListSellers(ctx, -1, 0)
+		assert.ErrorIs(t, err, model.ErrInvalidPagination)
+		_, err = repo.ListSellers(ctx, 10, -1)
+		assert.ErrorIs(t, err, model.ErrInvalidPagination)
+	})
+
+	t.Run("TenantIsolation", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-a", model.BrandIDBrandA)))
+		require.NoError(t, repo.CreateSeller(ctx, newSeller("seller-b", model.BrandIDBrandB)))
+		brandA := context.WithValue(ctx, commonAuth.ClaimsContextKey, &commonAuth.Claims{UserID: "user-1", Brands: []string{model.BrandIDBrandA}})
+
+		found, err := repo.GetSellerByID(brandA, "seller-b")
+		require.NoError(t, err)
+		assert.Nil(t, found, "sellers of other brands must look missing")
+		assert.ErrorIs(t, repo.UpdateSeller(brandA, newSeller("seller-b", model.BrandIDBrandB)), model.ErrSellerNotFound)
+		assert.ErrorIs(t, repo.DeleteSeller(brandA, "seller-b"), model.ErrSellerNotFound)
+
+		page, err := repo.ListSellers(brandA, 10, 0)
+		require.NoError(t, err)
+		assert.Equal(t, []string{"seller-a"}, sellerIDs(page))
+
+		found, err = repo.GetSellerByID(ctx, "seller-b")
+		require.NoError(t, err)
+		require.NotNil(t, found, "the system tenant sees every brand")
+
+		anonymous := context.Background()
+		found, err = repo.GetSellerByID(anonymous, "seller-a")
+		require.NoError(t, err)
+		assert.Nil(t, found, "a caller without claims sees no brand")
+		assert.ErrorIs(t, repo.UpdateSeller(anonymous, newSeller("seller-a", model.BrandIDBrandA)), model.ErrSellerNotFound)
+		assert.ErrorIs(t, repo.DeleteSeller(anonymous, "seller-a"), model.ErrSellerNotFound)
+		page, err = repo.ListSellers(anonymous, 10, 0)
+		require.NoError(t, err)
+		assert.Empty(t, page)
+	})
+}
+
+func newSeller(id, brandID string) *model.Seller {
+	seller := model.NewSeller()
+	seller.ID = id
+	seller.BrandID = brandID
+	seller.Status = model.StatusPending
+	seller.Address = "1 George St"
+	seller.City = "Sydney"
+	seller.State = "NSW"
+	seller.Postcode = "2000"
+	seller.Email = fmt.Sprintf("%s@example.com", id)
+	seller.PhoneNumber = "0400000000"
+	seller.Latitude = -33.8688
+	seller.Longitude = 151.2093
+	seller.LastUpdatedBy = "user-1"
+	// Postgres keeps microseconds
+	seller.LastUpdateTime = time.Date(2024, 5, 1, 9, 30, 0, 123456000, time.UTC)
+	return seller
+}
+
+// assertSellerEqual compares sellers field by field, treating times in different locations as equal.
+func assertSellerEqual(t *testing.T, want, got *model.Seller) {
+	t.Helper()
+	require.NotNil(t, got)
+	wantCopy, gotCopy := *want, *got
+	wantCopy.LastUpdateTime = want.LastUpdateTime.UTC()
+	gotCopy.LastUpdateTime = got.LastUpdateTime.UTC()
+	assert.Equal(t, wantCopy, gotCopy)
+}
+
+func sellerIDs(sellers []*model.Seller) []string {
+	ids := []string{}
+	for _, seller := range sellers {
+		ids = append(ids, seller.ID)
+	}
+	return ids
+}
@@ -0,0 +1,125 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
+)
+
+// MemorySellerRepository is an in-memory implementation of SellerRepository for local development
+// and tests. It applies the same tenant restriction as PGSellerRepository and keeps copies, so
+// callers cannot change stored sellers without UpdateSeller. Data is lost on restart.
+type MemorySellerRepository struct {
+	mu      sync.RWMutex
+	sellers map[string]*model.Seller
+}
+
+// NewMemorySellerRepository creates an empty MemorySellerRepository.
+func NewMemorySellerRepository() *MemorySellerRepository {
+	return &MemorySellerRepository{sellers: map[string]*model.Seller{}}
+}
+
+// CreateSeller stores a copy of the seller. Like the primary key of the sellers table, IDs must be unique.
+func (r *MemorySellerRepository) CreateSeller(ctx context.Context, seller *model.Seller) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.sellers[seller.ID]; exists {
+		return fmt.Errorf("failed to create seller: duplicate ID %s", seller.ID)
+	}
+	stored := *seller
+	r.sellers[seller.ID] = &stored
+	return nil
+}
+
+// CreateSellers stores copies of sellers, or none of them if any ID is taken.
+func (r *MemorySellerRepository) CreateSellers(ctx context.Context, sellers []*model.Seller) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	ids := make(map[string]bool, len(sellers))
+	for _, seller := range sellers {
+		if _, exists := r.sellers[seller.ID]; exists || ids[seller.ID] {
+			return fmt.Errorf("failed to create %d sellers: duplicate ID %s", len(sellers), seller.ID)
+		}
+		ids[seller.ID] = true
+	}
+	for _, seller := range sellers {
+		stored := *seller
+		r.sellers[seller.ID] = &stored
+	}
+	return nil
+}
+
+// GetSellerByID returns a copy of the seller, or nil if there is none the caller may see.
+func (r *MemorySellerRepository) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	seller, ok := r.visible(ctx, id)
+	if !ok {
+		return nil, nil // Seller not found
+	}
+	found := *seller
+	return &found, nil
+}
+
+// UpdateSeller replaces the stored seller with a copy of seller.
+func (r *MemorySellerRepository) UpdateSeller(ctx context.Context, seller *model.Seller) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.visible(ctx, seller.ID); !ok {
+		return fmt.Errorf("seller with ID %s not found for update: %w", seller.ID, model.ErrSellerNotFound)
+	}
+	stored := *seller
+	r.sellers[seller.ID] = &stored
+	return nil
+}
+
+// DeleteSeller removes the seller.
+func (r *MemorySellerRepository) DeleteSeller(ctx context.Context, id string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, ok := r.visible(ctx, id); !ok {
+		return fmt.Errorf("seller with ID %s not found for delete: %w", id, model.ErrSellerNotFound)
+	}
+	delete(r.sellers, id)
+	return nil
+}
+
+// ListSellers returns copies of the sellers the caller may see, ordered by ID, with pagination.
+// Like PGSellerRepository it rejects a negative limit or offset with model.ErrInvalidPagination.
+func (r *MemorySellerRepository) ListSellers(ctx context.Context, limit, offset int) ([]*model.Seller, error) {
+	if limit < 0 || offset < 0 {
+		return nil, fmt.Errorf("failed to list sellers: %w", model.ErrInvalidPagination)
+	}
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	filter := commonAuth.TenantFilterFromContext(ctx)
+	var sellers []*model.Seller
+	for _, seller := range r.sellers {
+		if filter.Allows(seller.BrandID) {
+			found := *seller
+			sellers = append(sellers, &found)
+		}
+	}
+	sort.Slice(sellers, func(i, j int) bool { return sellers[i].ID < sellers[j].ID })
+	if offset >= len(sellers) {
+		return nil, nil
+	}
+	sellers = sellers[offset:]
+	if limit < len(sellers) {
+		sellers = sellers[:limit]
+	}
+	return sellers, nil
+}
+
+// visible returns the stored seller if it exists and belongs to a brand the caller in ctx may act on.
+func (r *MemorySellerRepository) visible(ctx context.Context, id string) (*model.Seller, bool) {
+	seller, ok := r.sellers[id]
+	if !ok || !commonAuth.TenantFilterFromContext(ctx).Allows(seller.BrandID) {
+		return nil, false
+	}
+	return seller, true
+}
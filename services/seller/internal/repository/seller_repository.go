@@ -2,29 +2,47 @@ package repository
 
 import (
 	"context"
-	"database/sql"
+	"errors"
 	"fmt"
+	"strconv"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/logger"
 	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 )
 
 // SellerRepository defines the interface for seller data operations.
+// Sellers of brands outside the caller's tenants behave as if they did not exist.
+// repositorytest.RunSellerRepositoryTests checks that an implementation keeps this contract.
 type SellerRepository interface {
 	CreateSeller(ctx context.Context, seller *model.Seller) error
+	// CreateSellers creates all of sellers or, if any of them cannot be created, none. It is meant for
+	// imports; like CreateSeller, it leaves checking brands against the caller's tenants to the service.
+	CreateSellers(ctx context.Context, sellers []*model.Seller) error
+	// GetSellerByID returns nil and no error if the seller does not exist.
 	GetSellerByID(ctx context.Context, id string) (*model.Seller, error)
+	// UpdateSeller and DeleteSeller return an error wrapping model.ErrSellerNotFound if the seller does not exist.
 	UpdateSeller(ctx context.Context, seller *model.Seller) error
 	DeleteSeller(ctx context.Context, id string) error
+	// ListSellers returns sellers ordered by ID, so limit and offset page through them consistently.
 	ListSellers(ctx context.Context, limit, offset int) ([]*model.Seller, error)
 }
 
-// PGSellerRepository is a PostgreSQL implementation of SellerRepository.
+// PGSellerRepository is a PostgreSQL implementation of SellerRepository on pgx; the databases of
+// its cluster must come from database.NewPgxDB.
+// Reads, updates and deletes only see sellers of the brands the caller in ctx may act on.
+// Reads go to the read replicas of db when it has any (see database.Cluster).
 type PGSellerRepository struct {
-	db *sql.DB
+	db      *database.Cluster
+	queries *database.QueryMetrics
 }
 
-// NewPGSellerRepository creates a new PGSellerRepository.
-func NewPGSellerRepository(db *sql.DB) *PGSellerRepository {
-	return &PGSellerRepository{db: db}
+// NewPGSellerRepository creates a new PGSellerRepository. queries may be nil.
+func NewPGSellerRepository(db *database.Cluster, queries *database.QueryMetrics) *PGSellerRepository {
+	return &PGSellerRepository{db: db, queries: queries}
 }
 
 // CreateSeller inserts a new seller into the database.
@@ -33,22 +51,12 @@ func (r *PGSellerRepository) CreateSeller(ctx context.Context, seller *model.Sel
 	// Example placeholder:
 	query := `INSERT INTO sellers (id, brand_id, status, address, city, state, country, postcode, email, phone_number, latitude, longitude, last_updated_by, last_update_time)
               VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
-	_, err := r.db.ExecContext(ctx, query,
-		seller.ID,
-		seller.BrandID,
-		seller.Status,
-		seller.Address,
-		seller.City,
-		seller.State,
-		seller.Country,
-		seller.Postcode,
-		seller.Email,
-		seller.PhoneNumber,
-		seller.Latitude,
-		seller.Longitude,
-		seller.LastUpdatedBy,
-		seller.LastUpdateTime,
-	)
+	start := time.Now()
+	err := r.db.WithPgxWriter(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, query, sellerValues(seller)...)
+		return err
+	})
+	r.queries.Observe("sellers.create", start, err)
 	if err != nil {
 		// Log or wrap the error appropriately
 		return fmt.Errorf("failed to create seller: %w", err)
@@ -56,33 +64,40 @@ func (r *PGSellerRepository) CreateSeller(ctx context.Context, seller *model.Sel
 	return nil
 }
 
+// CreateSellers inserts sellers with COPY, which is much faster than an INSERT per seller for large imports.
+func (r *PGSellerRepository) CreateSellers(ctx context.Context, sellers []*model.Seller) error {
+	if len(sellers) == 0 {
+		return nil
+	}
+	start := time.Now()
+	err := r.db.WithPgxWriter(ctx, func(conn *pgx.Conn) error {
+		_, err := conn.CopyFrom(ctx, pgx.Identifier{"sellers"}, sellerColumns, pgx.CopyFromSlice(len(sellers), func(i int) ([]any, error) {
+			return sellerValues(sellers[i]), nil
+		}))
+		return err
+	})
+	r.queries.Observe("sellers.create_many", start, err)
+	if err != nil {
+		return fmt.Errorf("failed to create %d sellers: %w", len(sellers), err)
+	}
+	return nil
+}
+
 // GetSellerByID retrieves a seller by their ID.
 func (r *PGSellerRepository) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
 	// In a real implementation, you would execute an SQL SELECT statement here.
 	// Example placeholder:
 	query := `SELECT id, brand_id, status, address, city, state, country, postcode, email, phone_number, latitude, longitude, last_updated_by, last_update_time
               FROM sellers WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
-
+	query, args := tenantClause(ctx, query, id)
+	start := time.Now()
 	seller := &model.Seller{}
-	err := row.Scan(
-		&seller.ID,
-		&seller.BrandID,
-		&seller.Status,
-		&seller.Address,
-		&seller.City,
-		&seller.State,
-		&seller.Country,
-		&seller.Postcode,
-		&seller.Email,
-		&seller.PhoneNumber,
-		&seller.Latitude,
-		&seller.Longitude,
-		&seller.LastUpdatedBy,
-		&seller.LastUpdateTime,
-	)
+	err := r.db.WithPgxReader(ctx, func(conn *pgx.Conn) error {
+		return conn.QueryRow(ctx, query, args...).Scan(sellerFields(seller)...)
+	})
+	r.queries.Observe("sellers.get_by_id", start, err)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // Seller not found
 		}
 		// Log or wrap the error appropriately
@@ -98,31 +113,20 @@ func (r *PGSellerRepository) UpdateSeller(ctx context.Context, seller *model.Sel
 	query := `UPDATE sellers
               SET brand_id = $2, status = $3, address = $4, city = $5, state = $6, country = $7, postcode = $8, email = $9, phone_number = $10, latitude = $11, longitude = $12, last_updated_by = $13, last_update_time = $14
               WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query,
-		seller.ID,
-		seller.BrandID,
-		seller.Status,
-		seller.Address,
-		seller.City,
-		seller.State,
-		seller.Country,
-		seller.Postcode,
-		seller.Email,
-		seller.PhoneNumber,
-		seller.Latitude,
-		seller.Longitude,
-		seller.LastUpdatedBy,
-		seller.LastUpdateTime,
-	)
+	query, args := tenantClause(ctx, query, sellerValues(seller)...)
+	start := time.Now()
+	var rowsAffected int64
+	err := r.db.WithPgxWriter(ctx, func(conn *pgx.Conn) error {
+		tag, err := conn.Exec(ctx, query, args...)
+		rowsAffected = tag.RowsAffected()
+		return err
+	})
+	r.queries.Observe("sellers.update", start, err)
 	if err != nil {
 		return fmt.Errorf("failed to update seller %s: %w", seller.ID, err)
 	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected after update for seller %s: %w", seller.ID, err)
-	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("seller with ID %s not found for update", seller.ID) // Or a specific "not found" error
+		return fmt.Errorf("seller with ID %s not found for update: %w", seller.ID, model.ErrSellerNotFound)
 	}
 	return nil
 }
@@ -131,64 +135,120 @@ func (r *PGSellerRepository) UpdateSeller(ctx context.Context, seller *model.Sel
 func (r *PGSellerRepository) DeleteSeller(ctx context.Context, id string) error {
 	// In a real implementation, you would execute an SQL DELETE statement here.
 	// Example placeholder:
-	query := `DELETE FROM sellers WHERE id = $1`
-	result, err := r.db.ExecContext(ctx, query, id)
+	query, args := tenantClause(ctx, `DELETE FROM sellers WHERE id = $1`, id)
+	start := time.Now()
+	var rowsAffected int64
+	err := r.db.WithPgxWriter(ctx, func(conn *pgx.Conn) error {
+		tag, err := conn.Exec(ctx, query, args...)
+		rowsAffected = tag.RowsAffected()
+		return err
+	})
+	r.queries.Observe("sellers.delete", start, err)
 	if err != nil {
 		return fmt.Errorf("failed to delete seller %s: %w", id, err)
 	}
-	rowsAffected, err := result.RowsAffected()
-	if err != nil {
-		return fmt.Errorf("failed to get rows affected after delete for seller %s: %w", id, err)
-	}
 	if rowsAffected == 0 {
-		return fmt.Errorf("seller with ID %s not found for delete", id) // Or a specific "not found" error
+		return fmt.Errorf("seller with ID %s not found for delete: %w", id, model.ErrSellerNotFound)
 	}
 	return nil
 }
 
-// ListSellers retrieves a list of sellers with pagination.
+// ListSellers retrieves a list of sellers ordered by ID, with pagination.
 func (r *PGSellerRepository) ListSellers(ctx context.Context, limit, offset int) ([]*model.Seller, error) {
+	if limit < 0 || offset < 0 { // Postgres rejects them too, but with an error callers cannot match
+		return nil, fmt.Errorf("failed to list sellers: %w", model.ErrInvalidPagination)
+	}
 	// In a real implementation, you would execute an SQL SELECT statement here.
 	// Example placeholder:
 	query := `SELECT id, brand_id, status, address, city, state, country, postcode, email, phone_number, latitude, longitude, last_updated_by, last_update_time
-              FROM sellers LIMIT $1 OFFSET $2`
-	rows, err := r.db.QueryContext(ctx, query, limit, offset)
-	if err != nil {
-		return nil, fmt.Errorf("failed to list sellers: %w", err)
+              FROM sellers`
+	args := []interface{}{limit, offset}
+	if filter := commonAuth.TenantFilterFromContext(ctx); !filter.All {
+		query += ` WHERE brand_id = ANY($3)`
+		args = append(args, filter.Brands)
 	}
-	defer rows.Close()
-
+	query += ` ORDER BY id LIMIT $1 OFFSET $2`
+	start := time.Now()
 	var sellers []*model.Seller
-	for rows.Next() {
-		seller := &model.Seller{}
-		err := rows.Scan(
-			&seller.ID,
-			&seller.BrandID,
-			&seller.Status,
-			&seller.Address,
-			&seller.City,
-			&seller.State,
-			&seller.Country,
-			&seller.Postcode,
-			&seller.Email,
-			&seller.PhoneNumber,
-			&seller.Latitude,
-			&seller.Longitude,
-			&seller.LastUpdatedBy,
-			&seller.LastUpdateTime,
-		)
+	err := r.db.WithPgxReader(ctx, func(conn *pgx.Conn) error {
+		rows, err := conn.Query(ctx, query, args...)
 		if err != nil {
-			// Log the scanning error but continue processing other rows if possible,
-			// or return the error depending on desired behavior.
-			fmt.Printf("Error scanning seller row: %v\n", err)
-			continue
+			return fmt.Errorf("failed to list sellers: %w", err)
 		}
-		sellers = append(sellers, seller)
-	}
+		defer rows.Close()
 
-	if err = rows.Err(); err != nil {
-		return nil, fmt.Errorf("error after iterating through seller rows: %w", err)
+		for rows.Next() {
+			seller := &model.Seller{}
+			if err := rows.Scan(sellerFields(seller)...); err != nil {
+				// Log the scanning error but continue processing other rows if possible,
+				// or return the error depending on desired behavior.
+				logger.FromContext(ctx, nil).Error(err, "Error scanning seller row")
+				continue
+			}
+			sellers = append(sellers, seller)
+		}
+		if err := rows.Err(); err != nil {
+			return fmt.Errorf("error after iterating through seller rows: %w", err)
+		}
+		return nil
+	})
+	r.queries.Observe("sellers.list", start, err) // Includes reading the rows
+	if err != nil {
+		return nil, err
 	}
 
 	return sellers, nil
-}
\ No newline at end of file
+}
+
+// sellerColumns are the columns of the sellers table, in the order of sellerValues and sellerFields.
+var sellerColumns = []string{"id", "brand_id", "status", "address", "city", "state", "country", "postcode", "email", "phone_number", "latitude", "longitude", "last_updated_by", "last_update_time"}
+
+// sellerValues returns the values of seller's columns.
+func sellerValues(seller *model.Seller) []any {
+	return []any{
+		seller.ID,
+		seller.BrandID,
+		seller.Status,
+		seller.Address,
+		seller.City,
+		seller.State,
+		seller.Country,
+		seller.Postcode,
+		seller.Email,
+		seller.PhoneNumber,
+		seller.Latitude,
+		seller.Longitude,
+		seller.LastUpdatedBy,
+		seller.LastUpdateTime,
+	}
+}
+
+// sellerFields returns pointers to seller's fields, to scan a row into.
+func sellerFields(seller *model.Seller) []any {
+	return []any{
+		&seller.ID,
+		&seller.BrandID,
+		&seller.Status,
+		&seller.Address,
+		&seller.City,
+		&seller.State,
+		&seller.Country,
+		&seller.Postcode,
+		&seller.Email,
+		&seller.PhoneNumber,
+		&seller.Latitude,
+		&seller.Longitude,
+		&seller.LastUpdatedBy,
+		&seller.LastUpdateTime,
+	}
+}
+
+// tenantClause appends the caller's brand restriction to a query ending in a WHERE condition.
+func tenantClause(ctx context.Context, query string, args ...interface{}) (string, []interface{}) {
+	filter := commonAuth.TenantFilterFromContext(ctx)
+	if filter.All {
+		return query, args
+	}
+	args = append(args, filter.Brands)
+	return query + " AND brand_id = ANY($" + strconv.Itoa(len(args)) + ")", args
+}
@@ -0,0 +1,16 @@
+package service
+
+// Domain events the seller service records in its outbox, with the seller (or, for deletes, its ID)
+// as payload.
+const (
+	sellerAggregate = "seller"
+
+	EventSellerCreated = "seller.created"
+	EventSellerUpdated = "seller.updated"
+	EventSellerDeleted = "seller.deleted"
+)
+
+// sellerDeleted is the payload of EventSellerDeleted.
+type sellerDeleted struct {
+	ID string `json:"id"`
+}
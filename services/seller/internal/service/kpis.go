@@ -0,0 +1,37 @@
+package service
+
+import "github.com/omni-compos/digital-mono/libs/metrics"
+
+// Business KPI labels. Brand IDs and statuses are validated against model.ValidBrandIDs and
+// model.ValidStatuses before they are counted, so the label sets stay small.
+type sellerLabels struct {
+	BrandID string `label:"brand_id"`
+	Status  string `label:"status"`
+}
+
+type statusTransitionLabels struct {
+	BrandID string `label:"brand_id"`
+	From    string `label:"from"`
+	To      string `label:"to"`
+}
+
+type brandLabels struct {
+	BrandID string `label:"brand_id"`
+}
+
+// sellerKPIs are the business metrics of the seller service.
+type sellerKPIs struct {
+	created           *metrics.Counter[sellerLabels]
+	updated           *metrics.Counter[sellerLabels]
+	statusTransitions *metrics.Counter[statusTransitionLabels]
+	geocodingFailures *metrics.Counter[brandLabels]
+}
+
+func newSellerKPIs(kpis *metrics.BusinessMetrics) sellerKPIs {
+	return sellerKPIs{
+		created:           metrics.NewCounter[sellerLabels](kpis, "sellers_created_total", "Sellers created by brand and status."),
+		updated:           metrics.NewCounter[sellerLabels](kpis, "sellers_updated_total", "Sellers updated by brand and resulting status."),
+		statusTransitions: metrics.NewCounter[statusTransitionLabels](kpis, "seller_status_transitions_total", "Seller status changes by brand, previous and new status."),
+		geocodingFailures: metrics.NewCounter[brandLabels](kpis, "seller_geocoding_failures_total", "Seller creates and updates that failed to geocode the address, by brand."),
+	}
+}
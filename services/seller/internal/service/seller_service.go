@@ -6,16 +6,25 @@ import (
 	"time"
 
 	"github.com/google/uuid"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/database"
 	"github.com/omni-compos/digital-mono/libs/localization"
 	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/libs/tracing"
 
 	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 	"github.com/omni-compos/digital-mono/services/seller/internal/repository"
 )
 
 // SellerService defines the interface for seller business logic.
+// Every operation is limited to the brands of the caller in ctx (auth.Claims.Brands);
+// sellers of other brands behave as if they did not exist.
 type SellerService interface {
 	CreateSeller(ctx context.Context, seller *model.Seller, userID string) (*model.Seller, error)
+	// ImportSellers creates sellers in bulk, all of them or none, e.g. when moving sellers over from
+	// another system. Sellers without coordinates are geocoded.
+	ImportSellers(ctx context.Context, sellers []*model.Seller, userID string) ([]*model.Seller, error)
 	GetSellerByID(ctx context.Context, id string) (*model.Seller, error)
 	UpdateSeller(ctx context.Context, id string, updates *model.Seller, userID string) (*model.Seller, error)
 	DeleteSeller(ctx context.Context, id string) error
@@ -27,19 +36,33 @@ type DefaultSellerService struct {
 	repo              repository.SellerRepository
 	localization	  localization.LocationalisationService
 	logger            logger.Logger
+	kpis              sellerKPIs
+	tx                database.Transactor
+	outbox            *database.Outbox
 }
 
-// NewProductService creates a new DefaultSellerService.
-func NewSellerService(repo repository.SellerRepository, localization localization.LocationalisationService, logger logger.Logger) *DefaultSellerService {
+// NewSellerService creates a new DefaultSellerService.
+// Business metrics are declared on kpis; it may be nil. Every change and its domain event in
+// outbox are written in one transaction of tx; a nil tx runs them without one, a nil outbox
+// records no events.
+func NewSellerService(repo repository.SellerRepository, localization localization.LocationalisationService, logger logger.Logger, kpis *metrics.BusinessMetrics, tx database.Transactor, outbox *database.Outbox) *DefaultSellerService {
+	if tx == nil {
+		tx = database.NopTransactor{}
+	}
 	return &DefaultSellerService{
 		repo:              repo,
 		localization: 	   localization,
 		logger:            logger,
+		kpis:              newSellerKPIs(kpis),
+		tx:                tx,
+		outbox:            outbox,
 	}
 }
 
 // CreateSeller handles the creation of a new seller.
 func (s *DefaultSellerService) CreateSeller(ctx context.Context, seller *model.Seller, userID string) (*model.Seller, error) {
+	ctx, span := tracing.Start(ctx, "SellerService.CreateSeller")
+	defer span.End()
 	// Validate static lists
 	if !isValidBrandID(seller.BrandID) {
 		return nil, fmt.Errorf("invalid brand ID: %s", seller.BrandID)
@@ -47,42 +70,118 @@ func (s *DefaultSellerService) CreateSeller(ctx context.Context, seller *model.S
 	if !isValidStatus(seller.Status) {
 		return nil, fmt.Errorf("invalid status: %s", seller.Status)
 	}
+	if !commonAuth.TenantFilterFromContext(ctx).Allows(seller.BrandID) {
+		return nil, fmt.Errorf("cannot create seller for brand %s: %w", seller.BrandID, model.ErrBrandNotAllowed)
+	}
 
 	// Get Lat/Lng from address using locationalisation service
 	lat, lng, err := s.localization.GetLatLngFromAddress(ctx, seller.Address, seller.City, seller.State, seller.Country, seller.Postcode)
 	if err != nil {
-		s.logger.Error(err, "Failed to get lat/lng for seller", "address", seller.Address)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to get lat/lng for seller", "address", seller.Address)
+		s.kpis.geocodingFailures.Inc(brandLabels{BrandID: seller.BrandID})
 		// Decide if this should be a hard error or if you proceed without coords
 		// For now, let's return an error
 		return nil, fmt.Errorf("failed to geocode address: %w", err)
 	}
 	seller.Latitude = lat
 	seller.Longitude = lng
+	logger.FromContext(ctx, s.logger).Debug("Geocoded seller address", "latitude", lat, "longitude", lng)
 
 	// Set audit fields
 	seller.ID = uuid.New().String() // Generate a new UUID for the seller
 	seller.LastUpdatedBy = userID
 	seller.LastUpdateTime = time.Now()
 
-	// Save to repository
-	err = s.repo.CreateSeller(ctx, seller)
+	// Save to repository, together with the event
+	err = s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateSeller(ctx, seller); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, sellerAggregate, seller.ID, EventSellerCreated, seller)
+	})
 	if err != nil {
-		s.logger.Error(err, "Failed to create seller in repository")
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to create seller in repository")
 		return nil, fmt.Errorf("failed to save seller: %w", err)
 	}
 
-	s.logger.Info("Seller created successfully", "seller_id", seller.ID, "updated_by", userID)
+	logger.FromContext(ctx, s.logger).Info("Seller created successfully", "seller_id", seller.ID, "updated_by", userID)
+	s.kpis.created.Inc(sellerLabels{BrandID: seller.BrandID, Status: seller.Status})
 	return seller, nil
 }
 
+// MaxImportSize is the most sellers ImportSellers accepts at once.
+const MaxImportSize = 10000
+
+// ImportSellers validates every seller, including its brand against the caller's tenants, before
+// writing any. The sellers and their events are written in one transaction.
+func (s *DefaultSellerService) ImportSellers(ctx context.Context, sellers []*model.Seller, userID string) ([]*model.Seller, error) {
+	ctx, span := tracing.Start(ctx, "SellerService.ImportSellers")
+	defer span.End()
+	if len(sellers) == 0 || len(sellers) > MaxImportSize {
+		return nil, fmt.Errorf("an import holds 1 to %d sellers, got %d: %w", MaxImportSize, len(sellers), model.ErrInvalidSeller)
+	}
+	tenants := commonAuth.TenantFilterFromContext(ctx)
+	for i, seller := range sellers {
+		if !isValidBrandID(seller.BrandID) {
+			return nil, fmt.Errorf("seller %d: invalid brand ID %s: %w", i, seller.BrandID, model.ErrInvalidSeller)
+		}
+		if !isValidStatus(seller.Status) {
+			return nil, fmt.Errorf("seller %d: invalid status %s: %w", i, seller.Status, model.ErrInvalidSeller)
+		}
+		if !tenants.Allows(seller.BrandID) {
+			return nil, fmt.Errorf("seller %d: cannot create seller for brand %s: %w", i, seller.BrandID, model.ErrBrandNotAllowed)
+		}
+	}
+
+	now := time.Now()
+	events := make([]database.NewEvent, len(sellers))
+	for i, seller := range sellers {
+		// Imports usually carry coordinates; geocoding thousands of addresses would take long
+		if seller.Latitude == 0 && seller.Longitude == 0 {
+			lat, lng, err := s.localization.GetLatLngFromAddress(ctx, seller.Address, seller.City, seller.State, seller.Country, seller.Postcode)
+			if err != nil {
+				logger.FromContext(ctx, s.logger).Error(err, "Failed to get lat/lng for imported seller", "index", i, "address", seller.Address)
+				s.kpis.geocodingFailures.Inc(brandLabels{BrandID: seller.BrandID})
+				return nil, fmt.Errorf("seller %d: failed to geocode address: %w", i, err)
+			}
+			seller.Latitude = lat
+			seller.Longitude = lng
+		}
+		seller.ID = uuid.New().String()
+		seller.LastUpdatedBy = userID
+		seller.LastUpdateTime = now
+		events[i] = database.NewEvent{AggregateType: sellerAggregate, AggregateID: seller.ID, Type: EventSellerCreated, Payload: seller}
+	}
+
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateSellers(ctx, sellers); err != nil {
+			return err
+		}
+		return s.outbox.AddAll(ctx, events)
+	})
+	if err != nil {
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to import sellers in repository", "count", len(sellers))
+		return nil, fmt.Errorf("failed to save sellers: %w", err)
+	}
+
+	logger.FromContext(ctx, s.logger).Info("Sellers imported successfully", "count", len(sellers), "updated_by", userID)
+	for _, seller := range sellers {
+		s.kpis.created.Inc(sellerLabels{BrandID: seller.BrandID, Status: seller.Status})
+	}
+	return sellers, nil
+}
+
 // GetSellerByID retrieves a seller by ID.
 func (s *DefaultSellerService) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
+	ctx, span := tracing.Start(ctx, "SellerService.GetSellerByID")
+	defer span.End()
 	seller, err := s.repo.GetSellerByID(ctx, id)
 	if err != nil {
-		s.logger.Error(err, "Failed to get seller by ID from repository", "seller_id", id)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to get seller by ID from repository", "seller_id", id)
 		return nil, fmt.Errorf("failed to retrieve seller: %w", err)
 	}
-	if seller == nil {
+	if seller == nil || !commonAuth.TenantFilterFromContext(ctx).Allows(seller.BrandID) {
+		logger.FromContext(ctx, s.logger).Debug("Seller not found or outside caller's brands", "seller_id", id)
 		return nil, nil // Seller not found
 	}
 	return seller, nil
@@ -90,26 +189,56 @@ func (s *DefaultSellerService) GetSellerByID(ctx context.Context, id string) (*m
 
 // UpdateSeller handles updating an existing seller.
 func (s *DefaultSellerService) UpdateSeller(ctx context.Context, id string, updates *model.Seller, userID string) (*model.Seller, error) {
+	ctx, span := tracing.Start(ctx, "SellerService.UpdateSeller")
+	defer span.End()
+
+	// Read, modify and write in one transaction, so concurrent updates cannot overwrite each other
+	var existingSeller *model.Seller
+	var previousStatus string
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		existingSeller, previousStatus, err = s.updateSeller(ctx, id, updates, userID)
+		return err
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	logger.FromContext(ctx, s.logger).Info("Seller updated successfully", "seller_id", id, "updated_by", userID)
+	s.kpis.updated.Inc(sellerLabels{BrandID: existingSeller.BrandID, Status: existingSeller.Status})
+	if existingSeller.Status != previousStatus {
+		s.kpis.statusTransitions.Inc(statusTransitionLabels{BrandID: existingSeller.BrandID, From: previousStatus, To: existingSeller.Status})
+	}
+	return existingSeller, nil
+}
+
+// updateSeller applies updates to the stored seller and saves it, returning it and its previous status.
+func (s *DefaultSellerService) updateSeller(ctx context.Context, id string, updates *model.Seller, userID string) (*model.Seller, string, error) {
 	existingSeller, err := s.repo.GetSellerByID(ctx, id)
 	if err != nil {
-		s.logger.Error(err, "Failed to get existing seller for update", "seller_id", id)
-		return nil, fmt.Errorf("failed to retrieve seller for update: %w", err)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to get existing seller for update", "seller_id", id)
+		return nil, "", fmt.Errorf("failed to retrieve seller for update: %w", err)
 	}
-	if existingSeller == nil {
-		return nil, fmt.Errorf("seller with ID %s not found", id)
+	tenants := commonAuth.TenantFilterFromContext(ctx)
+	if existingSeller == nil || !tenants.Allows(existingSeller.BrandID) {
+		return nil, "", fmt.Errorf("seller with ID %s not found: %w", id, model.ErrSellerNotFound)
 	}
+	previousStatus := existingSeller.Status
 
 	// Apply updates (only fields that are allowed to be updated)
 	// This is a simplified approach; a real implementation might merge fields carefully
 	if updates.BrandID != "" {
 		if !isValidBrandID(updates.BrandID) {
-			return nil, fmt.Errorf("invalid brand ID: %s", updates.BrandID)
+			return nil, "", fmt.Errorf("invalid brand ID: %s", updates.BrandID)
+		}
+		if !tenants.Allows(updates.BrandID) {
+			return nil, "", fmt.Errorf("cannot move seller %s to brand %s: %w", id, updates.BrandID, model.ErrBrandNotAllowed)
 		}
 		existingSeller.BrandID = updates.BrandID
 	}
 	if updates.Status != "" {
 		if !isValidStatus(updates.Status) {
-			return nil, fmt.Errorf("invalid status: %s", updates.Status)
+			return nil, "", fmt.Errorf("invalid status: %s", updates.Status)
 		}
 		existingSeller.Status = updates.Status
 	}
@@ -139,10 +268,11 @@ func (s *DefaultSellerService) UpdateSeller(ctx context.Context, id string, upda
 	// A more robust check would compare old vs new address fields
 	lat, lng, err := s.localization.GetLatLngFromAddress(ctx, existingSeller.Address, existingSeller.City, existingSeller.State, existingSeller.Country, existingSeller.Postcode)
 	if err != nil {
-		s.logger.Error(err, "Failed to re-geocode address for seller update", "seller_id", id)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to re-geocode address for seller update", "seller_id", id)
+		s.kpis.geocodingFailures.Inc(brandLabels{BrandID: existingSeller.BrandID})
 		// Decide if this should block the update or just skip updating coords
 		// For now, let's return an error
-		return nil, fmt.Errorf("failed to geocode address for update: %w", err)
+		return nil, "", fmt.Errorf("failed to geocode address for update: %w", err)
 	}
 	existingSeller.Latitude = lat
 	existingSeller.Longitude = lng
@@ -154,32 +284,44 @@ func (s *DefaultSellerService) UpdateSeller(ctx context.Context, id string, upda
 	// Save updates to repository
 	err = s.repo.UpdateSeller(ctx, existingSeller)
 	if err != nil {
-		s.logger.Error(err, "Failed to update seller in repository", "seller_id", id)
-		return nil, fmt.Errorf("failed to save seller updates: %w", err)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to update seller in repository", "seller_id", id)
+		return nil, "", fmt.Errorf("failed to save seller updates: %w", err)
+	}
+	if err := s.outbox.Add(ctx, sellerAggregate, existingSeller.ID, EventSellerUpdated, existingSeller); err != nil {
+		return nil, "", err
 	}
 
-	s.logger.Info("Seller updated successfully", "seller_id", id, "updated_by", userID)
-	return existingSeller, nil
+	return existingSeller, previousStatus, nil
 }
 
 // DeleteSeller handles deleting a seller.
 func (s *DefaultSellerService) DeleteSeller(ctx context.Context, id string) error {
-	err := s.repo.DeleteSeller(ctx, id)
+	ctx, span := tracing.Start(ctx, "SellerService.DeleteSeller")
+	defer span.End()
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.DeleteSeller(ctx, id); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, sellerAggregate, id, EventSellerDeleted, sellerDeleted{ID: id})
+	})
 	if err != nil {
-		s.logger.Error(err, "Failed to delete seller from repository", "seller_id", id)
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to delete seller from repository", "seller_id", id)
 		return fmt.Errorf("failed to delete seller: %w", err)
 	}
-	s.logger.Info("Seller deleted successfully", "seller_id", id)
+	logger.FromContext(ctx, s.logger).Info("Seller deleted successfully", "seller_id", id)
 	return nil
 }
 
 // ListSellers handles retrieving a list of sellers.
 func (s *DefaultSellerService) ListSellers(ctx context.Context, limit, offset int) ([]*model.Seller, error) {
+	ctx, span := tracing.Start(ctx, "SellerService.ListSellers")
+	defer span.End()
 	sellers, err := s.repo.ListSellers(ctx, limit, offset)
 	if err != nil {
-		s.logger.Error(err, "Failed to list sellers from repository")
+		logger.FromContext(ctx, s.logger).Error(err, "Failed to list sellers from repository")
 		return nil, fmt.Errorf("failed to list sellers: %w", err)
 	}
+	logger.FromContext(ctx, s.logger).Debug("Listed sellers", "count", len(sellers), "limit", limit, "offset", offset)
 	return sellers, nil
 }
 
@@ -0,0 +1,193 @@
+// Package benchmark compares the pgx seller repository with the database/sql path it replaced
+// (lib/pq, one statement per seller). It runs against the database in TEST_DATABASE_DSN and is
+// skipped without it:
+//
+//	TEST_DATABASE_DSN=... go test -run '^$' -bench . -benchmem ./tests/benchmark
+package benchmark
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/jackc/pgx/v5"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
+	"github.com/omni-compos/digital-mono/services/seller/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/seller/internal/repository"
+)
+
+// importSize is the number of sellers per import.
+const importSize = 1000
+
+const (
+	insertSeller = `INSERT INTO sellers (id, brand_id, status, address, city, state, country, postcode, email, phone_number, latitude, longitude, last_updated_by, last_update_time)
+              VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)`
+	selectSeller = `SELECT id, brand_id, status, address, city, state, country, postcode, email, phone_number, latitude, longitude, last_updated_by, last_update_time
+              FROM sellers WHERE id = $1`
+)
+
+func BenchmarkCreateSeller(b *testing.B) {
+	ctx := commonAuth.WithSystemTenant(context.Background())
+	pgxDB, sqlDB := openDBs(b)
+
+	b.Run("database_sql", func(b *testing.B) {
+		databasetest.Truncate(b, sqlDB, "sellers")
+		for i := 0; i < b.N; i++ {
+			if _, err := sqlDB.ExecContext(ctx, insertSeller, sellerArgs(newSeller(i))...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pgx", func(b *testing.B) {
+		databasetest.Truncate(b, pgxDB, "sellers")
+		repo := newRepo(pgxDB)
+		for i := 0; i < b.N; i++ {
+			if err := repo.CreateSeller(ctx, newSeller(i)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+func BenchmarkGetSellerByID(b *testing.B) {
+	ctx := commonAuth.WithSystemTenant(context.Background())
+	pgxDB, sqlDB := openDBs(b)
+	databasetest.Truncate(b, pgxDB, "sellers")
+	repo := newRepo(pgxDB)
+	if err := repo.CreateSellers(ctx, newSellers(0, importSize)); err != nil {
+		b.Fatal(err)
+	}
+
+	b.Run("database_sql", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			seller := &model.Seller{}
+			if err := sqlDB.QueryRowContext(ctx, selectSeller, sellerID(i%importSize)).Scan(sellerFields(seller)...); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+
+	b.Run("pgx", func(b *testing.B) {
+		for i := 0; i < b.N; i++ {
+			if _, err := repo.GetSellerByID(ctx, sellerID(i%importSize)); err != nil {
+				b.Fatal(err)
+			}
+		}
+	})
+}
+
+// BenchmarkImportSellers inserts importSize sellers per operation in one transaction.
+func BenchmarkImportSellers(b *testing.B) {
+	ctx := commonAuth.WithSystemTenant(context.Background())
+	pgxDB, sqlDB := openDBs(b)
+
+	b.Run("database_sql_inserts", func(b *testing.B) {
+		tx := commonDB.NewTxManager(sqlDB, commonDB.TxOptions{})
+		benchmarkImport(b, sqlDB, func(sellers []*model.Seller) error {
+			return tx.WithinTx(ctx, func(ctx context.Context) error {
+				for _, seller := range sellers {
+					if _, err := commonDB.Executor(ctx, sqlDB).ExecContext(ctx, insertSeller, sellerArgs(seller)...); err != nil {
+						return err
+					}
+				}
+				return nil
+			})
+		})
+	})
+
+	b.Run("pgx_batch", func(b *testing.B) {
+		tx := commonDB.NewTxManager(pgxDB, commonDB.TxOptions{})
+		benchmarkImport(b, pgxDB, func(sellers []*model.Seller) error {
+			return tx.WithinTx(ctx, func(ctx context.Context) error {
+				return commonDB.WithPgx(ctx, pgxDB, func(conn *pgx.Conn) error {
+					batch := &pgx.Batch{}
+					for _, seller := range sellers {
+						batch.Queue(insertSeller, sellerArgs(seller)...)
+					}
+					return conn.SendBatch(ctx, batch).Close()
+				})
+			})
+		})
+	})
+
+	b.Run("pgx_copy", func(b *testing.B) {
+		repo := newRepo(pgxDB)
+		benchmarkImport(b, pgxDB, func(sellers []*model.Seller) error {
+			return repo.CreateSellers(ctx, sellers)
+		})
+	})
+}
+
+func benchmarkImport(b *testing.B, db *sql.DB, insert func(sellers []*model.Seller) error) {
+	for i := 0; i < b.N; i++ {
+		b.StopTimer()
+		databasetest.Truncate(b, db, "sellers")
+		sellers := newSellers(0, importSize)
+		b.StartTimer()
+		if err := insert(sellers); err != nil {
+			b.Fatal(err)
+		}
+	}
+	b.ReportMetric(float64(b.N*importSize)/b.Elapsed().Seconds(), "sellers/s")
+}
+
+// openDBs returns the migrated test database opened through pgx and through lib/pq.
+func openDBs(b *testing.B) (pgxDB, sqlDB *sql.DB) {
+	pgxDB = databasetest.Open(b, migrations.FS, migrations.Table)
+	sqlDB, err := commonDB.NewPostgresDB(context.Background(), databasetest.Config(b))
+	if err != nil {
+		b.Fatalf("failed to connect to test database: %v", err)
+	}
+	b.Cleanup(func() { sqlDB.Close() })
+	return pgxDB, sqlDB
+}
+
+func newRepo(db *sql.DB) *repository.PGSellerRepository {
+	return repository.NewPGSellerRepository(commonDB.NewCluster(db, nil, commonDB.ClusterConfig{}), nil)
+}
+
+func newSellers(from, n int) []*model.Seller {
+	sellers := make([]*model.Seller, n)
+	for i := range sellers {
+		sellers[i] = newSeller(from + i)
+	}
+	return sellers
+}
+
+func newSeller(i int) *model.Seller {
+	seller := model.NewSeller()
+	seller.ID = sellerID(i)
+	seller.BrandID = model.BrandIDBrandA
+	seller.Status = model.StatusPending
+	seller.Address = fmt.Sprintf("%d George St", i)
+	seller.City = "Sydney"
+	seller.State = "NSW"
+	seller.Postcode = "2000"
+	seller.Email = fmt.Sprintf("seller-%d@example.com", i)
+	seller.PhoneNumber = "0400000000"
+	seller.Latitude = -33.8688
+	seller.Longitude = 151.2093
+	seller.LastUpdatedBy = "user-1"
+	seller.LastUpdateTime = time.Now()
+	return seller
+}
+
+func sellerID(i int) string {
+	return fmt.Sprintf("seller-%08d", i)
+}
+
+func sellerArgs(seller *model.Seller) []any {
+	return []any{seller.ID, seller.BrandID, seller.Status, seller.Address, seller.City, seller.State, seller.Country, seller.Postcode,
+		seller.Email, seller.PhoneNumber, seller.Latitude, seller.Longitude, seller.LastUpdatedBy, seller.LastUpdateTime}
+}
+
+func sellerFields(seller *model.Seller) []any {
+	return []any{&seller.ID, &seller.BrandID, &seller.Status, &seller.Address, &seller.City, &seller.State, &seller.Country, &seller.Postcode,
+		&seller.Email, &seller.PhoneNumber, &seller.Latitude, &seller.Longitude, &seller.LastUpdatedBy, &seller.LastUpdateTime}
+}
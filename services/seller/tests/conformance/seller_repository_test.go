@@ -0,0 +1,26 @@
+package conformance
+
+import (
+	"testing"
+
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/services/seller/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/seller/internal/repository"
+	"github.com/omni-compos/digital-mono/services/seller/internal/repository/repositorytest"
+)
+
+func TestMemorySellerRepository(t *testing.T) {
+	repositorytest.RunSellerRepositoryTests(t, func(t *testing.T) repository.SellerRepository {
+		return repository.NewMemorySellerRepository()
+	})
+}
+
+// TestPGSellerRepository runs against the database in TEST_DATABASE_DSN and is skipped without it.
+func TestPGSellerRepository(t *testing.T) {
+	repositorytest.RunSellerRepositoryTests(t, func(t *testing.T) repository.SellerRepository {
+		db := databasetest.Open(t, migrations.FS, migrations.Table)
+		databasetest.Truncate(t, db, "sellers")
+		return repository.NewPGSellerRepository(commonDB.NewCluster(db, nil, commonDB.ClusterConfig{}), nil)
+	})
+}
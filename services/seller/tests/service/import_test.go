@@ -0,0 +1,142 @@
+package service_test
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
+	"github.com/omni-compos/digital-mono/services/seller/internal/handler/rest"
+	"github.com/omni-compos/digital-mono/services/seller/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/seller/internal/repository"
+	"github.com/omni-compos/digital-mono/services/seller/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedGeocoder returns the same coordinates for every address and counts the calls.
+type fixedGeocoder struct {
+	calls int
+}
+
+func (g *fixedGeocoder) GetLatLngFromAddress(ctx context.Context, address, city, state, country, postcode string) (float64, float64, error) {
+	g.calls++
+	return -33.8688, 151.2093, nil
+}
+
+func callerWithBrands(brands ...string) context.Context {
+	return context.WithValue(context.Background(), commonAuth.ClaimsContextKey, &commonAuth.Claims{UserID: "user-1", Brands: brands})
+}
+
+func importSeller(brandID string, lat, lng float64) *model.Seller {
+	return &model.Seller{BrandID: brandID, Status: model.StatusPending, Address: "1 George St", City: "Sydney", State: "NSW", Country: "AUS",
+		Postcode: "2000", Email: "seller@example.com", PhoneNumber: "0400000000", Latitude: lat, Longitude: lng}
+}
+
+func TestImportSellers_CreatesAllAndGeocodesOnlyMissingCoordinates(t *testing.T) {
+	repo := repository.NewMemorySellerRepository()
+	geocoder := &fixedGeocoder{}
+	svc := service.NewSellerService(repo, geocoder, logger.NewStdLogger(), nil, nil, nil)
+	ctx := callerWithBrands(model.BrandIDBrandA, model.BrandIDBrandB)
+
+	imported, err := svc.ImportSellers(ctx, []*model.Seller{importSeller(model.BrandIDBrandA, -37.8136, 144.9631), importSeller(model.BrandIDBrandB, 0, 0)}, "user-1")
+	require.NoError(t, err)
+	require.Len(t, imported, 2)
+	assert.Equal(t, 1, geocoder.calls, "only the seller without coordinates is geocoded")
+	assert.Equal(t, -37.8136, imported[0].Latitude)
+	assert.Equal(t, -33.8688, imported[1].Latitude)
+
+	for _, seller := range imported {
+		assert.NotEmpty(t, seller.ID)
+		assert.Equal(t, "user-1", seller.LastUpdatedBy)
+		stored, err := repo.GetSellerByID(ctx, seller.ID)
+		require.NoError(t, err)
+		assert.NotNil(t, stored)
+	}
+}
+
+func TestImportSellers_RejectsBrandOutsideCallerTenants(t *testing.T) {
+	repo := repository.NewMemorySellerRepository()
+	svc := service.NewSellerService(repo, &fixedGeocoder{}, logger.NewStdLogger(), nil, nil, nil)
+	ctx := callerWithBrands(model.BrandIDBrandA)
+
+	_, err := svc.ImportSellers(ctx, []*model.Seller{importSeller(model.BrandIDBrandA, 1, 1), importSeller(model.BrandIDBrandB, 1, 1)}, "user-1")
+	assert.ErrorIs(t, err, model.ErrBrandNotAllowed)
+
+	stored, err := repo.ListSellers(callerWithBrands(commonAuth.AllTenants), 10, 0)
+	require.NoError(t, err)
+	assert.Empty(t, stored, "a rejected import must not store any seller")
+}
+
+func TestImportSellers_RejectsInvalidSellers(t *testing.T) {
+	svc := service.NewSellerService(repository.NewMemorySellerRepository(), &fixedGeocoder{}, logger.NewStdLogger(), nil, nil, nil)
+	ctx := callerWithBrands(commonAuth.AllTenants)
+
+	invalidStatus := importSeller(model.BrandIDBrandA, 1, 1)
+	invalidStatus.Status = "UNKNOWN"
+	for name, sellers := range map[string][]*model.Seller{
+		"empty":          nil,
+		"unknown brand":  {importSeller("BRAND_Z", 1, 1)},
+		"unknown status": {invalidStatus},
+	} {
+		t.Run(name, func(t *testing.T) {
+			_, err := svc.ImportSellers(ctx, sellers, "user-1")
+			assert.ErrorIs(t, err, model.ErrInvalidSeller)
+		})
+	}
+}
+
+func TestImportSellersEndpoint(t *testing.T) {
+	svc := service.NewSellerService(repository.NewMemorySellerRepository(), &fixedGeocoder{}, logger.NewStdLogger(), nil, nil, nil)
+	handler := rest.NewSellerRESTHandler(svc, logger.NewStdLogger(), commonAuth.DefaultPolicy())
+
+	for name, tc := range map[string]struct {
+		body string
+		want int
+	}{
+		"created":      {`[{"brandId":"BRAND_A","status":"PENDING","address":"1 George St","latitude":1,"longitude":1}]`, http.StatusCreated},
+		"other brand":  {`[{"brandId":"BRAND_B","status":"PENDING","address":"1 George St","latitude":1,"longitude":1}]`, http.StatusForbidden},
+		"invalid":      {`[{"brandId":"BRAND_A","status":"UNKNOWN"}]`, http.StatusBadRequest},
+		"not an array": {`{"brandId":"BRAND_A"}`, http.StatusBadRequest},
+	} {
+		t.Run(name, func(t *testing.T) {
+			req := httptest.NewRequest(http.MethodPost, "/sellers/import", strings.NewReader(tc.body)).WithContext(callerWithBrands(model.BrandIDBrandA))
+			rec := httptest.NewRecorder()
+			handler.ImportSellers(rec, req)
+			assert.Equal(t, tc.want, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+// TestImportSellers_WritesOutboxEvents runs against the database in TEST_DATABASE_DSN and is skipped without it.
+func TestImportSellers_WritesOutboxEvents(t *testing.T) {
+	db := databasetest.Open(t, migrations.FS, migrations.Table)
+	databasetest.Truncate(t, db, "sellers", migrations.OutboxTable)
+	outbox, err := commonDB.NewOutbox(db, migrations.OutboxTable)
+	require.NoError(t, err)
+	repo := repository.NewPGSellerRepository(commonDB.NewCluster(db, nil, commonDB.ClusterConfig{}), nil)
+	svc := service.NewSellerService(repo, &fixedGeocoder{}, logger.NewStdLogger(), nil, commonDB.NewTxManager(db, commonDB.TxOptions{}), outbox)
+	ctx := callerWithBrands(commonAuth.AllTenants)
+
+	imported, err := svc.ImportSellers(ctx, []*model.Seller{importSeller(model.BrandIDBrandA, 1, 1), importSeller(model.BrandIDBrandB, 1, 1), importSeller(model.BrandIDBrandC, 1, 1)}, "user-1")
+	require.NoError(t, err)
+
+	rows, err := db.QueryContext(ctx, `SELECT aggregate_id, event_type FROM `+migrations.OutboxTable+` ORDER BY seq`)
+	require.NoError(t, err)
+	defer rows.Close()
+	var aggregateIDs []string
+	for rows.Next() {
+		var aggregateID, eventType string
+		require.NoError(t, rows.Scan(&aggregateID, &eventType))
+		assert.Equal(t, service.EventSellerCreated, eventType)
+		aggregateIDs = append(aggregateIDs, aggregateID)
+	}
+	require.NoError(t, rows.Err())
+	assert.Equal(t, []string{imported[0].ID, imported[1].ID, imported[2].ID}, aggregateIDs, "one event per seller, in import order")
+}
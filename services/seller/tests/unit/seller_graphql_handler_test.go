@@ -1,4 +1,4 @@
-package graphql_test
+package unit
 
 import (
 	"context"
@@ -6,65 +6,17 @@ import (
 
 	"github.com/graphql-go/graphql"
 	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 	sellerGraphQL "github.com/omni-compos/digital-mono/services/seller/internal/handler/graphql"
-	"github.com/omni-compos/digital-mono/services/seller/internal/domain"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 )
 
-// Mock implementations (re-using service mocks)
-type MockSellerService struct {
-	mock.Mock
-}
-
-func (m *MockSellerService) CreateSeller(ctx context.Context, seller *model.Seller, userID string) (*model.Seller, error) {
-	args := m.Called(ctx, seller, userID)
-	return args.Get(0).(*model.Seller), args.Error(1)
-}
-
-func (m *MockSellerService) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
-	args := m.Called(ctx, id)
-	return args.Get(0).(*model.Seller), args.Error(1)
-}
-
-func (m *MockSellerService) UpdateSeller(ctx context.Context, id string, updates *model.Seller, userID string) (*model.Seller, error) {
-	args := m.Called(ctx, id, updates, userID)
-	return args.Get(0).(*model.Seller), args.Error(1)
-}
-
-func (m *MockSellerService) DeleteSeller(ctx context.Context, id string) error {
-	args := m.Called(ctx, id)
-	return args.Error(0)
-}
-
-func (m *MockSellerService) ListSellers(ctx context.Context, limit, offset int) ([]*model.Seller, error) {
-	args := m.Called(ctx, limit, offset)
-	return args.Get(0).([]*model.Seller), args.Error(1)
-}
-
-// MockLogger (re-using service mock)
-type MockLogger struct {
-	mock.Mock
-}
-
-func (m *MockLogger) Info(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
-}
-func (m *MockLogger) Error(err error, msg string, keysAndValues ...interface{}) {
-	m.Called(err, msg, keysAndValues)
-}
-func (m *MockLogger) Debug(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
-}
-func (m *MockLogger) Warn(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
-}
-
 // Helper to create a GraphQL schema with mocks
 func newMockGraphQLSchema(t *testing.T) (*MockSellerService, *MockLogger, graphql.Schema) {
 	mockService := new(MockSellerService)
 	mockLogger := new(MockLogger)
-	handler, err := sellerGraphQL.NewSellerGraphQLHandler(mockService, mockLogger)
+	handler, err := sellerGraphQL.NewSellerGraphQLHandler(mockService, mockLogger, commonAuth.DefaultPolicy())
 	if err != nil {
 		t.Fatalf("Failed to create GraphQL handler: %v", err)
 	}
@@ -89,11 +41,10 @@ func TestSellerGraphQLHandler_GetSellerQuery(t *testing.T) {
 	expectedSeller := &model.Seller{ID: sellerID, BrandID: model.BrandIDBrandA, Status: model.StatusActive} // Simplified
 	userID := "test-user-456"
 
-	// Add UserID to context, simulating JWT middleware
-	ctx := context.WithValue(context.Background(), commonAuth.UserIDContextKey, userID)
+	ctx := contextWithClaims(userID)
 
 	// Expect the service call
-	mockService.On("GetSellerByID", ctx, sellerID).Return(expectedSeller, nil).Once()
+	mockService.On("GetSellerByID", mock.Anything, sellerID).Return(expectedSeller, nil).Once()
 
 	query := `
 		query GetSeller($id: String!) {
@@ -141,29 +92,14 @@ func TestSellerGraphQLHandler_CreateSellerMutation(t *testing.T) {
 	userID := "test-user-456"
 	createdSeller := &model.Seller{ID: "new-seller-id", BrandID: model.BrandIDBrandA, Status: model.StatusActive, LastUpdatedBy: userID} // Simplified
 
-	// Add UserID to context, simulating JWT middleware
-	ctx := context.WithValue(context.Background(), commonAuth.UserIDContextKey, userID)
+	ctx := contextWithClaims(userID)
 
 	// Expect the service call
 	// Use mock.AnythingOfType to match the seller object passed to the service
-	mockService.On("CreateSeller", ctx, mock.AnythingOfType("*model.Seller"), userID).
+	mockService.On("CreateSeller", mock.Anything, mock.AnythingOfType("*domain.Seller"), userID).
 		Return(createdSeller, nil).Once()
 
-	mutation := `
-		mutation CreateSeller($input: CreateSellerInput!) {
-			createSeller(input: $input) {
-				id
-				brandId
-				status
-				lastUpdatedBy
-			}
-		}
-	`
-	// GraphQL input types need to be defined or inferred.
-	// For simplicity in the test, we'll pass the args directly if the schema allows it,
-	// or define a simple input type structure if needed.
-	// The current schema definition uses direct args, not an input object type.
-	// Let's adjust the test query/variables to match the schema args.
+	// The schema takes the seller fields as arguments, not as an input object
 	mutationArgs := `
 		mutation CreateSeller(
 			$brandId: String!, $status: String!, $address: String!, $city: String!,
@@ -237,11 +173,11 @@ func TestSellerGraphQLHandler_CreateSellerMutation_Unauthorized(t *testing.T) {
 
 	assert.NotEmpty(t, result.Errors)
 	assert.Contains(t, result.Errors[0].Error(), "unauthorized")
-	assert.Nil(t, result.Data) // Data should be nil on error
+	assert.Equal(t, map[string]interface{}{"createSeller": nil}, result.Data) // The field is null on error
 
 	mockService.AssertExpectations(t) // Service should not be called
 	mockLogger.AssertExpectations(t)
 }
 
 // Add tests for updateSeller, deleteSeller, sellers (list) queries.
-// Ensure error handling and authorization checks in resolvers are tested.
\ No newline at end of file
+// Ensure error handling and authorization checks in resolvers are tested.
@@ -1,4 +1,4 @@
-package rest_test
+package unit
 
 import (
 	"bytes"
@@ -11,7 +11,9 @@ import (
 
 	"github.com/gorilla/mux"
 	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
-	"github.com/omni-compos/digital-mono/libs/metrics"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/metrics/metricstest"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 	"github.com/omni-compos/digital-mono/services/seller/internal/handler/rest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -27,6 +29,11 @@ func (m *MockSellerService) CreateSeller(ctx context.Context, seller *model.Sell
 	return args.Get(0).(*model.Seller), args.Error(1)
 }
 
+func (m *MockSellerService) ImportSellers(ctx context.Context, sellers []*model.Seller, userID string) ([]*model.Seller, error) {
+	args := m.Called(ctx, sellers, userID)
+	return args.Get(0).([]*model.Seller), args.Error(1)
+}
+
 func (m *MockSellerService) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*model.Seller), args.Error(1)
@@ -47,33 +54,6 @@ func (m *MockSellerService) ListSellers(ctx context.Context, limit, offset int)
 	return args.Get(0).([]*model.Seller), args.Error(1)
 }
 
-type MockMetrics struct {
-	mock.Mock
-}
-
-func (m *MockMetrics) IncRequestsTotal(operation, handlerType string) {
-	m.Called(operation, handlerType)
-}
-func (m *MockMetrics) IncResponsesTotal(operation, handlerType, code string) {
-	m.Called(operation, handlerType, code)
-}
-func (m *MockMetrics) NewRequestDurationTimer(operation, handlerType string) metrics.RequestDurationTimer {
-	args := m.Called(operation, handlerType)
-	return args.Get(0).(metrics.RequestDurationTimer)
-}
-func (m *MockMetrics) Handler() http.Handler {
-	args := m.Called()
-	return args.Get(0).(http.Handler)
-}
-
-type MockRequestDurationTimer struct {
-	mock.Mock
-}
-
-func (m *MockRequestDurationTimer) ObserveDuration() {
-	m.Called()
-}
-
 // MockLogger (re-using service mock)
 type MockLogger struct {
 	mock.Mock
@@ -88,36 +68,48 @@ func (m *MockLogger) Error(err error, msg string, keysAndValues ...interface{})
 func (m *MockLogger) Debug(msg string, keysAndValues ...interface{}) {
 	m.Called(msg, keysAndValues)
 }
-func (m *MockLogger) Warn(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
+func (m *MockLogger) Warn(err error, msg string, keysAndValues ...interface{}) {
+	m.Called(err, msg, keysAndValues)
+}
+func (m *MockLogger) With(keysAndValues ...interface{}) logger.Logger {
+	return m
 }
 
-// Helper to create a handler with mocks
-func newMockHandler(t *testing.T) (*MockSellerService, *MockLogger, *MockMetrics, *rest.SellerRESTHandler) {
+// Helper to create a handler with mocks. Metrics come from the router middleware, so the
+// returned router records every request in the Recorder.
+func newMockHandler(t *testing.T) (*MockSellerService, *MockLogger, *metricstest.Recorder, *mux.Router) {
 	mockService := new(MockSellerService)
 	mockLogger := new(MockLogger)
-	mockMetrics := new(MockMetrics)
-	handler := rest.NewSellerRESTHandler(mockService, mockLogger, mockMetrics)
-	return mockService, mockLogger, mockMetrics, handler
+	recorder := metricstest.NewRecorder()
+	handler := rest.NewSellerRESTHandler(mockService, mockLogger, commonAuth.DefaultPolicy())
+
+	router := mux.NewRouter()
+	router.Use(recorder.Middleware)
+	apiRouter := router.PathPrefix("/api/v1").Subrouter()
+	handler.RegisterRoutes(apiRouter)
+	return mockService, mockLogger, recorder, router
 }
 
 // Helper to create a request with context
 func newRequestWithContext(method, url string, body interface{}, userID string) *http.Request {
 	var reqBody bytes.Buffer
-	if body != nil {
+	if raw, ok := body.(string); ok {
+		reqBody.WriteString(raw) // Sent as is, e.g. malformed JSON
+	} else if body != nil {
 		json.NewEncoder(&reqBody).Encode(body)
 	}
 
 	req := httptest.NewRequest(method, url, &reqBody)
 	req.Header.Set("Content-Type", "application/json")
 
-	// Add UserID to context, simulating JWT middleware
-	ctx := context.WithValue(req.Context(), commonAuth.UserIDContextKey, userID)
+	// Add claims to context, simulating JWT middleware
+	claims := &commonAuth.Claims{UserID: userID, Roles: []string{"admin"}, Brands: []string{commonAuth.AllTenants}}
+	ctx := context.WithValue(req.Context(), commonAuth.ClaimsContextKey, claims)
 	return req.WithContext(ctx)
 }
 
 func TestSellerRESTHandler_CreateSeller(t *testing.T) {
-	mockService, mockLogger, mockMetrics, handler := newMockHandler(t)
+	mockService, mockLogger, recorder, router := newMockHandler(t)
 
 	inputSeller := map[string]interface{}{
 		"brandId":     model.BrandIDBrandA,
@@ -136,21 +128,10 @@ func TestSellerRESTHandler_CreateSeller(t *testing.T) {
 	req := newRequestWithContext("POST", "/api/v1/sellers", inputSeller, userID)
 	rr := httptest.NewRecorder()
 
-	mockTimer := new(MockRequestDurationTimer)
-	mockMetrics.On("IncRequestsTotal", "create_seller", "rest").Once()
-	mockMetrics.On("NewRequestDurationTimer", "create_seller", "rest").Return(mockTimer).Once()
-	mockTimer.On("ObserveDuration").Once()
-	mockMetrics.On("IncResponsesTotal", "create_seller", "rest", "201").Once()
-
 	// Expect the service call
-	mockService.On("CreateSeller", mock.Anything, mock.AnythingOfType("*model.Seller"), userID).
+	mockService.On("CreateSeller", mock.Anything, mock.AnythingOfType("*domain.Seller"), userID).
 		Return(expectedSeller, nil).Once()
 
-	// Use a router to handle the path matching
-	router := mux.NewRouter()
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	handler.RegisterRoutes(apiRouter)
-
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusCreated, rr.Code)
@@ -164,33 +145,22 @@ func TestSellerRESTHandler_CreateSeller(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t) // Should not be called on success
-	mockMetrics.AssertExpectations(t)
-	mockTimer.AssertExpectations(t)
+	recorder.AssertHTTPRequest(t, "/api/v1/sellers", http.MethodPost, http.StatusCreated)
 }
 
 func TestSellerRESTHandler_CreateSeller_InvalidPayload(t *testing.T) {
-	mockService, mockLogger, mockMetrics, handler := newMockHandler(t)
+	mockService, mockLogger, recorder, router := newMockHandler(t)
 
 	invalidInput := `{"brandId": 123}` // brandId should be string
 	userID := "test-user-123"
 
-	req := newRequestWithContext("POST", "/api/v1/sellers", bytes.NewBufferString(invalidInput), userID)
+	req := newRequestWithContext("POST", "/api/v1/sellers", invalidInput, userID)
 	rr := httptest.NewRecorder()
 
-	mockTimer := new(MockRequestDurationTimer)
-	mockMetrics.On("IncRequestsTotal", "create_seller", "rest").Once()
-	mockMetrics.On("NewRequestDurationTimer", "create_seller", "rest").Return(mockTimer).Once()
-	mockTimer.On("ObserveDuration").Once()
-	mockMetrics.On("IncResponsesTotal", "create_seller", "rest", "400").Once()
-
-	mockLogger.On("Error", mock.Anything, "Failed to decode request body for CreateSeller").Once() // Expect logger call
+	mockLogger.On("Error", mock.Anything, "Failed to decode request body for CreateSeller", mock.Anything).Once() // Expect logger call
 
 	// No service expectation as decoding should fail first
 
-	router := mux.NewRouter()
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	handler.RegisterRoutes(apiRouter)
-
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusBadRequest, rr.Code)
@@ -198,12 +168,11 @@ func TestSellerRESTHandler_CreateSeller_InvalidPayload(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
-	mockMetrics.AssertExpectations(t)
-	mockTimer.AssertExpectations(t)
+	recorder.AssertHTTPRequest(t, "/api/v1/sellers", http.MethodPost, http.StatusBadRequest)
 }
 
 func TestSellerRESTHandler_CreateSeller_ServiceError(t *testing.T) {
-	mockService, mockLogger, mockMetrics, handler := newMockHandler(t)
+	mockService, mockLogger, recorder, router := newMockHandler(t)
 
 	inputSeller := map[string]interface{}{
 		"brandId":     model.BrandIDBrandA,
@@ -222,21 +191,11 @@ func TestSellerRESTHandler_CreateSeller_ServiceError(t *testing.T) {
 	req := newRequestWithContext("POST", "/api/v1/sellers", inputSeller, userID)
 	rr := httptest.NewRecorder()
 
-	mockTimer := new(MockRequestDurationTimer)
-	mockMetrics.On("IncRequestsTotal", "create_seller", "rest").Once()
-	mockMetrics.On("NewRequestDurationTimer", "create_seller", "rest").Return(mockTimer).Once()
-	mockTimer.On("ObserveDuration").Once()
-	mockMetrics.On("IncResponsesTotal", "create_seller", "rest", "500").Once()
-
 	// Expect the service call to return an error
-	mockService.On("CreateSeller", mock.Anything, mock.AnythingOfType("*model.Seller"), userID).
+	mockService.On("CreateSeller", mock.Anything, mock.AnythingOfType("*domain.Seller"), userID).
 		Return((*model.Seller)(nil), serviceErr).Once()
 
-	mockLogger.On("Error", serviceErr, "Failed to create seller via service").Once() // Expect logger call
-
-	router := mux.NewRouter()
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	handler.RegisterRoutes(apiRouter)
+	mockLogger.On("Error", serviceErr, "Failed to create seller via service", mock.Anything).Once() // Expect logger call
 
 	router.ServeHTTP(rr, req)
 
@@ -245,8 +204,7 @@ func TestSellerRESTHandler_CreateSeller_ServiceError(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
-	mockMetrics.AssertExpectations(t)
-	mockTimer.AssertExpectations(t)
+	recorder.AssertHTTPRequest(t, "/api/v1/sellers", http.MethodPost, http.StatusInternalServerError)
 }
 
 // Add tests for GetSellerByID, UpdateSeller, DeleteSeller, ListSellers
@@ -254,27 +212,17 @@ func TestSellerRESTHandler_CreateSeller_ServiceError(t *testing.T) {
 // Ensure JWT context is handled (e.g., test missing UserID in context).
 
 func TestSellerRESTHandler_GetSellerByID(t *testing.T) {
-	mockService, mockLogger, mockMetrics, handler := newMockHandler(t)
+	mockService, mockLogger, recorder, router := newMockHandler(t)
 
 	sellerID := "existing-seller-id"
 	expectedSeller := &model.Seller{ID: sellerID, BrandID: model.BrandIDBrandA} // Simplified
-	userID := "test-user-123" // UserID needed for middleware, but not used by GetByID service method in this example
+	userID := "test-user-123"                                                   // UserID needed for middleware, but not used by GetByID service method in this example
 
 	req := newRequestWithContext("GET", "/api/v1/sellers/"+sellerID, nil, userID)
 	rr := httptest.NewRecorder()
 
-	mockTimer := new(MockRequestDurationTimer)
-	mockMetrics.On("IncRequestsTotal", "get_seller_by_id", "rest").Once()
-	mockMetrics.On("NewRequestDurationTimer", "get_seller_by_id", "rest").Return(mockTimer).Once()
-	mockTimer.On("ObserveDuration").Once()
-	mockMetrics.On("IncResponsesTotal", "get_seller_by_id", "rest", "200").Once()
-
 	mockService.On("GetSellerByID", mock.Anything, sellerID).Return(expectedSeller, nil).Once()
 
-	router := mux.NewRouter()
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	handler.RegisterRoutes(apiRouter)
-
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusOK, rr.Code)
@@ -285,12 +233,11 @@ func TestSellerRESTHandler_GetSellerByID(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
-	mockMetrics.AssertExpectations(t)
-	mockTimer.AssertExpectations(t)
+	recorder.AssertHTTPRequest(t, "/api/v1/sellers/{id}", http.MethodGet, http.StatusOK)
 }
 
 func TestSellerRESTHandler_GetSellerByID_NotFound(t *testing.T) {
-	mockService, mockLogger, mockMetrics, handler := newMockHandler(t)
+	mockService, mockLogger, recorder, router := newMockHandler(t)
 
 	sellerID := "non-existent-id"
 	userID := "test-user-123"
@@ -298,18 +245,8 @@ func TestSellerRESTHandler_GetSellerByID_NotFound(t *testing.T) {
 	req := newRequestWithContext("GET", "/api/v1/sellers/"+sellerID, nil, userID)
 	rr := httptest.NewRecorder()
 
-	mockTimer := new(MockRequestDurationTimer)
-	mockMetrics.On("IncRequestsTotal", "get_seller_by_id", "rest").Once()
-	mockMetrics.On("NewRequestDurationTimer", "get_seller_by_id", "rest").Return(mockTimer).Once()
-	mockTimer.On("ObserveDuration").Once()
-	mockMetrics.On("IncResponsesTotal", "get_seller_by_id", "rest", "404").Once()
-
 	mockService.On("GetSellerByID", mock.Anything, sellerID).Return((*model.Seller)(nil), nil).Once() // Service returns nil, nil for not found
 
-	router := mux.NewRouter()
-	apiRouter := router.PathPrefix("/api/v1").Subrouter()
-	handler.RegisterRoutes(apiRouter)
-
 	router.ServeHTTP(rr, req)
 
 	assert.Equal(t, http.StatusNotFound, rr.Code)
@@ -317,8 +254,7 @@ func TestSellerRESTHandler_GetSellerByID_NotFound(t *testing.T) {
 
 	mockService.AssertExpectations(t)
 	mockLogger.AssertExpectations(t)
-	mockMetrics.AssertExpectations(t)
-	mockTimer.AssertExpectations(t)
+	recorder.AssertHTTPRequest(t, "/api/v1/sellers/{id}", http.MethodGet, http.StatusNotFound)
 }
 
-// Add tests for UpdateSeller, DeleteSeller, ListSellers
\ No newline at end of file
+// Add tests for UpdateSeller, DeleteSeller, ListSellers
@@ -1,4 +1,4 @@
-package service_test
+package unit
 
 import (
 	"context"
@@ -6,7 +6,8 @@ import (
 	"testing"
 	"time"
 
-	"github.com/omni-compos/digital-mono/services/seller/internal/domain"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 	"github.com/omni-compos/digital-mono/services/seller/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
@@ -22,6 +23,11 @@ func (m *MockSellerRepository) CreateSeller(ctx context.Context, seller *model.S
 	return args.Error(0)
 }
 
+func (m *MockSellerRepository) CreateSellers(ctx context.Context, sellers []*model.Seller) error {
+	args := m.Called(ctx, sellers)
+	return args.Error(0)
+}
+
 func (m *MockSellerRepository) GetSellerByID(ctx context.Context, id string) (*model.Seller, error) {
 	args := m.Called(ctx, id)
 	return args.Get(0).(*model.Seller), args.Error(1)
@@ -51,29 +57,18 @@ func (m *MockLocationalisationService) GetLatLngFromAddress(ctx context.Context,
 	return args.Get(0).(float64), args.Get(1).(float64), args.Error(2)
 }
 
-type MockLogger struct {
-	mock.Mock
-}
-
-func (m *MockLogger) Info(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
-}
-func (m *MockLogger) Error(err error, msg string, keysAndValues ...interface{}) {
-	m.Called(err, msg, keysAndValues)
-}
-func (m *MockLogger) Debug(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
-}
-func (m *MockLogger) Warn(msg string, keysAndValues ...interface{}) {
-	m.Called(msg, keysAndValues)
+// Helper to add claims to a context, simulating JWT middleware. The claims may use every brand
+func contextWithClaims(userID string) context.Context {
+	claims := &commonAuth.Claims{UserID: userID, Roles: []string{"admin"}, Brands: []string{commonAuth.AllTenants}}
+	return context.WithValue(context.Background(), commonAuth.ClaimsContextKey, claims)
 }
 
 func TestDefaultSellerService_CreateSeller(t *testing.T) {
 	mockRepo := new(MockSellerRepository)
 	mockLoc := new(MockLocationalisationService)
 	mockLogger := new(MockLogger)
-	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger)
-	ctx := context.Background()
+	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger, nil, nil, nil)
+	ctx := contextWithClaims("test-user-123")
 	userID := "test-user-123"
 
 	inputSeller := model.NewSeller()
@@ -91,14 +86,15 @@ func TestDefaultSellerService_CreateSeller(t *testing.T) {
 	expectedLng := 151.0
 
 	// Setup mock expectations
-	mockLoc.On("GetLatLngFromAddress", ctx, inputSeller.Address, inputSeller.City, inputSeller.State, inputSeller.Country, inputSeller.Postcode).
+	mockLoc.On("GetLatLngFromAddress", mock.Anything, inputSeller.Address, inputSeller.City, inputSeller.State, inputSeller.Country, inputSeller.Postcode).
 		Return(expectedLat, expectedLng, nil).Once()
 
 	// Expect CreateSeller to be called with a seller object that has ID, Lat/Lng, and audit fields set
-	mockRepo.On("CreateSeller", ctx, mock.AnythingOfType("*model.Seller")).
+	mockRepo.On("CreateSeller", mock.Anything, mock.AnythingOfType("*domain.Seller")).
 		Return(nil).Once()
 
-	mockLogger.On("Info", "Seller created successfully", mock.Anything, mock.Anything).Maybe() // Expect logger call
+	mockLogger.On("Debug", "Geocoded seller address", mock.Anything).Once()
+	mockLogger.On("Info", "Seller created successfully", mock.Anything).Once() // Expect logger call
 
 	// Call the service method
 	createdSeller, err := sellerService.CreateSeller(ctx, inputSeller, userID)
@@ -125,8 +121,8 @@ func TestDefaultSellerService_CreateSeller_InvalidBrandID(t *testing.T) {
 	mockRepo := new(MockSellerRepository)
 	mockLoc := new(MockLocationalisationService)
 	mockLogger := new(MockLogger)
-	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger)
-	ctx := context.Background()
+	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger, nil, nil, nil)
+	ctx := contextWithClaims("test-user-123")
 	userID := "test-user-123"
 
 	inputSeller := model.NewSeller()
@@ -151,8 +147,8 @@ func TestDefaultSellerService_CreateSeller_GeocodingError(t *testing.T) {
 	mockRepo := new(MockSellerRepository)
 	mockLoc := new(MockLocationalisationService)
 	mockLogger := new(MockLogger)
-	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger)
-	ctx := context.Background()
+	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger, nil, nil, nil)
+	ctx := contextWithClaims("test-user-123")
 	userID := "test-user-123"
 
 	inputSeller := model.NewSeller()
@@ -162,10 +158,10 @@ func TestDefaultSellerService_CreateSeller_GeocodingError(t *testing.T) {
 	// ... other required fields
 
 	locError := errors.New("geocoding failed")
-	mockLoc.On("GetLatLngFromAddress", ctx, inputSeller.Address, inputSeller.City, inputSeller.State, inputSeller.Country, inputSeller.Postcode).
+	mockLoc.On("GetLatLngFromAddress", mock.Anything, inputSeller.Address, inputSeller.City, inputSeller.State, inputSeller.Country, inputSeller.Postcode).
 		Return(0.0, 0.0, locError).Once()
 
-	mockLogger.On("Error", locError, "Failed to get lat/lng for seller", mock.Anything, mock.Anything).Maybe() // Expect logger call
+	mockLogger.On("Error", locError, "Failed to get lat/lng for seller", mock.Anything).Once() // Expect logger call
 
 	// No repo expectation as it shouldn't be called
 
@@ -187,13 +183,13 @@ func TestDefaultSellerService_GetSellerByID(t *testing.T) {
 	mockRepo := new(MockSellerRepository)
 	mockLoc := new(MockLocationalisationService)
 	mockLogger := new(MockLogger)
-	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger)
-	ctx := context.Background()
+	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger, nil, nil, nil)
+	ctx := contextWithClaims("test-user-123")
 	sellerID := "existing-id"
 
 	expectedSeller := &model.Seller{ID: sellerID, BrandID: model.BrandIDBrandA, Status: model.StatusActive} // Simplified
 
-	mockRepo.On("GetSellerByID", ctx, sellerID).Return(expectedSeller, nil).Once()
+	mockRepo.On("GetSellerByID", mock.Anything, sellerID).Return(expectedSeller, nil).Once()
 
 	seller, err := sellerService.GetSellerByID(ctx, sellerID)
 
@@ -209,11 +205,12 @@ func TestDefaultSellerService_GetSellerByID_NotFound(t *testing.T) {
 	mockRepo := new(MockSellerRepository)
 	mockLoc := new(MockLocationalisationService)
 	mockLogger := new(MockLogger)
-	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger)
-	ctx := context.Background()
+	sellerService := service.NewSellerService(mockRepo, mockLoc, mockLogger, nil, nil, nil)
+	ctx := contextWithClaims("test-user-123")
 	sellerID := "non-existent-id"
 
-	mockRepo.On("GetSellerByID", ctx, sellerID).Return((*model.Seller)(nil), nil).Once() // Simulate not found
+	mockRepo.On("GetSellerByID", mock.Anything, sellerID).Return((*model.Seller)(nil), nil).Once() // Simulate not found
+	mockLogger.On("Debug", "Seller not found or outside caller's brands", mock.Anything).Once()
 
 	seller, err := sellerService.GetSellerByID(ctx, sellerID)
 
@@ -225,4 +222,4 @@ func TestDefaultSellerService_GetSellerByID_NotFound(t *testing.T) {
 	mockLogger.AssertExpectations(t)
 }
 
-// Add more tests covering update logic, delete logic, list logic, and error handling.
\ No newline at end of file
+// Add more tests covering update logic, delete logic, list logic, and error handling.
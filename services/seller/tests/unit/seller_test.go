@@ -1,9 +1,9 @@
-package model_test
+package unit
 
 import (
 	"testing"
 
-	"github.com/omni-compos/digital-mono/services/seller/internal/domain"
+	model "github.com/omni-compos/digital-mono/services/seller/internal/domain"
 )
 
 func TestNewSeller(t *testing.T) {
@@ -18,4 +18,4 @@ func TestNewSeller(t *testing.T) {
 }
 
 // Add tests for validating BrandID and Status if validation logic was in the model
-// (Currently validation is in the service, which is fine)
\ No newline at end of file
+// (Currently validation is in the service, which is fine)
@@ -0,0 +1,54 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	userRepo "github.com/omni-compos/digital-mono/services/user/internal/repository"
+	userService "github.com/omni-compos/digital-mono/services/user/internal/service"
+)
+
+// seedBootstrapClient creates the service client named by BOOTSTRAP_CLIENT_ID with the secret in
+// BOOTSTRAP_CLIENT_SECRET, unless it already exists. Password login is disabled, so this client is
+// how a fresh deployment (or STORAGE=memory) gets its first token with write access: it may request
+// every scope on every brand from the user, seller and product services through POST /api/v1/oauth/token.
+// Humans get their roles from the OIDC provider (OIDC_ROLES_CLAIM, OIDC_DEFAULT_ROLES).
+func seedBootstrapClient(ctx context.Context, clients userRepo.ServiceClientRepository, appLogger commonLogger.Logger) error {
+	clientID := os.Getenv("BOOTSTRAP_CLIENT_ID")
+	if clientID == "" {
+		return nil
+	}
+	secret := os.Getenv("BOOTSTRAP_CLIENT_SECRET")
+	if secret == "" {
+		return fmt.Errorf("BOOTSTRAP_CLIENT_SECRET must be set with BOOTSTRAP_CLIENT_ID")
+	}
+	existing, err := clients.GetServiceClient(ctx, clientID)
+	if err != nil {
+		return fmt.Errorf("failed to look up bootstrap client: %w", err)
+	}
+	if existing != nil {
+		// Left as stored, so a secret rotated in the database is not reset on restart
+		appLogger.Info("Bootstrap client already exists", "client_id", clientID)
+		return nil
+	}
+	err = clients.CreateServiceClient(ctx, &domain.ServiceClient{
+		ClientID:         clientID,
+		Name:             "Bootstrap client",
+		SecretHash:       userService.HashClientSecret(secret),
+		AllowedScopes:    []string{"*"},
+		AllowedAudiences: []string{"user-service", "seller-service", "product-service"},
+		AllowedBrands:    []string{commonAuth.AllTenants},
+		CreatedAt:        time.Now(),
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create bootstrap client: %w", err)
+	}
+	appLogger.Info("Created bootstrap client", "client_id", clientID)
+	return nil
+}
@@ -1,63 +1,146 @@
 package main
 
 import (
+	"context"
 	"fmt"
 	"log"
 	"net/http"
 	"os"
+	"strings"
+	"time"
 
 	"github.com/gorilla/mux"
 	"github.com/graphql-go/handler"
-	_ "github.com/lib/pq" // PostgreSQL driver
 
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	commonDB "github.com/omni-compos/digital-mono/libs/database"
 	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
 	commonMetrics "github.com/omni-compos/digital-mono/libs/metrics"
+	commonTracing "github.com/omni-compos/digital-mono/libs/tracing"
 
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
 	userGraphQL "github.com/omni-compos/digital-mono/services/user/internal/handler/graphql"
 	userREST "github.com/omni-compos/digital-mono/services/user/internal/handler/rest"
-	userRepo "github.com/omni-compos/digital-mono/services/user/internal/repository"
 	userService "github.com/omni-compos/digital-mono/services/user/internal/service"
 )
 
 func main() {
 	println("Hello from $SERVICE_NAME service")
 	// Configuration (ideally from env vars or config file)
-	dbDSN := os.Getenv("DB_DSN")
-	if dbDSN == "" {
-		dbDSN = "host=localhost port=5432 user=omni_user password=strong_password dbname=digital_mono_db sslmode=disable"
-		log.Println("Warning: DB_DSN not set, using default.")
-	}
-	jwtSecret := os.Getenv("JWT_SECRET")
-	if jwtSecret == "" {
-		jwtSecret = "your-super-secret-key-for-user-service" // Use a strong, unique secret
-		log.Println("Warning: JWT_SECRET not set, using default.")
-	}
+	// Comma separated list of PEM private key files; the first one signs new tokens.
+	// Older keys stay published in the JWKS until every token they signed has expired.
+	signingKeyFiles := os.Getenv("JWT_SIGNING_KEY_FILES")
 
 	// Initialize common libraries
-	appLogger := commonLogger.NewStdLogger() // Replace with your actual logger implementation
+	// Structured logs; LOG_FORMAT (json|text), LOG_LEVEL and SERVICE_VERSION configure the output
+	logConfig := commonLogger.ConfigFromEnv("user-service")
+	logConfig.Redactor.RegisterKeys(commonLogger.RedactMask, domain.LogRedactedKeys...)
+	appLogger, err := commonLogger.NewSlogLogger(logConfig)
+	if err != nil {
+		log.Fatalf("Failed to initialize logger: %v", err)
+	}
+	commonLogger.SetDefault(appLogger)
 	appLogger.Info("Starting user service...")
 
-	db, err := commonDB.NewPostgresDB(dbDSN)
+	// Traces; OTEL_TRACES_EXPORTER (otlp|stdout|file|none) and OTEL_EXPORTER_OTLP_ENDPOINT configure the export
+	shutdownTracing, err := commonTracing.Setup(context.Background(), commonTracing.ConfigFromEnv("user-service"))
 	if err != nil {
-		appLogger.Error(err, "Failed to connect to database")
-		log.Fatalf("Failed to connect to database: %v", err)
+		appLogger.Error(err, "Failed to initialize tracing")
+		log.Fatalf("Failed to initialize tracing: %v", err)
 	}
-	defer db.Close()
-	appLogger.Info("Successfully connected to database")
+	defer shutdownTracing(context.Background())
+	// Outgoing calls (e.g. JWKS and OIDC fetches) carry the trace and request ID
+	http.DefaultTransport = commonTracing.Transport(commonLogger.Transport(http.DefaultTransport))
 
 	// Initialize Prometheus metrics (placeholder, replace with actual implementation)
 	promMetrics := commonMetrics.NewPrometheusMetrics("user_service", "api")
+	// Connection pool stats and per-query latency/errors, served on /metrics
+	queryMetrics := commonDB.NewQueryMetrics("user_service")
+	// Domain KPIs (e.g. logins by outcome), served on /metrics under user_service_business_*
+	businessMetrics := commonMetrics.NewBusinessMetrics("user_service")
+	kpis := userService.NewKPIs(businessMetrics)
+	if err := promMetrics.Register(queryMetrics, businessMetrics); err != nil {
+		appLogger.Error(err, "Failed to register query and business metrics")
+		log.Fatalf("Failed to register query and business metrics: %v", err)
+	}
+
+	// Postgres by default; STORAGE=memory runs without a database
+	store, err := openStorage(context.Background(), appLogger, queryMetrics)
+	if err != nil {
+		appLogger.Error(err, "Failed to set up storage")
+		log.Fatalf("Failed to set up storage: %v", err)
+	}
+	defer store.close()
+	if store.db != nil {
+		if err := promMetrics.Register(commonDB.NewDBStatsCollector(store.db, "users")); err != nil {
+			appLogger.Error(err, "Failed to register database metrics")
+			log.Fatalf("Failed to register database metrics: %v", err)
+		}
+	}
 
 	// Initialize Auth
-	// authenticator := commonAuth.NewJWTAuthenticator(jwtSecret)
+	var keySet *commonAuth.KeySet
+	if signingKeyFiles != "" {
+		keySet, err = commonAuth.LoadKeySetFiles(strings.Split(signingKeyFiles, ",")...)
+	} else {
+		log.Println("Warning: JWT_SIGNING_KEY_FILES not set, generating an ephemeral RS256 signing key.")
+		var key *commonAuth.SigningKey
+		if key, err = commonAuth.GenerateRSASigningKey("ephemeral-" + time.Now().UTC().Format("20060102150405")); err == nil {
+			keySet = commonAuth.NewKeySet(key)
+		}
+	}
+	if err != nil {
+		appLogger.Error(err, "Failed to load JWT signing keys")
+		log.Fatalf("Failed to load JWT signing keys: %v", err)
+	}
+	authenticator := commonAuth.NewKeySetAuthenticator(keySet).
+		WithRevocationStore(store.revocations).
+		WithAPIKeyStore(store.apiKeyStore).
+		WithAudience("user-service")
+
+	// Role to permission mapping; AUTHZ_POLICY_FILE overrides the policy bundled with libs/auth
+	policy, err := commonAuth.LoadPolicy(os.Getenv("AUTHZ_POLICY_FILE"))
+	if err != nil {
+		appLogger.Error(err, "Failed to load authorization policy")
+		log.Fatalf("Failed to load authorization policy: %v", err)
+	}
 
 	// Dependency Injection
-	repo := userRepo.NewPGUserRepository(db)
-	service := userService.NewUserService(repo, appLogger)
+	service := userService.NewUserService(store.repo, appLogger, kpis, store.tx, store.outbox)
+	tokenService := userService.NewTokenService(authenticator, store.tokens, appLogger, userService.DefaultAccessTokenTTL, userService.DefaultRefreshTokenTTL)
+
+	clientService := userService.NewClientCredentialsService(authenticator, store.clients, appLogger, userService.DefaultClientTokenTTL)
+
+	apiKeyService := userService.NewAPIKeyService(store.apiKeys, policy, appLogger, store.tx, store.outbox)
+
+	// Optional login through a corporate OpenID Connect provider, enabled by OIDC_ISSUER_URL
+	var oidcHandler *userREST.OIDCRESTHandler
+	if issuerURL := os.Getenv("OIDC_ISSUER_URL"); issuerURL != "" {
+		var defaultRoles []string
+		if roles := os.Getenv("OIDC_DEFAULT_ROLES"); roles != "" {
+			defaultRoles = strings.Split(roles, ",")
+		}
+		provider, err := commonAuth.NewOIDCProvider(context.Background(), commonAuth.OIDCConfig{
+			IssuerURL:    issuerURL,
+			ClientID:     os.Getenv("OIDC_CLIENT_ID"),
+			ClientSecret: os.Getenv("OIDC_CLIENT_SECRET"),
+			RedirectURL:  os.Getenv("OIDC_REDIRECT_URL"),
+			UserIDClaim:  os.Getenv("OIDC_USER_ID_CLAIM"),
+			RolesClaim:   os.Getenv("OIDC_ROLES_CLAIM"),
+			DefaultRoles: defaultRoles,
+		})
+		if err != nil {
+			appLogger.Error(err, "Failed to initialize OIDC provider")
+			log.Fatalf("Failed to initialize OIDC provider: %v", err)
+		}
+		oidcService := userService.NewOIDCLoginService(provider, tokenService, appLogger, kpis)
+		oidcHandler = userREST.NewOIDCRESTHandler(oidcService, appLogger)
+	}
 
-	restHandler := userREST.NewUserRESTHandler(service, jwtSecret, appLogger, promMetrics)
-	gqlHandler, err := userGraphQL.NewUserGraphQLHandler(service, appLogger)
+	restHandler := userREST.NewUserRESTHandler(service, tokenService, appLogger, policy)
+	clientHandler := userREST.NewClientCredentialsRESTHandler(clientService, appLogger)
+	apiKeyHandler := userREST.NewAPIKeyRESTHandler(apiKeyService, appLogger)
+	gqlHandler, err := userGraphQL.NewUserGraphQLHandler(service, appLogger, policy)
 	if err != nil {
 		appLogger.Error(err, "Failed to create GraphQL handler")
 		log.Fatalf("Failed to create GraphQL handler: %v", err)
@@ -65,38 +148,48 @@ func main() {
 
 	// Router
 	r := mux.NewRouter()
+	r.Use(commonTracing.Middleware("user-service"))  // Server spans; first, so logs and metrics see the trace
+	r.Use(commonLogger.RequestMiddleware(appLogger)) // Request-scoped logger with X-Request-ID/traceparent
+	r.Use(promMetrics.Middleware)                    // RED metrics labeled by route template
 
-	// // REST API routes
-	// apiRouter := r.PathPrefix("/api/v1").Subrouter()
-	// // apiRouter.Use(authenticator.Middleware) // Apply JWT auth middleware
-	// restHandler.RegisterRoutes(apiRouter)
-
-	// Register public routes (like login) BEFORE applying middleware
-	// restHandler.RegisterRoutes(r) // Register all routes, including /login
-
-	// restHandler.RegisterRoutes(r) // Register all routes, including /login
-	// REST API routes
+	// REST API routes; public routes (like login) first, then the ones behind the authenticator
 	apiRouter := r.PathPrefix("/api/v1").Subrouter()
 	restHandler.RegisterRoutes(apiRouter)
-	// apiRouter.Use(authenticator.Middleware) 
-	// restHandler.RegisterProtectedRoutes(apiRouter) // Register all routes, including /login
-
-
-	// Register public routes (like login) BEFORE applying middleware
- 
-
-	// GraphQL endpoint
-	// You might want to protect this with authenticator.Middleware as well
+	clientHandler.RegisterRoutes(apiRouter)
+	if oidcHandler != nil {
+		oidcHandler.RegisterRoutes(apiRouter)
+	}
+	protectedRouter := r.PathPrefix("/api/v1").Subrouter()
+	protectedRouter.Use(authenticator.Middleware)
+	restHandler.RegisterProtectedRoutes(protectedRouter)
+	apiKeyHandler.RegisterProtectedRoutes(protectedRouter)
+
+	// GraphQL endpoint; resolvers check permissions from the JWT claims
+	// Spans for parse, validation, execution and resolvers; operation, resolver and error metrics
+	gqlHandler.Schema.AddExtensions(commonTracing.GraphQLExtension(), promMetrics.GraphQLExtension())
 	graphqlHTTPHandler := handler.New(&handler.Config{
 		Schema:   &gqlHandler.Schema,
 		Pretty:   true,
 		GraphiQL: true, // Enable GraphiQL UI at /graphql
 	})
-	r.Handle("/graphql", graphqlHTTPHandler)
+	r.Handle("/graphql", authenticator.Middleware(graphqlHTTPHandler))
+
+	// Public signing keys so other services can verify tokens without a shared secret
+	r.Handle("/.well-known/jwks.json", keySet.JWKSHandler()).Methods(http.MethodGet)
+
+	// Runtime log levels, e.g. PUT {"level":"debug","packages":{"repository":"debug"}}
+	r.Handle("/admin/loglevel", authenticator.Middleware(policy.RequirePermission(commonAuth.PermLoggingAdmin)(commonLogger.LevelHandler(logConfig.Levels)))).Methods(http.MethodGet, http.MethodPut)
 
 	// Prometheus metrics endpoint
 	r.Handle("/metrics", promMetrics.Handler()) // Assuming your metrics lib provides an http.Handler
 
+	// Liveness and readiness; /ready answers 503 while the database is unreachable, always OK with in-memory storage
+	r.HandleFunc("/health", func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+		w.Write([]byte("OK"))
+	}).Methods("GET")
+	r.Handle("/ready", store.ready).Methods("GET")
+
 	port := os.Getenv("PORT")
 	if port == "" {
 		port = "8081" // Default port for user service
@@ -106,4 +199,4 @@ func main() {
 		appLogger.Error(err, "Failed to start server")
 		log.Fatalf("Failed to start server: %v", err)
 	}
-}
\ No newline at end of file
+}
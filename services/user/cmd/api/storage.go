@@ -0,0 +1,141 @@
+package main
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	commonLogger "github.com/omni-compos/digital-mono/libs/logger"
+
+	userMigrations "github.com/omni-compos/digital-mono/services/user/internal/migrations"
+	userRepo "github.com/omni-compos/digital-mono/services/user/internal/repository"
+)
+
+// storage is what the service persists to: Postgres, or process memory with STORAGE=memory.
+type storage struct {
+	repo        userRepo.UserRepository
+	tokens      userRepo.RefreshTokenRepository
+	clients     userRepo.ServiceClientRepository
+	apiKeys     userRepo.APIKeyRepository
+	apiKeyStore commonAuth.APIKeyStore // Authenticates the keys stored in apiKeys
+	revocations commonAuth.RevocationStore
+	tx          commonDB.Transactor
+	outbox      *commonDB.Outbox // nil in memory; domain events are not recorded
+	db          *sql.DB          // nil in memory
+	ready       http.Handler
+	close       func()
+}
+
+// openStorage returns the storage selected by STORAGE (postgres|memory), defaulting to postgres,
+// with the bootstrap client from BOOTSTRAP_CLIENT_ID seeded (see seedBootstrapClient).
+func openStorage(ctx context.Context, appLogger commonLogger.Logger, queryMetrics *commonDB.QueryMetrics) (*storage, error) {
+	var store *storage
+	switch mode := os.Getenv("STORAGE"); mode {
+	case "", "postgres":
+		var err error
+		if store, err = newPostgresStorage(ctx, appLogger, queryMetrics); err != nil {
+			return nil, err
+		}
+	case "memory":
+		appLogger.Info("Using in-memory storage; data is lost on restart")
+		store = newMemoryStorage()
+	default:
+		return nil, fmt.Errorf("unknown storage %q, want postgres or memory", mode)
+	}
+	if err := seedBootstrapClient(ctx, store.clients, appLogger); err != nil {
+		store.close()
+		return nil, err
+	}
+	return store, nil
+}
+
+// newMemoryStorage needs no database, for local development and demos. Its only service client is
+// the bootstrap client, if BOOTSTRAP_CLIENT_ID is set.
+func newMemoryStorage() *storage {
+	apiKeys := userRepo.NewMemoryAPIKeyRepository()
+	return &storage{
+		repo:        userRepo.NewMemoryUserRepository(),
+		tokens:      userRepo.NewMemoryRefreshTokenRepository(),
+		clients:     userRepo.NewMemoryServiceClientRepository(),
+		apiKeys:     apiKeys,
+		apiKeyStore: apiKeys,
+		revocations: commonAuth.NewMemoryRevocationStore(),
+		tx:          commonDB.NopTransactor{},
+		ready: http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+			w.WriteHeader(http.StatusOK)
+			w.Write([]byte("OK"))
+		}),
+		close: func() {},
+	}
+}
+
+// newPostgresStorage connects to the database configured by DB_* (see libs/database ConfigFromEnv),
+// migrates it and starts the outbox relay.
+func newPostgresStorage(ctx context.Context, appLogger commonLogger.Logger, queryMetrics *commonDB.QueryMetrics) (*storage, error) {
+	dbConfig, err := commonDB.ConfigFromEnv()
+	if err != nil {
+		return nil, fmt.Errorf("invalid database configuration: %w", err)
+	}
+	if os.Getenv("DB_DSN") == "" && os.Getenv("DB_HOST") == "" {
+		log.Println("Warning: DB_DSN not set, using local development database for user service.")
+	}
+
+	// Waits for Postgres to accept connections, e.g. while it starts next to the service
+	dbConfig.OnRetry = func(attempt int, wait time.Duration, err error) {
+		appLogger.Warn(err, "Database not reachable yet, retrying", "attempt", attempt, "wait", wait.String())
+	}
+	db, err := commonDB.NewPgxDB(ctx, dbConfig)
+	if err != nil {
+		return nil, fmt.Errorf("failed to connect to database: %w", err)
+	}
+	appLogger.Info("Successfully connected to database")
+
+	// Schema migrations embedded in the service; DB_MIGRATE_ON_STARTUP=false leaves them to cmd/migrate
+	if os.Getenv("DB_MIGRATE_ON_STARTUP") != "false" {
+		migrator, err := commonDB.NewMigrator(db, userMigrations.FS, userMigrations.Table)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to load database migrations: %w", err)
+		}
+		applied, err := migrator.Up(ctx)
+		if err != nil {
+			db.Close()
+			return nil, fmt.Errorf("failed to migrate database: %w", err)
+		}
+		appLogger.Info("Database schema is up to date", "applied_migrations", len(applied))
+	}
+
+	// Domain events are written with each change and published by the relay; logged until a broker is wired in
+	outbox, err := commonDB.NewOutbox(db, userMigrations.OutboxTable)
+	if err != nil {
+		db.Close()
+		return nil, fmt.Errorf("failed to create outbox: %w", err)
+	}
+	relay := commonDB.NewRelay(outbox, commonDB.PublisherFunc(func(ctx context.Context, event commonDB.OutboxEvent) error {
+		appLogger.Info("Published domain event", "event_id", event.ID, "event_type", event.Type, "aggregate_id", event.AggregateID)
+		return nil
+	}), commonDB.RelayConfig{OnError: func(err error) { appLogger.Error(err, "Outbox relay failed") }})
+	go relay.Run(ctx)
+
+	return &storage{
+		repo:    userRepo.NewPGUserRepository(db, queryMetrics),
+		tokens:  userRepo.NewPGRefreshTokenRepository(db, queryMetrics),
+		clients: userRepo.NewPGServiceClientRepository(db, queryMetrics),
+		apiKeys: userRepo.NewPGAPIKeyRepository(db, queryMetrics),
+		// Other services read the revocation list and API keys from the same tables
+		apiKeyStore: commonAuth.NewSQLAPIKeyStore(db),
+		revocations: commonAuth.NewSQLRevocationStore(db),
+		tx:          commonDB.NewTxManager(db, commonDB.TxOptions{}),
+		outbox:      outbox,
+		db:          db,
+		// Readiness: 503 while the database is unreachable
+		ready: commonDB.ReadinessHandler(db),
+		close: func() { db.Close() },
+	}, nil
+}
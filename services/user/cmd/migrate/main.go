@@ -0,0 +1,40 @@
+// Command migrate applies or reverts the user service's schema migrations, e.g. from a deploy job
+// when the service runs with DB_MIGRATE_ON_STARTUP=false.
+//
+//	migrate up | down [steps] | status
+package main
+
+import (
+	"context"
+	"errors"
+	"log"
+	"os"
+
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+
+	userMigrations "github.com/omni-compos/digital-mono/services/user/internal/migrations"
+)
+
+func main() {
+	// Same DB_* settings as the API
+	dbConfig, err := commonDB.ConfigFromEnv()
+	if err != nil {
+		log.Fatalf("Invalid database configuration: %v", err)
+	}
+	db, err := commonDB.NewPgxDB(context.Background(), dbConfig)
+	if err != nil {
+		log.Fatalf("Failed to connect to database: %v", err)
+	}
+	defer db.Close()
+
+	migrator, err := commonDB.NewMigrator(db, userMigrations.FS, userMigrations.Table)
+	if err != nil {
+		log.Fatalf("Failed to load database migrations: %v", err)
+	}
+	if err := commonDB.RunMigrateCommand(context.Background(), migrator, os.Args[1:], os.Stdout); err != nil {
+		if errors.Is(err, commonDB.ErrUsage) {
+			log.Fatal(err)
+		}
+		log.Fatalf("Migration failed: %v", err)
+	}
+}
@@ -2,6 +2,10 @@ package domain
 
 import "time"
 
+// LogRedactedKeys are the log field keys, beyond the logger's defaults, that hold users' personal
+// data (full names); they are masked in every log line.
+var LogRedactedKeys = []string{"name"}
+
 // User represents a user in the system.
 type User struct {
 	ID        string    `json:"id"`
@@ -10,13 +14,86 @@ type User struct {
 	CreatedAt time.Time `json:"created_at"`
 	UpdatedAt time.Time `json:"updated_at"`
 	Roles	  []string  `json:"roles"`
+	Brands    []string  `json:"brands,omitempty"` // Tenants the user may act on, "*" for all
 }
 type LoginRequest struct {
 	Email    string `json:"email"`
 	Password string `json:"password"`
 }
 
-// LoginResponse represents the response body for the login endpoint.
+// LoginResponse represents the response body for the login and token refresh endpoints.
 type LoginResponse struct {
-	Token string `json:"token"`
-}
\ No newline at end of file
+	Token        string `json:"token"`
+	RefreshToken string `json:"refresh_token"`
+	TokenType    string `json:"token_type"`
+	ExpiresIn    int64  `json:"expires_in"` // Access token lifetime in seconds
+}
+
+// RefreshTokenRequest represents the request body for the token refresh and logout endpoints.
+type RefreshTokenRequest struct {
+	RefreshToken string `json:"refresh_token"`
+}
+
+// RefreshToken is a long-lived, single-use credential that can be exchanged for a new access token.
+// Only the SHA-256 hash of the token is stored. Every refresh rotates the token; all tokens
+// descending from the same login share a FamilyID so reuse of a rotated token can revoke the chain.
+type RefreshToken struct {
+	ID         string
+	UserID     string
+	Roles      []string
+	Brands     []string
+	TokenHash  string
+	FamilyID   string
+	ExpiresAt  time.Time
+	CreatedAt  time.Time
+	RevokedAt  *time.Time
+	ReplacedBy string
+}
+// ServiceClient is a machine principal (e.g. a BFF) allowed to use the client-credentials grant.
+// Only the SHA-256 hash of the client secret is stored.
+type ServiceClient struct {
+	ClientID         string
+	Name             string
+	SecretHash       string
+	AllowedScopes    []string
+	AllowedAudiences []string
+	AllowedBrands    []string // Tenants carried by the client's tokens, "*" for all
+	Disabled         bool
+	CreatedAt        time.Time
+}
+
+// ClientTokenResponse is the OAuth 2.0 token response for the client-credentials grant.
+type ClientTokenResponse struct {
+	AccessToken string `json:"access_token"`
+	TokenType   string `json:"token_type"`
+	ExpiresIn   int64  `json:"expires_in"`
+	Scope       string `json:"scope"`
+}
+
+// APIKey is a long-lived, scoped credential for partner integrations and batch jobs.
+// Only the SHA-256 hash of the key is stored; Prefix is kept so users can tell their keys apart.
+type APIKey struct {
+	ID        string     `json:"id"`
+	OwnerID   string     `json:"owner_id"`
+	Name      string     `json:"name"`
+	Prefix    string     `json:"prefix"`
+	KeyHash   string     `json:"-"`
+	Scopes    []string   `json:"scopes"`
+	Brands    []string   `json:"brands"` // Copied from the owner's token when the key is issued
+	ExpiresAt time.Time  `json:"expires_at"`
+	CreatedAt time.Time  `json:"created_at"`
+	RevokedAt *time.Time `json:"revoked_at,omitempty"`
+}
+
+// CreateAPIKeyRequest represents the request body for issuing an API key.
+type CreateAPIKeyRequest struct {
+	Name          string   `json:"name"`
+	Scopes        []string `json:"scopes"`
+	ExpiresInDays int      `json:"expires_in_days"` // Defaults to 90, at most 365
+}
+
+// CreateAPIKeyResponse returns the raw key; it is shown only once.
+type CreateAPIKeyResponse struct {
+	Key string `json:"key"`
+	*APIKey
+}
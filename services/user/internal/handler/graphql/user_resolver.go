@@ -2,6 +2,7 @@ package graphql
 
 import (
 	"github.com/graphql-go/graphql"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	"github.com/omni-compos/digital-mono/libs/logger"
 
 	//"github.com/omni-compos/digital-mono/services/user/internal/domain"
@@ -17,6 +18,7 @@ func init() {
 			"id":        &graphql.Field{Type: graphql.NewNonNull(graphql.ID)},
 			"name":      &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
 			"email":     &graphql.Field{Type: graphql.NewNonNull(graphql.String)},
+			"brands":    &graphql.Field{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
 			"createdAt": &graphql.Field{Type: graphql.NewNonNull(graphql.String)}, // Simplification
 			"updatedAt": &graphql.Field{Type: graphql.NewNonNull(graphql.String)}, // Simplification
 		},
@@ -31,7 +33,7 @@ type UserGraphQLHandler struct {
 }
 
 // NewUserGraphQLHandler creates a new GraphQL handler for users.
-func NewUserGraphQLHandler(userService service.UserService, log logger.Logger) (*UserGraphQLHandler, error) {
+func NewUserGraphQLHandler(userService service.UserService, log logger.Logger, policy *commonAuth.Policy) (*UserGraphQLHandler, error) {
 	rootQuery := graphql.NewObject(graphql.ObjectConfig{
 		Name: "RootQuery",
 		Fields: graphql.Fields{
@@ -40,13 +42,13 @@ func NewUserGraphQLHandler(userService service.UserService, log logger.Logger) (
 				Args: graphql.FieldConfigArgument{
 					"id": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.ID)},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermUsersRead, func(p graphql.ResolveParams) (interface{}, error) {
 					id, ok := p.Args["id"].(string)
 					if !ok {
 						return nil, nil // Or an error
 					}
 					return userService.GetUser(p.Context, id)
-				},
+				}),
 			},
 		},
 	})
@@ -57,13 +59,20 @@ func NewUserGraphQLHandler(userService service.UserService, log logger.Logger) (
 			"createUser": &graphql.Field{
 				Type: userType,
 				Args: graphql.FieldConfigArgument{
-					"name":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
-					"email": &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"name":   &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"email":  &graphql.ArgumentConfig{Type: graphql.NewNonNull(graphql.String)},
+					"brands": &graphql.ArgumentConfig{Type: graphql.NewList(graphql.NewNonNull(graphql.String))},
 				},
-				Resolve: func(p graphql.ResolveParams) (interface{}, error) {
+				Resolve: policy.RequirePermissionResolver(commonAuth.PermUsersWrite, func(p graphql.ResolveParams) (interface{}, error) {
 					name := p.Args["name"].(string)
 					email := p.Args["email"].(string)
-					user, err := userService.CreateUser(p.Context, name, email)
+					var brands []string
+					if list, ok := p.Args["brands"].([]interface{}); ok {
+						for _, brand := range list {
+							brands = append(brands, brand.(string))
+						}
+					}
+					user, err := userService.CreateUser(p.Context, name, email, brands)
 					if err != nil {
 						log.Error(err, "GraphQL: Failed to create user")
 						return nil, err
@@ -71,7 +80,7 @@ func NewUserGraphQLHandler(userService service.UserService, log logger.Logger) (
 					// Map domain.User to a struct that graphql-go can serialize easily if needed,
 					// or ensure domain.User fields match graphql.Fields (which they do here).
 					return user, nil
-				},
+				}),
 			},
 		},
 	})
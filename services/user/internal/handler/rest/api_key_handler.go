@@ -0,0 +1,106 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+
+	"github.com/gorilla/mux"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+)
+
+// APIKeyRESTHandler lets users manage their own API keys.
+type APIKeyRESTHandler struct {
+	service service.APIKeyService
+	logger  logger.Logger
+}
+
+// NewAPIKeyRESTHandler creates a new APIKeyRESTHandler.
+func NewAPIKeyRESTHandler(apiKeyService service.APIKeyService, log logger.Logger) *APIKeyRESTHandler {
+	return &APIKeyRESTHandler{service: apiKeyService, logger: log}
+}
+
+// RegisterProtectedRoutes registers the API key endpoints; they require an authenticated user.
+func (h *APIKeyRESTHandler) RegisterProtectedRoutes(r *mux.Router) {
+	r.HandleFunc("/api-keys", h.CreateAPIKey).Methods(http.MethodPost)
+	r.HandleFunc("/api-keys", h.ListAPIKeys).Methods(http.MethodGet)
+	r.HandleFunc("/api-keys/{id}", h.RevokeAPIKey).Methods(http.MethodDelete)
+}
+
+// CreateAPIKey handles POST /api-keys. The raw key is only returned in this response.
+func (h *APIKeyRESTHandler) CreateAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, ok := commonAuth.GetClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.CreateAPIKeyRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	key, err := h.service.CreateAPIKey(r.Context(), claims, &req)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidAPIKeyRequest):
+			http.Error(w, "Invalid API key request: name and scopes are required, expires_in_days must be between 1 and 365", http.StatusBadRequest)
+		case errors.Is(err, service.ErrAPIKeyNotAllowed):
+			http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+		default:
+			logger.FromContext(r.Context(), h.logger).Error(err, "Failed to create API key", "userID", claims.UserID)
+			http.Error(w, "Failed to create API key", http.StatusInternalServerError)
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	w.WriteHeader(http.StatusCreated)
+	json.NewEncoder(w).Encode(key)
+}
+
+// ListAPIKeys handles GET /api-keys, returning the caller's keys without their secrets.
+func (h *APIKeyRESTHandler) ListAPIKeys(w http.ResponseWriter, r *http.Request) {
+	claims, ok := commonAuth.GetClaimsFromContext(r.Context())
+	if !ok || claims.IsScopeLimited() {
+		http.Error(w, "Forbidden: only users can manage API keys", http.StatusForbidden)
+		return
+	}
+
+	keys, err := h.service.ListAPIKeys(r.Context(), claims.UserID)
+	if err != nil {
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to list API keys", "userID", claims.UserID)
+		http.Error(w, "Failed to list API keys", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(keys)
+}
+
+// RevokeAPIKey handles DELETE /api-keys/{id}.
+func (h *APIKeyRESTHandler) RevokeAPIKey(w http.ResponseWriter, r *http.Request) {
+	claims, ok := commonAuth.GetClaimsFromContext(r.Context())
+	if !ok || claims.IsScopeLimited() {
+		http.Error(w, "Forbidden: only users can manage API keys", http.StatusForbidden)
+		return
+	}
+
+	id := mux.Vars(r)["id"]
+	if err := h.service.RevokeAPIKey(r.Context(), claims.UserID, id); err != nil {
+		if errors.Is(err, service.ErrAPIKeyNotFound) {
+			http.Error(w, "API key not found", http.StatusNotFound)
+			return
+		}
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to revoke API key", "userID", claims.UserID, "keyID", id)
+		http.Error(w, "Failed to revoke API key", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
@@ -0,0 +1,91 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/url"
+	"strings"
+
+	"github.com/gorilla/mux"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+)
+
+// ClientCredentialsRESTHandler implements the OAuth 2.0 client-credentials token endpoint for service principals.
+type ClientCredentialsRESTHandler struct {
+	service service.ClientCredentialsService
+	logger  logger.Logger
+}
+
+// NewClientCredentialsRESTHandler creates a new ClientCredentialsRESTHandler.
+func NewClientCredentialsRESTHandler(clientService service.ClientCredentialsService, log logger.Logger) *ClientCredentialsRESTHandler {
+	return &ClientCredentialsRESTHandler{service: clientService, logger: log}
+}
+
+// RegisterRoutes registers the token endpoint. It is public; clients authenticate with their credentials.
+func (h *ClientCredentialsRESTHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/oauth/token", h.Token).Methods(http.MethodPost)
+}
+
+// Token handles POST /oauth/token with grant_type=client_credentials.
+// Credentials are read from HTTP Basic auth, form-urlencoded as RFC 6749 §2.3.1 requires, or the
+// client_id/client_secret form fields; audience and scope are space separated lists.
+func (h *ClientCredentialsRESTHandler) Token(w http.ResponseWriter, r *http.Request) {
+	if err := r.ParseForm(); err != nil {
+		h.oauthError(w, http.StatusBadRequest, "invalid_request")
+		return
+	}
+	if r.PostForm.Get("grant_type") != "client_credentials" {
+		h.oauthError(w, http.StatusBadRequest, "unsupported_grant_type")
+		return
+	}
+
+	clientID, clientSecret, ok := r.BasicAuth()
+	if ok {
+		var idErr, secretErr error
+		clientID, idErr = url.QueryUnescape(clientID)
+		clientSecret, secretErr = url.QueryUnescape(clientSecret)
+		if idErr != nil || secretErr != nil {
+			h.oauthError(w, http.StatusUnauthorized, "invalid_client")
+			return
+		}
+	} else {
+		clientID, clientSecret = r.PostForm.Get("client_id"), r.PostForm.Get("client_secret")
+	}
+	if clientID == "" || clientSecret == "" {
+		h.oauthError(w, http.StatusUnauthorized, "invalid_client")
+		return
+	}
+
+	audience := strings.Fields(r.PostForm.Get("audience"))
+	scopes := strings.Fields(r.PostForm.Get("scope"))
+
+	token, err := h.service.IssueClientToken(r.Context(), clientID, clientSecret, audience, scopes)
+	if err != nil {
+		switch {
+		case errors.Is(err, service.ErrInvalidClient):
+			logger.FromContext(r.Context(), h.logger).Warn(err, "Client authentication failed", "client_id", clientID)
+			h.oauthError(w, http.StatusUnauthorized, "invalid_client")
+		case errors.Is(err, service.ErrInvalidScope):
+			h.oauthError(w, http.StatusBadRequest, "invalid_scope")
+		default:
+			logger.FromContext(r.Context(), h.logger).Error(err, "Failed to issue client token", "client_id", clientID)
+			h.oauthError(w, http.StatusInternalServerError, "server_error")
+		}
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(token)
+}
+
+func (h *ClientCredentialsRESTHandler) oauthError(w http.ResponseWriter, status int, code string) {
+	w.Header().Set("Content-Type", "application/json")
+	if status == http.StatusUnauthorized {
+		w.Header().Set("WWW-Authenticate", `Basic realm="oauth"`)
+	}
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(map[string]string{"error": code})
+}
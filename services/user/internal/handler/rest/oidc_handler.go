@@ -0,0 +1,84 @@
+package rest
+
+import (
+	"encoding/json"
+	"errors"
+	"net/http"
+	"strings"
+
+	"github.com/gorilla/mux"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+)
+
+// oidcLoginCookie holds "<state>.<nonce>" between the login redirect and the callback.
+const oidcLoginCookie = "oidc_login"
+
+// OIDCRESTHandler implements login through an external OpenID Connect provider.
+type OIDCRESTHandler struct {
+	service service.OIDCLoginService
+	logger  logger.Logger
+}
+
+// NewOIDCRESTHandler creates a new OIDCRESTHandler.
+func NewOIDCRESTHandler(oidcService service.OIDCLoginService, log logger.Logger) *OIDCRESTHandler {
+	return &OIDCRESTHandler{service: oidcService, logger: log}
+}
+
+// RegisterRoutes registers the public login and callback endpoints.
+func (h *OIDCRESTHandler) RegisterRoutes(r *mux.Router) {
+	r.HandleFunc("/oidc/login", h.Login).Methods(http.MethodGet)
+	r.HandleFunc("/oidc/callback", h.Callback).Methods(http.MethodGet)
+}
+
+// Login handles GET /oidc/login by redirecting the browser to the IdP.
+func (h *OIDCRESTHandler) Login(w http.ResponseWriter, r *http.Request) {
+	state, nonce := commonAuth.NewTokenID(), commonAuth.NewTokenID()
+	http.SetCookie(w, &http.Cookie{
+		Name:     oidcLoginCookie,
+		Value:    state + "." + nonce,
+		Path:     "/",
+		MaxAge:   600,
+		HttpOnly: true,
+		Secure:   r.TLS != nil,
+		SameSite: http.SameSiteLaxMode,
+	})
+	http.Redirect(w, r, h.service.LoginURL(state, nonce), http.StatusFound)
+}
+
+// Callback handles GET /oidc/callback, exchanging the code for the service's own tokens.
+func (h *OIDCRESTHandler) Callback(w http.ResponseWriter, r *http.Request) {
+	query := r.URL.Query()
+	if idpError := query.Get("error"); idpError != "" {
+		http.Error(w, "Login failed: "+idpError, http.StatusUnauthorized)
+		return
+	}
+
+	cookie, err := r.Cookie(oidcLoginCookie)
+	state, nonce, found := "", "", false
+	if err == nil {
+		state, nonce, found = strings.Cut(cookie.Value, ".")
+	}
+	if !found || state == "" || query.Get("state") != state || query.Get("code") == "" {
+		http.Error(w, "Invalid login state", http.StatusBadRequest)
+		return
+	}
+	http.SetCookie(w, &http.Cookie{Name: oidcLoginCookie, Path: "/", MaxAge: -1})
+
+	tokens, err := h.service.CompleteLogin(r.Context(), query.Get("code"), nonce)
+	if err != nil {
+		if errors.Is(err, service.ErrOIDCLoginFailed) {
+			logger.FromContext(r.Context(), h.logger).Warn(err, "OIDC login failed")
+			http.Error(w, "Login failed", http.StatusUnauthorized)
+			return
+		}
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to issue tokens for OIDC login")
+		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.Header().Set("Cache-Control", "no-store")
+	json.NewEncoder(w).Encode(tokens)
+}
@@ -2,14 +2,12 @@ package rest
 
 import (
 	"encoding/json"
+	"errors"
 	"net/http"
-	"strconv"
-	"time"
 
 	"github.com/gorilla/mux"
 	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	"github.com/omni-compos/digital-mono/libs/logger"
-	"github.com/omni-compos/digital-mono/libs/metrics"
 	"github.com/omni-compos/digital-mono/services/user/internal/domain"
 	"github.com/omni-compos/digital-mono/services/user/internal/service"
 )
@@ -17,41 +15,43 @@ import (
 // UserRESTHandler handles HTTP requests for users.
 type UserRESTHandler struct {
 	service service.UserService
-	jwtSecret    string
+	tokens  service.TokenService
 	logger  logger.Logger
-	metrics metrics.PrometheusMetrics // Using the common metrics interface
+	policy  *commonAuth.Policy
 }
 
 // NewUserRESTHandler creates a new UserRESTHandler.
-func NewUserRESTHandler(userService service.UserService, jwtSecret string, log logger.Logger, promMetrics metrics.PrometheusMetrics) *UserRESTHandler {
-	return &UserRESTHandler{service: userService,jwtSecret: jwtSecret, logger: log, metrics: promMetrics}
+func NewUserRESTHandler(userService service.UserService, tokenService service.TokenService, log logger.Logger, policy *commonAuth.Policy) *UserRESTHandler {
+	return &UserRESTHandler{service: userService, tokens: tokenService, logger: log, policy: policy}
 }
 
 type CreateUserRequest struct {
-	Name  string `json:"name"`
-	Email string `json:"email"`
+	Name   string   `json:"name"`
+	Email  string   `json:"email"`
+	Brands []string `json:"brands"` // Tenants the user may act on, within the caller's own
 }
 
 func (h *UserRESTHandler) CreateUserHandler(w http.ResponseWriter, r *http.Request) {
 	var req CreateUserRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
 		http.Error(w, "Invalid request body", http.StatusBadRequest)
-		h.metrics.IncResponsesTotal("createUser", "rest", strconv.Itoa(http.StatusBadRequest))
 		return
 	}
 
-	user, err := h.service.CreateUser(r.Context(), req.Name, req.Email)
+	user, err := h.service.CreateUser(r.Context(), req.Name, req.Email, req.Brands)
+	if errors.Is(err, service.ErrBrandNotAllowed) {
+		http.Error(w, "Forbidden: "+err.Error(), http.StatusForbidden)
+		return
+	}
 	if err != nil {
-		h.logger.Error(err, "Failed to create user")
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to create user")
 		http.Error(w, "Failed to create user", http.StatusInternalServerError)
-		h.metrics.IncResponsesTotal("createUser", "rest", strconv.Itoa(http.StatusInternalServerError))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusCreated)
 	json.NewEncoder(w).Encode(user)
-	h.metrics.IncResponsesTotal("createUser", "rest", strconv.Itoa(http.StatusCreated))
 }
 
 func (h *UserRESTHandler) GetUserHandler(w http.ResponseWriter, r *http.Request) {
@@ -61,18 +61,15 @@ func (h *UserRESTHandler) GetUserHandler(w http.ResponseWriter, r *http.Request)
 	user, err := h.service.GetUser(r.Context(), id)
 	if err != nil { // Handle not found specifically if service returns a specific error
 		http.Error(w, "Failed to get user", http.StatusInternalServerError)
-		h.metrics.IncResponsesTotal("getUser", "rest", strconv.Itoa(http.StatusInternalServerError))
 		return
 	}
 	if user == nil {
 		http.Error(w, "User not found", http.StatusNotFound)
-		h.metrics.IncResponsesTotal("getUser", "rest", strconv.Itoa(http.StatusNotFound))
 		return
 	}
 
 	w.Header().Set("Content-Type", "application/json")
 	json.NewEncoder(w).Encode(user)
-	h.metrics.IncResponsesTotal("getUser", "rest", strconv.Itoa(http.StatusOK))
 }
 
  
@@ -82,8 +79,7 @@ func (h *UserRESTHandler) RegisterRoutes(r *mux.Router) {
 	// Public routes 
 
 	r.HandleFunc("/login", h.Login).Methods(http.MethodPost)  
-	r.HandleFunc("/users", h.CreateUserHandler).Methods(http.MethodPost)
-	r.HandleFunc("/users/{id}", h.GetUserHandler).Methods(http.MethodGet)
+	r.HandleFunc("/token/refresh", h.RefreshToken).Methods(http.MethodPost)
 	// r.HandleFunc("/users", h.CreateUser).Methods("POST")
 	// r.HandleFunc("/users/{id}", h.GetUserByID).Methods("GET")
 	// r.HandleFunc("/users/{id}", h.UpdateUser).Methods("PUT")
@@ -91,46 +87,118 @@ func (h *UserRESTHandler) RegisterRoutes(r *mux.Router) {
 	// r.HandleFunc("/users", h.ListUsers).Methods("GET")
 }
 
-// Login handles POST /login requests.
+// RegisterProtectedRoutes registers the REST endpoints that require a valid access token.
+func (h *UserRESTHandler) RegisterProtectedRoutes(r *mux.Router) {
+	r.HandleFunc("/logout", h.Logout).Methods(http.MethodPost)
+	// Creating users needs users:write, as the GraphQL createUser mutation does
+	r.Handle("/users", h.policy.RequirePermission(commonAuth.PermUsersWrite)(http.HandlerFunc(h.CreateUserHandler))).Methods(http.MethodPost)
+	r.Handle("/users/{id}", h.policy.RequirePermission(commonAuth.PermUsersRead)(http.HandlerFunc(h.GetUserHandler))).Methods(http.MethodGet)
+	r.Handle("/users/{id}/sessions/revoke", h.policy.RequirePermission(commonAuth.PermUsersAdmin)(http.HandlerFunc(h.RevokeUserSessions))).Methods(http.MethodPost)
+}
+
+// Login handles POST /login requests. It answers 501 while password login is disabled (see
+// UserService.AuthenticateUser); users sign in through OIDC instead.
 func (h *UserRESTHandler) Login(w http.ResponseWriter, r *http.Request) {
-	h.logger.Info("Authentication 0")
-	h.metrics.IncRequestsTotal("login", "rest") // Corrected: 2 arguments
-	timer := h.metrics.NewRequestDurationTimer("login", "rest")
-	defer timer.ObserveDuration()
+	logger.FromContext(r.Context(), h.logger).Info("Authentication 0")
 
 	var req *domain.LoginRequest
 	if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
-		h.logger.Error(err, "Failed to decode login request body")
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to decode login request body")
 		http.Error(w, "Invalid request payload", http.StatusBadRequest)
-		h.metrics.IncResponsesTotal("login", "rest", strconv.Itoa(http.StatusBadRequest))
 		return
 	}
 
 	// Authenticate user using the service layer
 	user, err := h.service.AuthenticateUser(r.Context(), req.Email, req.Password)
+	if errors.Is(err, service.ErrPasswordLoginDisabled) {
+		http.Error(w, "Password login is not available; sign in through /api/v1/oidc/login", http.StatusNotImplemented)
+		return
+	}
 	if err != nil {
-		h.logger.Warn(err,"Authentication failed", "email", req.Email, "error")
-		http.Error(w, "Invalid credentials", http.StatusUnauthorized)
-		h.metrics.IncResponsesTotal("login", "rest", strconv.Itoa(http.StatusUnauthorized))
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to authenticate user")
+		http.Error(w, "Failed to authenticate", http.StatusInternalServerError)
 		return
 	}
  
-	// Generate JWT token
-	// Use a reasonable expiration time, e.g., 24 hours
-
-	authenticator := commonAuth.NewJWTAuthenticator(h.jwtSecret)
- 
-	token, err := authenticator.GenerateToken(user.ID, user.Roles, 24*time.Hour)
+	// Issue a short-lived access token plus a rotating refresh token
+	tokens, err := h.tokens.IssueTokens(r.Context(), user)
 	if err != nil {
-		h.logger.Error(err, "Failed to generate JWT token for user", "userID", user.ID)
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to issue tokens for user", "userID", user.ID)
 		http.Error(w, "Failed to generate token", http.StatusInternalServerError)
-		h.metrics.IncResponsesTotal("login", "rest", strconv.Itoa(http.StatusInternalServerError))
 		return
-	} 
+	}
 
 	// Return the token
 	w.Header().Set("Content-Type", "application/json")
 	w.WriteHeader(http.StatusOK)
-	json.NewEncoder(w).Encode(domain.LoginResponse{Token: token})
-	h.metrics.IncResponsesTotal("login", "rest", strconv.Itoa(http.StatusOK))
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// RefreshToken handles POST /token/refresh requests.
+func (h *UserRESTHandler) RefreshToken(w http.ResponseWriter, r *http.Request) {
+	var req domain.RefreshTokenRequest
+	if err := json.NewDecoder(r.Body).Decode(&req); err != nil || req.RefreshToken == "" {
+		http.Error(w, "Invalid request payload", http.StatusBadRequest)
+		return
+	}
+
+	tokens, err := h.tokens.RefreshTokens(r.Context(), req.RefreshToken)
+	if err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			http.Error(w, "Invalid refresh token", http.StatusUnauthorized)
+			return
+		}
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to refresh tokens")
+		http.Error(w, "Failed to refresh token", http.StatusInternalServerError)
+		return
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	json.NewEncoder(w).Encode(tokens)
+}
+
+// Logout handles POST /logout requests. The body may carry the session's refresh token.
+func (h *UserRESTHandler) Logout(w http.ResponseWriter, r *http.Request) {
+	claims, ok := commonAuth.GetClaimsFromContext(r.Context())
+	if !ok {
+		http.Error(w, "Unauthorized", http.StatusUnauthorized)
+		return
+	}
+
+	var req domain.RefreshTokenRequest
+	if r.ContentLength != 0 {
+		if err := json.NewDecoder(r.Body).Decode(&req); err != nil {
+			http.Error(w, "Invalid request payload", http.StatusBadRequest)
+			return
+		}
+	}
+
+	if err := h.tokens.Logout(r.Context(), claims, req.RefreshToken); err != nil {
+		if errors.Is(err, service.ErrInvalidRefreshToken) {
+			http.Error(w, "Invalid refresh token", http.StatusBadRequest)
+			return
+		}
+		if errors.Is(err, service.ErrNotASession) {
+			http.Error(w, "Only session tokens can be logged out", http.StatusBadRequest)
+			return
+		}
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to log out user", "userID", claims.UserID)
+		http.Error(w, "Failed to log out", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
+}
+
+// RevokeUserSessions handles POST /users/{id}/sessions/revoke, e.g. when an account is compromised.
+// The user's refresh tokens and the access tokens issued to them so far stop working at once.
+func (h *UserRESTHandler) RevokeUserSessions(w http.ResponseWriter, r *http.Request) {
+	id := mux.Vars(r)["id"]
+	if err := h.tokens.RevokeUserSessions(r.Context(), id); err != nil {
+		logger.FromContext(r.Context(), h.logger).Error(err, "Failed to revoke user sessions", "userID", id)
+		http.Error(w, "Failed to revoke sessions", http.StatusInternalServerError)
+		return
+	}
+
+	w.WriteHeader(http.StatusNoContent)
 }
\ No newline at end of file
@@ -0,0 +1,25 @@
+-- Rotating refresh tokens issued by the user service
+CREATE TABLE IF NOT EXISTS refresh_tokens (
+    id VARCHAR(36) PRIMARY KEY,
+    user_id VARCHAR(255) NOT NULL, -- Local user ID or the subject from an external IdP
+    roles TEXT[] NOT NULL DEFAULT '{}',
+    brands TEXT[] NOT NULL DEFAULT '{}',
+    token_hash VARCHAR(64) UNIQUE NOT NULL,
+    family_id VARCHAR(36) NOT NULL,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    revoked_at TIMESTAMP WITH TIME ZONE,
+    replaced_by VARCHAR(36)
+);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_user_id ON refresh_tokens(user_id);
+CREATE INDEX IF NOT EXISTS idx_refresh_tokens_family_id ON refresh_tokens(family_id);
+-- Access token revocation list keyed by jti, read by every service
+CREATE TABLE IF NOT EXISTS revoked_tokens (
+    jti VARCHAR(64) PRIMARY KEY,
+    expires_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
+-- Tokens of a user issued before issued_before are revoked, e.g. after an account compromise
+CREATE TABLE IF NOT EXISTS revoked_user_tokens (
+    user_id VARCHAR(255) PRIMARY KEY,
+    issued_before TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,11 @@
+-- Client-credentials grant for BFFs and other services
+CREATE TABLE IF NOT EXISTS service_clients (
+    client_id VARCHAR(64) PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    secret_hash VARCHAR(64) NOT NULL,
+    allowed_scopes TEXT[] NOT NULL DEFAULT '{}',
+    allowed_audiences TEXT[] NOT NULL DEFAULT '{}',
+    allowed_brands TEXT[] NOT NULL DEFAULT '{}',
+    disabled BOOLEAN NOT NULL DEFAULT FALSE,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL
+);
@@ -0,0 +1,19 @@
+-- Domain events written in the same transaction as the change, published by the outbox relay
+CREATE TABLE IF NOT EXISTS user_outbox (
+    seq BIGSERIAL PRIMARY KEY,
+    id VARCHAR(36) UNIQUE NOT NULL, -- Event ID; consumers deduplicate on it
+    aggregate_type VARCHAR(50) NOT NULL,
+    aggregate_id VARCHAR(255) NOT NULL,
+    event_type VARCHAR(100) NOT NULL,
+    payload JSONB NOT NULL,
+    created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+    attempts INT NOT NULL DEFAULT 0,
+    last_error TEXT,
+    next_attempt_at TIMESTAMP WITH TIME ZONE,
+    published_at TIMESTAMP WITH TIME ZONE,
+    -- The transaction that wrote the event. Sequence values are taken at insert, not at commit, so the
+    -- relay publishes in (txid, seq) order and only events older than every running transaction.
+    txid xid8 NOT NULL DEFAULT pg_current_xact_id()
+);
+CREATE INDEX IF NOT EXISTS idx_user_outbox_pending ON user_outbox(txid, seq) WHERE published_at IS NULL;
+CREATE INDEX IF NOT EXISTS idx_user_outbox_published_at ON user_outbox(published_at) WHERE published_at IS NOT NULL;
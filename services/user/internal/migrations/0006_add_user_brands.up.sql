@@ -0,0 +1,3 @@
+-- The brands (tenants) each user may act on, for their tokens once password login is available; '*' for all.
+-- Existing users get none until an administrator grants them, so they cannot see any brand's data.
+ALTER TABLE users ADD COLUMN IF NOT EXISTS brands TEXT[] NOT NULL DEFAULT '{}';
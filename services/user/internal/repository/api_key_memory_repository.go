@@ -0,0 +1,100 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"sync"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+// MemoryAPIKeyRepository is an in-memory APIKeyRepository for local development and tests.
+// It also implements commonAuth.APIKeyStore, taking the place of SQLAPIKeyStore when the
+// service runs without a database.
+type MemoryAPIKeyRepository struct {
+	mu     sync.RWMutex
+	keys   map[string]*domain.APIKey
+	hashes map[string]string // Key hash to key ID, enforcing the unique key_hash column
+}
+
+// NewMemoryAPIKeyRepository creates an empty MemoryAPIKeyRepository.
+func NewMemoryAPIKeyRepository() *MemoryAPIKeyRepository {
+	return &MemoryAPIKeyRepository{keys: map[string]*domain.APIKey{}, hashes: map[string]string{}}
+}
+
+func (r *MemoryAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.keys[key.ID]; exists {
+		return fmt.Errorf("duplicate API key ID %s", key.ID)
+	}
+	if _, exists := r.hashes[key.KeyHash]; exists {
+		return fmt.Errorf("duplicate API key hash")
+	}
+	stored := copyAPIKey(key)
+	stored.RevokedAt = nil
+	r.keys[key.ID] = stored
+	r.hashes[key.KeyHash] = key.ID
+	return nil
+}
+
+// ListAPIKeysByOwner returns the owner's keys, newest first and without their hashes.
+func (r *MemoryAPIKeyRepository) ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*domain.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	keys := []*domain.APIKey{}
+	for _, key := range r.keys {
+		if key.OwnerID == ownerID {
+			listed := copyAPIKey(key)
+			listed.KeyHash = ""
+			keys = append(keys, listed)
+		}
+	}
+	sort.SliceStable(keys, func(i, j int) bool { return keys[i].CreatedAt.After(keys[j].CreatedAt) })
+	return keys, nil
+}
+
+func (r *MemoryAPIKeyRepository) RevokeAPIKey(ctx context.Context, id, ownerID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || key.OwnerID != ownerID || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return true, nil
+}
+
+// GetAPIKeyByHash implements commonAuth.APIKeyStore.
+func (r *MemoryAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*commonAuth.APIKey, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.hashes[keyHash]
+	if !ok {
+		return nil, nil
+	}
+	key := copyAPIKey(r.keys[id])
+	return &commonAuth.APIKey{
+		ID:        key.ID,
+		OwnerID:   key.OwnerID,
+		Scopes:    key.Scopes,
+		Brands:    key.Brands,
+		ExpiresAt: key.ExpiresAt,
+		RevokedAt: key.RevokedAt,
+	}, nil
+}
+
+func copyAPIKey(key *domain.APIKey) *domain.APIKey {
+	copied := *key
+	copied.Scopes = append([]string{}, key.Scopes...)
+	copied.Brands = append([]string{}, key.Brands...)
+	if key.RevokedAt != nil {
+		revokedAt := *key.RevokedAt
+		copied.RevokedAt = &revokedAt
+	}
+	return &copied
+}
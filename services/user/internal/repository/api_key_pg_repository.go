@@ -0,0 +1,101 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"strings"
+	"time"
+
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+// APIKeyRepository defines the interface for API key storage.
+// Lookups by hash for authentication go through commonAuth.SQLAPIKeyStore, which reads the same table.
+// repositorytest.RunAPIKeyRepositoryTests checks that an implementation keeps this contract.
+type APIKeyRepository interface {
+	CreateAPIKey(ctx context.Context, key *domain.APIKey) error
+	// ListAPIKeysByOwner returns the owner's keys newest first, without their hashes, and an
+	// empty slice if there are none.
+	ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*domain.APIKey, error)
+	// RevokeAPIKey reports false if the owner has no active key with that ID.
+	RevokeAPIKey(ctx context.Context, id, ownerID string) (bool, error)
+}
+
+type pgAPIKeyRepository struct {
+	db      *sql.DB
+	queries *database.QueryMetrics
+}
+
+// NewPGAPIKeyRepository creates a new PostgreSQL API key repository. queries may be nil.
+func NewPGAPIKeyRepository(db *sql.DB, queries *database.QueryMetrics) APIKeyRepository {
+	return &pgAPIKeyRepository{db: db, queries: queries}
+}
+
+func (r *pgAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	query := `INSERT INTO api_keys (id, owner_id, name, key_prefix, key_hash, scopes, brands, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9)`
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, key.ID, key.OwnerID, key.Name, key.Prefix, key.KeyHash, strings.Join(key.Scopes, " "), strings.Join(key.Brands, " "), key.ExpiresAt, key.CreatedAt)
+	r.queries.Observe("api_keys.create", start, err)
+	return err
+}
+
+func (r *pgAPIKeyRepository) ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*domain.APIKey, error) {
+	start := time.Now()
+	keys, err := r.listAPIKeysByOwner(ctx, ownerID)
+	r.queries.Observe("api_keys.list_by_owner", start, err)
+	return keys, err
+}
+
+func (r *pgAPIKeyRepository) listAPIKeysByOwner(ctx context.Context, ownerID string) ([]*domain.APIKey, error) {
+	query := `SELECT id, owner_id, name, key_prefix, scopes, brands, expires_at, created_at, revoked_at FROM api_keys WHERE owner_id = $1 ORDER BY created_at DESC`
+	rows, err := database.Executor(ctx, r.db).QueryContext(ctx, query, ownerID)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	keys := []*domain.APIKey{}
+	for rows.Next() {
+		key := &domain.APIKey{}
+		var scopes, brands string
+		var revokedAt sql.NullTime
+		if err := rows.Scan(&key.ID, &key.OwnerID, &key.Name, &key.Prefix, &scopes, &brands, &key.ExpiresAt, &key.CreatedAt, &revokedAt); err != nil {
+			return nil, err
+		}
+		key.Scopes = strings.Fields(scopes)
+		key.Brands = strings.Fields(brands)
+		if revokedAt.Valid {
+			key.RevokedAt = &revokedAt.Time
+		}
+		keys = append(keys, key)
+	}
+	return keys, rows.Err()
+}
+
+func (r *pgAPIKeyRepository) RevokeAPIKey(ctx context.Context, id, ownerID string) (bool, error) {
+	start := time.Now()
+	result, err := database.Executor(ctx, r.db).ExecContext(ctx, `UPDATE api_keys SET revoked_at = $3 WHERE id = $1 AND owner_id = $2 AND revoked_at IS NULL`, id, ownerID, time.Now())
+	r.queries.Observe("api_keys.revoke", start, err)
+	if err != nil {
+		return false, err
+	}
+	rowsAffected, err := result.RowsAffected()
+	if err != nil {
+		return false, err
+	}
+	return rowsAffected > 0, nil
+}
+
+// CREATE TABLE api_keys (
+//     id VARCHAR(36) PRIMARY KEY,
+//     owner_id VARCHAR(255) NOT NULL,
+//     name VARCHAR(255) NOT NULL,
+//     key_prefix VARCHAR(16) NOT NULL,
+//     key_hash VARCHAR(64) UNIQUE NOT NULL,
+//     scopes TEXT NOT NULL, -- Space separated
+//     brands TEXT NOT NULL DEFAULT '', -- Space separated
+//     expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//     revoked_at TIMESTAMP WITH TIME ZONE
+// );
@@ -0,0 +1,107 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+type memoryRefreshTokenRepository struct {
+	mu     sync.RWMutex
+	tokens map[string]*domain.RefreshToken
+	hashes map[string]string // Token hash to token ID, enforcing the unique token_hash column
+}
+
+// NewMemoryRefreshTokenRepository creates an in-memory refresh token repository for local development and tests.
+func NewMemoryRefreshTokenRepository() RefreshTokenRepository {
+	return &memoryRefreshTokenRepository{tokens: map[string]*domain.RefreshToken{}, hashes: map[string]string{}}
+}
+
+func (r *memoryRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.insert(token)
+}
+
+func (r *memoryRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.hashes[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	return copyRefreshToken(r.tokens[id]), nil
+}
+
+// RotateRefreshToken revokes and inserts under one lock, so concurrent refreshes cannot both succeed.
+func (r *memoryRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, replacement *domain.RefreshToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	old, ok := r.tokens[oldID]
+	if !ok || old.RevokedAt != nil {
+		return false, nil
+	}
+	if err := r.insert(replacement); err != nil {
+		return false, err
+	}
+	now := time.Now()
+	old.RevokedAt = &now
+	old.ReplacedBy = replacement.ID
+	return true, nil
+}
+
+func (r *memoryRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	return r.revokeWhere(func(token *domain.RefreshToken) bool { return token.ID == id })
+}
+
+func (r *memoryRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	return r.revokeWhere(func(token *domain.RefreshToken) bool { return token.FamilyID == familyID })
+}
+
+func (r *memoryRefreshTokenRepository) RevokeRefreshTokensForUser(ctx context.Context, userID string) error {
+	return r.revokeWhere(func(token *domain.RefreshToken) bool { return token.UserID == userID })
+}
+
+// insert stores a copy of token; the caller holds the write lock.
+func (r *memoryRefreshTokenRepository) insert(token *domain.RefreshToken) error {
+	if _, exists := r.tokens[token.ID]; exists {
+		return fmt.Errorf("duplicate refresh token ID %s", token.ID)
+	}
+	if _, exists := r.hashes[token.TokenHash]; exists {
+		return fmt.Errorf("duplicate refresh token hash")
+	}
+	stored := copyRefreshToken(token)
+	stored.RevokedAt = nil
+	stored.ReplacedBy = ""
+	r.tokens[token.ID] = stored
+	r.hashes[token.TokenHash] = token.ID
+	return nil
+}
+
+// revokeWhere revokes the active tokens matching match.
+func (r *memoryRefreshTokenRepository) revokeWhere(match func(*domain.RefreshToken) bool) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if token.RevokedAt == nil && match(token) {
+			revokedAt := now
+			token.RevokedAt = &revokedAt
+		}
+	}
+	return nil
+}
+
+func copyRefreshToken(token *domain.RefreshToken) *domain.RefreshToken {
+	copied := *token
+	copied.Roles = append([]string{}, token.Roles...)
+	copied.Brands = append([]string{}, token.Brands...)
+	if token.RevokedAt != nil {
+		revokedAt := *token.RevokedAt
+		copied.RevokedAt = &revokedAt
+	}
+	return &copied
+}
@@ -0,0 +1,134 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+// RefreshTokenRepository defines the interface for refresh token storage.
+// repositorytest.RunRefreshTokenRepositoryTests checks that an implementation keeps this contract.
+type RefreshTokenRepository interface {
+	CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error
+	// GetRefreshTokenByHash returns nil and no error if no token has the hash.
+	GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error)
+	// RotateRefreshToken revokes the old token and links it to its replacement. It reports false if
+	// the old token was already revoked, so concurrent refreshes cannot both succeed.
+	RotateRefreshToken(ctx context.Context, oldID string, replacement *domain.RefreshToken) (bool, error)
+	RevokeRefreshToken(ctx context.Context, id string) error
+	RevokeRefreshTokenFamily(ctx context.Context, familyID string) error
+	RevokeRefreshTokensForUser(ctx context.Context, userID string) error
+}
+
+type pgRefreshTokenRepository struct {
+	db      *sql.DB
+	tx      *database.TxManager
+	queries *database.QueryMetrics
+}
+
+// NewPGRefreshTokenRepository creates a new PostgreSQL refresh token repository. queries may be nil.
+func NewPGRefreshTokenRepository(db *sql.DB, queries *database.QueryMetrics) RefreshTokenRepository {
+	return &pgRefreshTokenRepository{db: db, tx: database.NewTxManager(db, database.TxOptions{}), queries: queries}
+}
+
+func (r *pgRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	query := `INSERT INTO refresh_tokens (id, user_id, roles, brands, token_hash, family_id, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, token.ID, token.UserID, pq.Array(token.Roles), pq.Array(token.Brands), token.TokenHash, token.FamilyID, token.ExpiresAt, token.CreatedAt)
+	r.queries.Observe("refresh_tokens.create", start, err)
+	return err
+}
+
+func (r *pgRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	token := &domain.RefreshToken{}
+	var revokedAt sql.NullTime
+	var replacedBy sql.NullString
+	query := `SELECT id, user_id, roles, brands, token_hash, family_id, expires_at, created_at, revoked_at, replaced_by FROM refresh_tokens WHERE token_hash = $1`
+	start := time.Now()
+	row := database.Executor(ctx, r.db).QueryRowContext(ctx, query, tokenHash)
+	err := row.Scan(&token.ID, &token.UserID, pq.Array(&token.Roles), pq.Array(&token.Brands), &token.TokenHash, &token.FamilyID, &token.ExpiresAt, &token.CreatedAt, &revokedAt, &replacedBy)
+	r.queries.Observe("refresh_tokens.get_by_hash", start, err)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	if revokedAt.Valid {
+		token.RevokedAt = &revokedAt.Time
+	}
+	token.ReplacedBy = replacedBy.String
+	return token, nil
+}
+
+func (r *pgRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, replacement *domain.RefreshToken) (bool, error) {
+	start := time.Now()
+	rotated, err := r.rotateRefreshToken(ctx, oldID, replacement)
+	r.queries.Observe("refresh_tokens.rotate", start, err)
+	return rotated, err
+}
+
+// rotateRefreshToken revokes and inserts in one transaction, or in a savepoint of the caller's.
+func (r *pgRefreshTokenRepository) rotateRefreshToken(ctx context.Context, oldID string, replacement *domain.RefreshToken) (bool, error) {
+	rotated := false
+	err := r.tx.WithinTx(ctx, func(ctx context.Context) error {
+		tx := database.Executor(ctx, r.db)
+		result, err := tx.ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $2, replaced_by = $3 WHERE id = $1 AND revoked_at IS NULL`, oldID, time.Now(), replacement.ID)
+		if err != nil {
+			return err
+		}
+		rowsAffected, err := result.RowsAffected()
+		if err != nil {
+			return err
+		}
+		if rowsAffected == 0 {
+			return nil
+		}
+
+		query := `INSERT INTO refresh_tokens (id, user_id, roles, brands, token_hash, family_id, expires_at, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+		if _, err := tx.ExecContext(ctx, query, replacement.ID, replacement.UserID, pq.Array(replacement.Roles), pq.Array(replacement.Brands), replacement.TokenHash, replacement.FamilyID, replacement.ExpiresAt, replacement.CreatedAt); err != nil {
+			return err
+		}
+		rotated = true
+		return nil
+	})
+	return rotated, err
+}
+
+func (r *pgRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $2 WHERE id = $1 AND revoked_at IS NULL`, id, time.Now())
+	r.queries.Observe("refresh_tokens.revoke", start, err)
+	return err
+}
+
+func (r *pgRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $2 WHERE family_id = $1 AND revoked_at IS NULL`, familyID, time.Now())
+	r.queries.Observe("refresh_tokens.revoke_family", start, err)
+	return err
+}
+
+func (r *pgRefreshTokenRepository) RevokeRefreshTokensForUser(ctx context.Context, userID string) error {
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, `UPDATE refresh_tokens SET revoked_at = $2 WHERE user_id = $1 AND revoked_at IS NULL`, userID, time.Now())
+	r.queries.Observe("refresh_tokens.revoke_for_user", start, err)
+	return err
+}
+
+// CREATE TABLE refresh_tokens (
+//     id VARCHAR(36) PRIMARY KEY,
+//     user_id VARCHAR(255) NOT NULL, -- Local user ID or the subject from an external IdP
+//     roles TEXT[] NOT NULL DEFAULT '{}',
+//     brands TEXT[] NOT NULL DEFAULT '{}',
+//     token_hash VARCHAR(64) UNIQUE NOT NULL,
+//     family_id VARCHAR(36) NOT NULL,
+//     expires_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
+//     revoked_at TIMESTAMP WITH TIME ZONE,
+//     replaced_by VARCHAR(36)
+// );
@@ -0,0 +1,108 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunAPIKeyRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.APIKeyRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunAPIKeyRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.APIKeyRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndList", func(t *testing.T) {
+		repo := newRepo(t)
+		key := newAPIKey("key-1", "user-1", 0)
+		require.NoError(t, repo.CreateAPIKey(ctx, key))
+
+		keys, err := repo.ListAPIKeysByOwner(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		found := keys[0]
+		assert.Equal(t, key.ID, found.ID)
+		assert.Equal(t, key.OwnerID, found.OwnerID)
+		assert.Equal(t, key.Name, found.Name)
+		assert.Equal(t, key.Prefix, found.Prefix)
+		assert.Empty(t, found.KeyHash, "listed keys must not carry their hash")
+		assert.Equal(t, key.Scopes, found.Scopes)
+		assert.Equal(t, key.Brands, found.Brands)
+		assert.True(t, key.ExpiresAt.Equal(found.ExpiresAt))
+		assert.True(t, key.CreatedAt.Equal(found.CreatedAt))
+		assert.Nil(t, found.RevokedAt)
+	})
+
+	t.Run("CreateDuplicateHash", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-1", "user-1", 0)))
+		duplicate := newAPIKey("key-2", "user-1", 0)
+		duplicate.KeyHash = "hash-key-1"
+		assert.Error(t, repo.CreateAPIKey(ctx, duplicate))
+	})
+
+	t.Run("ListNewestFirst", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-1", "user-1", 0)))
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-3", "user-1", 2*time.Hour)))
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-2", "user-1", time.Hour)))
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-4", "user-2", 3*time.Hour)))
+
+		keys, err := repo.ListAPIKeysByOwner(ctx, "user-1")
+		require.NoError(t, err)
+		ids := []string{}
+		for _, key := range keys {
+			ids = append(ids, key.ID)
+		}
+		assert.Equal(t, []string{"key-3", "key-2", "key-1"}, ids)
+	})
+
+	t.Run("ListEmpty", func(t *testing.T) {
+		repo := newRepo(t)
+		keys, err := repo.ListAPIKeysByOwner(ctx, "user-1")
+		require.NoError(t, err)
+		assert.NotNil(t, keys, "no keys is an empty list, so it encodes as []")
+		assert.Empty(t, keys)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateAPIKey(ctx, newAPIKey("key-1", "user-1", 0)))
+
+		revoked, err := repo.RevokeAPIKey(ctx, "key-1", "user-2")
+		require.NoError(t, err)
+		assert.False(t, revoked, "only the owner may revoke a key")
+		revoked, err = repo.RevokeAPIKey(ctx, "key-1", "user-1")
+		require.NoError(t, err)
+		assert.True(t, revoked)
+		revoked, err = repo.RevokeAPIKey(ctx, "key-1", "user-1")
+		require.NoError(t, err)
+		assert.False(t, revoked, "a revoked key is no longer active")
+		revoked, err = repo.RevokeAPIKey(ctx, "missing", "user-1")
+		require.NoError(t, err)
+		assert.False(t, revoked)
+
+		keys, err := repo.ListAPIKeysByOwner(ctx, "user-1")
+		require.NoError(t, err)
+		require.Len(t, keys, 1)
+		assert.NotNil(t, keys[0].RevokedAt)
+	})
+}
+
+func newAPIKey(id, ownerID string, createdAfter time.Duration) *domain.APIKey {
+	return &domain.APIKey{
+		ID:        id,
+		OwnerID:   ownerID,
+		Name:      "Nightly export",
+		Prefix:    "omni_" + id,
+		KeyHash:   "hash-" + id,
+		Scopes:    []string{"sellers:read", "products:read"},
+		Brands:    []string{"BRAND_A"},
+		ExpiresAt: fixedTime.Add(90 * 24 * time.Hour),
+		CreatedAt: fixedTime.Add(createdAfter),
+	}
+}
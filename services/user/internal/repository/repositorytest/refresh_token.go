@@ -0,0 +1,139 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunRefreshTokenRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.RefreshTokenRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunRefreshTokenRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.RefreshTokenRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGetByHash", func(t *testing.T) {
+		repo := newRepo(t)
+		token := newRefreshToken("rt-1", "user-1", "family-1")
+		require.NoError(t, repo.CreateRefreshToken(ctx, token))
+
+		found, err := repo.GetRefreshTokenByHash(ctx, token.TokenHash)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, token.ID, found.ID)
+		assert.Equal(t, token.UserID, found.UserID)
+		assert.Equal(t, token.Roles, found.Roles)
+		assert.Equal(t, token.Brands, found.Brands)
+		assert.Equal(t, token.FamilyID, found.FamilyID)
+		assert.True(t, token.ExpiresAt.Equal(found.ExpiresAt))
+		assert.True(t, token.CreatedAt.Equal(found.CreatedAt))
+		assert.Nil(t, found.RevokedAt)
+		assert.Empty(t, found.ReplacedBy)
+	})
+
+	t.Run("CreateDuplicateHash", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-1", "user-1", "family-1")))
+		duplicate := newRefreshToken("rt-2", "user-1", "family-1")
+		duplicate.TokenHash = "hash-rt-1"
+		assert.Error(t, repo.CreateRefreshToken(ctx, duplicate))
+	})
+
+	t.Run("GetByHashNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		found, err := repo.GetRefreshTokenByHash(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("RotateOnce", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-1", "user-1", "family-1")))
+
+		rotated, err := repo.RotateRefreshToken(ctx, "rt-1", newRefreshToken("rt-2", "user-1", "family-1"))
+		require.NoError(t, err)
+		assert.True(t, rotated)
+		rotated, err = repo.RotateRefreshToken(ctx, "rt-1", newRefreshToken("rt-3", "user-1", "family-1"))
+		require.NoError(t, err)
+		assert.False(t, rotated, "a rotated token must not rotate again")
+
+		old, err := repo.GetRefreshTokenByHash(ctx, "hash-rt-1")
+		require.NoError(t, err)
+		require.NotNil(t, old.RevokedAt)
+		assert.Equal(t, "rt-2", old.ReplacedBy)
+		replacement, err := repo.GetRefreshTokenByHash(ctx, "hash-rt-2")
+		require.NoError(t, err)
+		require.NotNil(t, replacement)
+		assert.Nil(t, replacement.RevokedAt)
+		lost, err := repo.GetRefreshTokenByHash(ctx, "hash-rt-3")
+		require.NoError(t, err)
+		assert.Nil(t, lost, "the replacement of a failed rotation must not be stored")
+	})
+
+	t.Run("RotateNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		rotated, err := repo.RotateRefreshToken(ctx, "missing", newRefreshToken("rt-2", "user-1", "family-1"))
+		require.NoError(t, err)
+		assert.False(t, rotated)
+	})
+
+	t.Run("Revoke", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-1", "user-1", "family-1")))
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-2", "user-1", "family-1")))
+		require.NoError(t, repo.RevokeRefreshToken(ctx, "rt-1"))
+		require.NoError(t, repo.RevokeRefreshToken(ctx, "missing"))
+
+		assertRevoked(t, repo, "hash-rt-1", true)
+		assertRevoked(t, repo, "hash-rt-2", false)
+	})
+
+	t.Run("RevokeFamily", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-1", "user-1", "family-1")))
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-2", "user-1", "family-1")))
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-3", "user-1", "family-2")))
+		require.NoError(t, repo.RevokeRefreshTokenFamily(ctx, "family-1"))
+
+		assertRevoked(t, repo, "hash-rt-1", true)
+		assertRevoked(t, repo, "hash-rt-2", true)
+		assertRevoked(t, repo, "hash-rt-3", false)
+	})
+
+	t.Run("RevokeForUser", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-1", "user-1", "family-1")))
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-2", "user-1", "family-2")))
+		require.NoError(t, repo.CreateRefreshToken(ctx, newRefreshToken("rt-3", "user-2", "family-3")))
+		require.NoError(t, repo.RevokeRefreshTokensForUser(ctx, "user-1"))
+
+		assertRevoked(t, repo, "hash-rt-1", true)
+		assertRevoked(t, repo, "hash-rt-2", true)
+		assertRevoked(t, repo, "hash-rt-3", false)
+	})
+}
+
+func newRefreshToken(id, userID, familyID string) *domain.RefreshToken {
+	return &domain.RefreshToken{
+		ID:        id,
+		UserID:    userID,
+		Roles:     []string{"user"},
+		Brands:    []string{"BRAND_A"},
+		TokenHash: "hash-" + id,
+		FamilyID:  familyID,
+		ExpiresAt: fixedTime.Add(24 * time.Hour),
+		CreatedAt: fixedTime,
+	}
+}
+
+func assertRevoked(t *testing.T, repo repository.RefreshTokenRepository, tokenHash string, revoked bool) {
+	t.Helper()
+	token, err := repo.GetRefreshTokenByHash(context.Background(), tokenHash)
+	require.NoError(t, err)
+	require.NotNil(t, token)
+	assert.Equal(t, revoked, token.RevokedAt != nil, "token %s revoked", tokenHash)
+}
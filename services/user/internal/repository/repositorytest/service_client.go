@@ -0,0 +1,61 @@
+package repositorytest
+
+import (
+	"context"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// RunServiceClientRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.ServiceClientRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunServiceClientRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.ServiceClientRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		client := newServiceClient("bff-web")
+		require.NoError(t, repo.CreateServiceClient(ctx, client))
+
+		found, err := repo.GetServiceClient(ctx, client.ClientID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, client.ClientID, found.ClientID)
+		assert.Equal(t, client.Name, found.Name)
+		assert.Equal(t, client.SecretHash, found.SecretHash)
+		assert.Equal(t, client.AllowedScopes, found.AllowedScopes)
+		assert.Equal(t, client.AllowedAudiences, found.AllowedAudiences)
+		assert.Equal(t, client.AllowedBrands, found.AllowedBrands)
+		assert.Equal(t, client.Disabled, found.Disabled)
+		assert.True(t, client.CreatedAt.Equal(found.CreatedAt))
+	})
+
+	t.Run("CreateDuplicateID", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateServiceClient(ctx, newServiceClient("bff-web")))
+		assert.Error(t, repo.CreateServiceClient(ctx, newServiceClient("bff-web")))
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		found, err := repo.GetServiceClient(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
+func newServiceClient(clientID string) *domain.ServiceClient {
+	return &domain.ServiceClient{
+		ClientID:         clientID,
+		Name:             "Web BFF",
+		SecretHash:       "secret-hash",
+		AllowedScopes:    []string{"sellers:read", "sellers:write"},
+		AllowedAudiences: []string{"seller-service"},
+		AllowedBrands:    []string{"*"},
+		Disabled:         true,
+		CreatedAt:        fixedTime,
+	}
+}
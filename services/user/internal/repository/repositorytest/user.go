@@ -0,0 +1,103 @@
+// Package repositorytest holds conformance suites for the user service's repository interfaces.
+// Every implementation runs them, so the in-memory repositories cannot drift from the Postgres ones.
+package repositorytest
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fixedTime is the base time of fixtures; Postgres keeps microseconds.
+var fixedTime = time.Date(2024, 5, 1, 9, 30, 0, 123456000, time.UTC)
+
+// RunUserRepositoryTests checks that the repositories returned by newRepo keep the
+// repository.UserRepository contract. newRepo is called once per subtest and must return an empty repository.
+func RunUserRepositoryTests(t *testing.T, newRepo func(t *testing.T) repository.UserRepository) {
+	ctx := context.Background()
+
+	t.Run("CreateAndGet", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("user-1", "ada@example.com")
+		require.NoError(t, repo.CreateUser(ctx, user))
+
+		found, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, user.ID, found.ID)
+		assert.Equal(t, user.Name, found.Name)
+		assert.Equal(t, user.Email, found.Email)
+		assert.True(t, user.CreatedAt.Equal(found.CreatedAt))
+		assert.True(t, user.UpdatedAt.Equal(found.UpdatedAt))
+		assert.Empty(t, found.Roles, "roles are not stored with the user")
+		assert.Equal(t, user.Brands, found.Brands)
+	})
+
+	t.Run("CreateWithoutBrands", func(t *testing.T) {
+		repo := newRepo(t)
+		user := newUser("user-1", "ada@example.com")
+		user.Brands = nil
+		require.NoError(t, repo.CreateUser(ctx, user))
+
+		found, err := repo.GetUserByID(ctx, user.ID)
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Empty(t, found.Brands)
+	})
+
+	t.Run("GetByEmail", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateUser(ctx, newUser("user-1", "ada@example.com")))
+		require.NoError(t, repo.CreateUser(ctx, newUser("user-2", "grace@example.com")))
+
+		found, err := repo.GetUserByEmail(ctx, "grace@example.com")
+		require.NoError(t, err)
+		require.NotNil(t, found)
+		assert.Equal(t, "user-2", found.ID)
+		assert.Equal(t, []string{"BRAND_A"}, found.Brands)
+
+		found, err = repo.GetUserByEmail(ctx, "missing@example.com")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+
+	t.Run("CreateDuplicateID", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateUser(ctx, newUser("user-1", "ada@example.com")))
+		assert.Error(t, repo.CreateUser(ctx, newUser("user-1", "grace@example.com")))
+	})
+
+	t.Run("CreateDuplicateEmail", func(t *testing.T) {
+		repo := newRepo(t)
+		require.NoError(t, repo.CreateUser(ctx, newUser("user-1", "ada@example.com")))
+		assert.Error(t, repo.CreateUser(ctx, newUser("user-2", "ada@example.com")))
+
+		found, err := repo.GetUserByID(ctx, "user-2")
+		require.NoError(t, err)
+		assert.Nil(t, found, "a rejected user must not be stored")
+	})
+
+	t.Run("GetNotFound", func(t *testing.T) {
+		repo := newRepo(t)
+		found, err := repo.GetUserByID(ctx, "missing")
+		assert.NoError(t, err)
+		assert.Nil(t, found)
+	})
+}
+
+func newUser(id, email string) *domain.User {
+	return &domain.User{
+		ID:        id,
+		Name:      "Ada Lovelace",
+		Email:     email,
+		CreatedAt: fixedTime,
+		UpdatedAt: fixedTime,
+		Roles:     []string{"admin"},
+		Brands:    []string{"BRAND_A"},
+	}
+}
@@ -0,0 +1,47 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+type memoryServiceClientRepository struct {
+	mu      sync.RWMutex
+	clients map[string]*domain.ServiceClient
+}
+
+// NewMemoryServiceClientRepository creates an in-memory service client repository for local development and tests.
+func NewMemoryServiceClientRepository() ServiceClientRepository {
+	return &memoryServiceClientRepository{clients: map[string]*domain.ServiceClient{}}
+}
+
+func (r *memoryServiceClientRepository) CreateServiceClient(ctx context.Context, client *domain.ServiceClient) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.clients[client.ClientID]; exists {
+		return fmt.Errorf("duplicate service client ID %s", client.ClientID)
+	}
+	r.clients[client.ClientID] = copyServiceClient(client)
+	return nil
+}
+
+func (r *memoryServiceClientRepository) GetServiceClient(ctx context.Context, clientID string) (*domain.ServiceClient, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	client, ok := r.clients[clientID]
+	if !ok {
+		return nil, nil
+	}
+	return copyServiceClient(client), nil
+}
+
+func copyServiceClient(client *domain.ServiceClient) *domain.ServiceClient {
+	copied := *client
+	copied.AllowedScopes = append([]string{}, client.AllowedScopes...)
+	copied.AllowedAudiences = append([]string{}, client.AllowedAudiences...)
+	copied.AllowedBrands = append([]string{}, client.AllowedBrands...)
+	return &copied
+}
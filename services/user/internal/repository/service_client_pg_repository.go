@@ -0,0 +1,64 @@
+package repository
+
+import (
+	"context"
+	"database/sql"
+	"time"
+
+	"github.com/lib/pq"
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+// ServiceClientRepository defines the interface for service client (client-credentials) storage.
+// repositorytest.RunServiceClientRepositoryTests checks that an implementation keeps this contract.
+type ServiceClientRepository interface {
+	CreateServiceClient(ctx context.Context, client *domain.ServiceClient) error
+	// GetServiceClient returns nil and no error if the client does not exist.
+	GetServiceClient(ctx context.Context, clientID string) (*domain.ServiceClient, error)
+}
+
+type pgServiceClientRepository struct {
+	db      *sql.DB
+	queries *database.QueryMetrics
+}
+
+// NewPGServiceClientRepository creates a new PostgreSQL service client repository. queries may be nil.
+func NewPGServiceClientRepository(db *sql.DB, queries *database.QueryMetrics) ServiceClientRepository {
+	return &pgServiceClientRepository{db: db, queries: queries}
+}
+
+func (r *pgServiceClientRepository) CreateServiceClient(ctx context.Context, client *domain.ServiceClient) error {
+	query := `INSERT INTO service_clients (client_id, name, secret_hash, allowed_scopes, allowed_audiences, allowed_brands, disabled, created_at) VALUES ($1, $2, $3, $4, $5, $6, $7, $8)`
+	start := time.Now()
+	_, err := database.Executor(ctx, r.db).ExecContext(ctx, query, client.ClientID, client.Name, client.SecretHash, pq.Array(client.AllowedScopes), pq.Array(client.AllowedAudiences), pq.Array(client.AllowedBrands), client.Disabled, client.CreatedAt)
+	r.queries.Observe("service_clients.create", start, err)
+	return err
+}
+
+func (r *pgServiceClientRepository) GetServiceClient(ctx context.Context, clientID string) (*domain.ServiceClient, error) {
+	client := &domain.ServiceClient{}
+	query := `SELECT client_id, name, secret_hash, allowed_scopes, allowed_audiences, allowed_brands, disabled, created_at FROM service_clients WHERE client_id = $1`
+	start := time.Now()
+	row := database.Executor(ctx, r.db).QueryRowContext(ctx, query, clientID)
+	err := row.Scan(&client.ClientID, &client.Name, &client.SecretHash, pq.Array(&client.AllowedScopes), pq.Array(&client.AllowedAudiences), pq.Array(&client.AllowedBrands), &client.Disabled, &client.CreatedAt)
+	r.queries.Observe("service_clients.get", start, err)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+	return client, nil
+}
+
+// CREATE TABLE service_clients (
+//     client_id VARCHAR(64) PRIMARY KEY,
+//     name VARCHAR(255) NOT NULL,
+//     secret_hash VARCHAR(64) NOT NULL,
+//     allowed_scopes TEXT[] NOT NULL DEFAULT '{}',
+//     allowed_audiences TEXT[] NOT NULL DEFAULT '{}',
+//     allowed_brands TEXT[] NOT NULL DEFAULT '{}',
+//     disabled BOOLEAN NOT NULL DEFAULT FALSE,
+//     created_at TIMESTAMP WITH TIME ZONE NOT NULL
+// );
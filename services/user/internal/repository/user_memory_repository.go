@@ -0,0 +1,61 @@
+package repository
+
+import (
+	"context"
+	"fmt"
+	"sync"
+
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+type memoryUserRepository struct {
+	mu     sync.RWMutex
+	users  map[string]*domain.User
+	emails map[string]string // Email to user ID, enforcing the unique email column
+}
+
+// NewMemoryUserRepository creates an in-memory user repository for local development and tests.
+// Like the users table it keeps only ID, name, email, brands and timestamps; data is lost on restart.
+func NewMemoryUserRepository() UserRepository {
+	return &memoryUserRepository{users: map[string]*domain.User{}, emails: map[string]string{}}
+}
+
+func (r *memoryUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if _, exists := r.users[user.ID]; exists {
+		return fmt.Errorf("duplicate user ID %s", user.ID)
+	}
+	if _, exists := r.emails[user.Email]; exists {
+		return fmt.Errorf("duplicate user email %s", user.Email)
+	}
+	r.users[user.ID] = storedUser(user)
+	r.emails[user.Email] = user.ID
+	return nil
+}
+
+func (r *memoryUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	user, ok := r.users[id]
+	if !ok {
+		return nil, nil
+	}
+	return storedUser(user), nil
+}
+
+func (r *memoryUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	id, ok := r.emails[email]
+	if !ok {
+		return nil, nil
+	}
+	return storedUser(r.users[id]), nil
+}
+
+// storedUser copies the columns of the users table.
+func storedUser(user *domain.User) *domain.User {
+	return &domain.User{ID: user.ID, Name: user.Name, Email: user.Email, Brands: append([]string{}, user.Brands...),
+		CreatedAt: user.CreatedAt, UpdatedAt: user.UpdatedAt}
+}
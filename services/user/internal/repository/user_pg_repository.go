@@ -3,38 +3,70 @@ package repository
 import (
 	"context"
 	"database/sql"
+	"errors"
+	"time"
 
+	"github.com/jackc/pgx/v5"
+	"github.com/omni-compos/digital-mono/libs/database"
 	"github.com/omni-compos/digital-mono/services/user/internal/domain"
 )
 
 // UserRepository defines the interface for user data operations.
+// repositorytest.RunUserRepositoryTests checks that an implementation keeps this contract.
 type UserRepository interface {
+	// CreateUser fails if the ID or email is taken. Brands are stored; roles are not.
 	CreateUser(ctx context.Context, user *domain.User) error
+	// GetUserByID returns nil and no error if the user does not exist.
 	GetUserByID(ctx context.Context, id string) (*domain.User, error)
+	// GetUserByEmail returns nil and no error if no user has the email.
+	GetUserByEmail(ctx context.Context, email string) (*domain.User, error)
 }
 
 type pgUserRepository struct {
-	db *sql.DB
+	db      *sql.DB
+	queries *database.QueryMetrics
 }
 
-// NewPGUserRepository creates a new PostgreSQL user repository.
-func NewPGUserRepository(db *sql.DB) UserRepository {
-	return &pgUserRepository{db: db}
+// NewPGUserRepository creates a new PostgreSQL user repository on pgx; db must come from
+// database.NewPgxDB. queries may be nil.
+func NewPGUserRepository(db *sql.DB, queries *database.QueryMetrics) UserRepository {
+	return &pgUserRepository{db: db, queries: queries}
 }
 
 func (r *pgUserRepository) CreateUser(ctx context.Context, user *domain.User) error {
-	query := `INSERT INTO users (id, name, email, created_at, updated_at) VALUES ($1, $2, $3, $4, $5)`
-	_, err := r.db.ExecContext(ctx, query, user.ID, user.Name, user.Email, user.CreatedAt, user.UpdatedAt)
+	query := `INSERT INTO users (id, name, email, brands, created_at, updated_at) VALUES ($1, $2, $3, $4, $5, $6)`
+	brands := user.Brands
+	if brands == nil {
+		brands = []string{} // The column is NOT NULL
+	}
+	start := time.Now()
+	err := database.WithPgx(ctx, r.db, func(conn *pgx.Conn) error {
+		_, err := conn.Exec(ctx, query, user.ID, user.Name, user.Email, brands, user.CreatedAt, user.UpdatedAt)
+		return err
+	})
+	r.queries.Observe("users.create", start, err)
 	return err
 }
 
 func (r *pgUserRepository) GetUserByID(ctx context.Context, id string) (*domain.User, error) {
+	return r.getUser(ctx, "users.get_by_id", `WHERE id = $1`, id)
+}
+
+func (r *pgUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	return r.getUser(ctx, "users.get_by_email", `WHERE email = $1`, email)
+}
+
+// getUser reads the single user matching where, recording the query as operation.
+func (r *pgUserRepository) getUser(ctx context.Context, operation, where string, arg string) (*domain.User, error) {
 	user := &domain.User{}
-	query := `SELECT id, name, email, created_at, updated_at FROM users WHERE id = $1`
-	row := r.db.QueryRowContext(ctx, query, id)
-	err := row.Scan(&user.ID, &user.Name, &user.Email, &user.CreatedAt, &user.UpdatedAt)
+	query := `SELECT id, name, email, brands, created_at, updated_at FROM users ` + where
+	start := time.Now()
+	err := database.WithPgx(ctx, r.db, func(conn *pgx.Conn) error {
+		return conn.QueryRow(ctx, query, arg).Scan(&user.ID, &user.Name, &user.Email, &user.Brands, &user.CreatedAt, &user.UpdatedAt)
+	})
+	r.queries.Observe(operation, start, err)
 	if err != nil {
-		if err == sql.ErrNoRows {
+		if errors.Is(err, pgx.ErrNoRows) {
 			return nil, nil // Or a custom domain.ErrNotFound
 		}
 		return nil, err
@@ -48,6 +80,7 @@ func (r *pgUserRepository) GetUserByID(ctx context.Context, id string) (*domain.
 //     id VARCHAR(36) PRIMARY KEY,
 //     name VARCHAR(255) NOT NULL,
 //     email VARCHAR(255) UNIQUE NOT NULL,
+//     brands TEXT[] NOT NULL DEFAULT '{}',
 //     created_at TIMESTAMP WITH TIME ZONE NOT NULL,
 //     updated_at TIMESTAMP WITH TIME ZONE NOT NULL
 // );
\ No newline at end of file
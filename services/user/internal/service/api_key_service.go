@@ -0,0 +1,133 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/tracing"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+)
+
+const (
+	// DefaultAPIKeyTTL is used when a key is requested without an expiry.
+	DefaultAPIKeyTTL = 90 * 24 * time.Hour
+	// MaxAPIKeyTTL bounds the lifetime of API keys; every key expires.
+	MaxAPIKeyTTL = 365 * 24 * time.Hour
+)
+
+var (
+	// ErrAPIKeyNotFound is returned when revoking a key the caller does not own or that is already revoked.
+	ErrAPIKeyNotFound = errors.New("API key not found")
+	// ErrInvalidAPIKeyRequest is returned for a missing name, no scopes or an out of range expiry.
+	ErrInvalidAPIKeyRequest = errors.New("invalid API key request")
+	// ErrAPIKeyNotAllowed is returned when the caller may not issue keys or asks for scopes beyond their own permissions.
+	ErrAPIKeyNotAllowed = errors.New("API key scope not allowed")
+)
+
+// APIKeyService issues, lists and revokes API keys for the calling user.
+type APIKeyService interface {
+	CreateAPIKey(ctx context.Context, claims *commonAuth.Claims, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error)
+	ListAPIKeys(ctx context.Context, ownerID string) ([]*domain.APIKey, error)
+	RevokeAPIKey(ctx context.Context, ownerID, id string) error
+}
+
+type apiKeyService struct {
+	repo   repository.APIKeyRepository
+	policy *commonAuth.Policy
+	logger logger.Logger
+	tx     database.Transactor
+	outbox *database.Outbox
+}
+
+// NewAPIKeyService creates a new API key service. Requested scopes are checked against the
+// caller's roles in policy, so a key can never do more than the user who issued it.
+// Keys and their domain events in outbox are written in one transaction of tx; nil tx and outbox are allowed.
+func NewAPIKeyService(repo repository.APIKeyRepository, policy *commonAuth.Policy, log logger.Logger, tx database.Transactor, outbox *database.Outbox) APIKeyService {
+	if tx == nil {
+		tx = database.NopTransactor{}
+	}
+	return &apiKeyService{repo: repo, policy: policy, logger: log, tx: tx, outbox: outbox}
+}
+
+func (s *apiKeyService) CreateAPIKey(ctx context.Context, claims *commonAuth.Claims, req *domain.CreateAPIKeyRequest) (*domain.CreateAPIKeyResponse, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.CreateAPIKey")
+	defer span.End()
+	if claims.IsScopeLimited() || claims.UserID == "" {
+		return nil, fmt.Errorf("%w: only users can issue API keys", ErrAPIKeyNotAllowed)
+	}
+	ttl := time.Duration(req.ExpiresInDays) * 24 * time.Hour
+	if req.ExpiresInDays == 0 {
+		ttl = DefaultAPIKeyTTL
+	}
+	if req.Name == "" || len(req.Scopes) == 0 || ttl <= 0 || ttl > MaxAPIKeyTTL {
+		return nil, ErrInvalidAPIKeyRequest
+	}
+	for _, scope := range req.Scopes {
+		if !s.policy.HasPermission(claims.Roles, scope) {
+			return nil, fmt.Errorf("%w: %s", ErrAPIKeyNotAllowed, scope)
+		}
+	}
+
+	rawKey, prefix, keyHash := commonAuth.GenerateAPIKey()
+	now := time.Now()
+	key := &domain.APIKey{
+		ID:        uuid.NewString(),
+		OwnerID:   claims.UserID,
+		Name:      req.Name,
+		Prefix:    prefix,
+		KeyHash:   keyHash,
+		Scopes:    req.Scopes,
+		Brands:    claims.Brands,
+		ExpiresAt: now.Add(ttl),
+		CreatedAt: now,
+	}
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateAPIKey(ctx, key); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, apiKeyAggregate, key.ID, EventAPIKeyCreated, key)
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to store API key: %w", err)
+	}
+	logger.FromContext(ctx, s.logger).Info("Issued API key", "user_id", key.OwnerID, "key_id", key.ID, "scope", key.Scopes)
+	return &domain.CreateAPIKeyResponse{Key: rawKey, APIKey: key}, nil
+}
+
+func (s *apiKeyService) ListAPIKeys(ctx context.Context, ownerID string) ([]*domain.APIKey, error) {
+	ctx, span := tracing.Start(ctx, "APIKeyService.ListAPIKeys")
+	defer span.End()
+	keys, err := s.repo.ListAPIKeysByOwner(ctx, ownerID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to list API keys for user %s: %w", ownerID, err)
+	}
+	return keys, nil
+}
+
+func (s *apiKeyService) RevokeAPIKey(ctx context.Context, ownerID, id string) error {
+	ctx, span := tracing.Start(ctx, "APIKeyService.RevokeAPIKey")
+	defer span.End()
+	revoked := false
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		var err error
+		if revoked, err = s.repo.RevokeAPIKey(ctx, id, ownerID); err != nil || !revoked {
+			return err
+		}
+		return s.outbox.Add(ctx, apiKeyAggregate, id, EventAPIKeyRevoked, apiKeyRevoked{ID: id, OwnerID: ownerID})
+	})
+	if err != nil {
+		return fmt.Errorf("failed to revoke API key %s: %w", id, err)
+	}
+	if !revoked {
+		return ErrAPIKeyNotFound
+	}
+	logger.FromContext(ctx, s.logger).Info("Revoked API key", "user_id", ownerID, "key_id", id)
+	return nil
+}
@@ -0,0 +1,17 @@
+package service
+
+// Domain events the user service records in its outbox.
+const (
+	userAggregate   = "user"
+	apiKeyAggregate = "api_key"
+
+	EventUserCreated   = "user.created"    // Payload: the user
+	EventAPIKeyCreated = "api_key.created" // Payload: the API key; its hash is never serialized
+	EventAPIKeyRevoked = "api_key.revoked" // Payload: apiKeyRevoked
+)
+
+// apiKeyRevoked is the payload of EventAPIKeyRevoked.
+type apiKeyRevoked struct {
+	ID      string `json:"id"`
+	OwnerID string `json:"ownerId"`
+}
@@ -0,0 +1,56 @@
+package service
+
+import "github.com/omni-compos/digital-mono/libs/metrics"
+
+// Login methods and outcomes counted by KPIs.
+const (
+	LoginMethodPassword = "password"
+	LoginMethodOIDC     = "oidc"
+
+	LoginSuccess            = "success"
+	LoginInvalidCredentials = "invalid_credentials"
+	LoginError              = "error"
+	LoginDisabled           = "disabled" // The login method is not available
+)
+
+type loginLabels struct {
+	Method  string `label:"method"`
+	Outcome string `label:"outcome"`
+}
+
+type noLabels struct{}
+
+// KPIs are the business metrics of the user service. They are shared by the services that log users
+// in, since a counter can only be declared once; create them with NewKPIs. A nil *KPIs records nothing.
+type KPIs struct {
+	usersCreated *metrics.Counter[noLabels]
+	logins       *metrics.Counter[loginLabels]
+}
+
+// NewKPIs declares the user service's business counters on b.
+func NewKPIs(b *metrics.BusinessMetrics) *KPIs {
+	return &KPIs{
+		usersCreated: metrics.NewCounter[noLabels](b, "users_created_total", "Users created."),
+		logins:       metrics.NewCounter[loginLabels](b, "logins_total", "Login attempts by method and outcome."),
+	}
+}
+
+func (k *KPIs) userCreated() {
+	if k != nil {
+		k.usersCreated.Inc(noLabels{})
+	}
+}
+
+func (k *KPIs) login(method, outcome string) {
+	if k != nil {
+		k.logins.Inc(loginLabels{Method: method, Outcome: outcome})
+	}
+}
+
+// Logins returns the number of logins counted for method and outcome, for tests.
+func (k *KPIs) Logins(method, outcome string) float64 {
+	if k == nil {
+		return 0
+	}
+	return k.logins.Value(loginLabels{Method: method, Outcome: outcome})
+}
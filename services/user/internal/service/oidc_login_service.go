@@ -0,0 +1,64 @@
+package service
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/tracing"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+)
+
+// ErrOIDCLoginFailed is returned when the IdP rejects the code or its ID token does not verify.
+var ErrOIDCLoginFailed = errors.New("OIDC login failed")
+
+// OIDCLoginService logs users in through an external OpenID Connect provider
+// (authorization-code flow) and issues the service's own session tokens for them.
+type OIDCLoginService interface {
+	LoginURL(state, nonce string) string
+	CompleteLogin(ctx context.Context, code, nonce string) (*domain.LoginResponse, error)
+}
+
+type oidcLoginService struct {
+	provider *commonAuth.OIDCProvider
+	tokens   TokenService
+	logger   logger.Logger
+	kpis     *KPIs
+}
+
+// NewOIDCLoginService creates a new OIDC login service. kpis may be nil.
+func NewOIDCLoginService(provider *commonAuth.OIDCProvider, tokenService TokenService, log logger.Logger, kpis *KPIs) OIDCLoginService {
+	return &oidcLoginService{provider: provider, tokens: tokenService, logger: log, kpis: kpis}
+}
+
+func (s *oidcLoginService) LoginURL(state, nonce string) string {
+	return s.provider.AuthCodeURL(state, nonce)
+}
+
+func (s *oidcLoginService) CompleteLogin(ctx context.Context, code, nonce string) (*domain.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "OIDCLoginService.CompleteLogin")
+	defer span.End()
+	idpTokens, err := s.provider.Exchange(ctx, code)
+	if err != nil {
+		s.kpis.login(LoginMethodOIDC, LoginInvalidCredentials)
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+	claims, err := s.provider.VerifyIDToken(ctx, idpTokens.IDToken, nonce)
+	if err != nil {
+		s.kpis.login(LoginMethodOIDC, LoginInvalidCredentials)
+		return nil, fmt.Errorf("%w: %v", ErrOIDCLoginFailed, err)
+	}
+
+	// The IdP identity, roles and brands are carried into the service's own tokens
+	user := &domain.User{ID: claims.UserID, Roles: claims.Roles, Brands: claims.Brands}
+	tokens, err := s.tokens.IssueTokens(ctx, user)
+	if err != nil {
+		s.kpis.login(LoginMethodOIDC, LoginError)
+		return nil, err
+	}
+	s.kpis.login(LoginMethodOIDC, LoginSuccess)
+	logger.FromContext(ctx, s.logger).Info("User logged in via OIDC", "user_id", user.ID, "issuer", claims.Issuer)
+	return tokens, nil
+}
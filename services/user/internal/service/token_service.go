@@ -0,0 +1,186 @@
+package service
+
+import (
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/base64"
+	"encoding/hex"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/google/uuid"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/tracing"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+)
+
+const (
+	// DefaultAccessTokenTTL keeps access tokens short-lived; clients refresh them with a refresh token.
+	DefaultAccessTokenTTL = 15 * time.Minute
+	// DefaultRefreshTokenTTL bounds how long a session can be kept alive without logging in again.
+	DefaultRefreshTokenTTL = 30 * 24 * time.Hour
+)
+
+var (
+	// ErrInvalidRefreshToken is returned when a refresh token is unknown, expired or revoked.
+	ErrInvalidRefreshToken = errors.New("invalid refresh token")
+	// ErrNotASession is returned by Logout for credentials that are not part of a login session,
+	// such as API keys and service tokens, which carry no jti.
+	ErrNotASession = errors.New("credentials are not a session token")
+)
+
+// TokenService defines session token operations: issuing, refreshing and revoking tokens.
+type TokenService interface {
+	IssueTokens(ctx context.Context, user *domain.User) (*domain.LoginResponse, error)
+	RefreshTokens(ctx context.Context, refreshToken string) (*domain.LoginResponse, error)
+	// Logout revokes the presented access token and, if given, the refresh token of the session.
+	// Nothing is revoked if the refresh token is invalid (ErrInvalidRefreshToken) or the access
+	// token has no jti (ErrNotASession).
+	Logout(ctx context.Context, claims *commonAuth.Claims, refreshToken string) error
+	// RevokeUserSessions revokes every refresh token of a user and every access token issued to
+	// them so far, e.g. after an account compromise.
+	RevokeUserSessions(ctx context.Context, userID string) error
+}
+
+type tokenService struct {
+	authenticator   *commonAuth.JWTAuthenticator
+	repo            repository.RefreshTokenRepository
+	logger          logger.Logger
+	accessTokenTTL  time.Duration
+	refreshTokenTTL time.Duration
+}
+
+// NewTokenService creates a new token service.
+func NewTokenService(authenticator *commonAuth.JWTAuthenticator, repo repository.RefreshTokenRepository, log logger.Logger, accessTokenTTL, refreshTokenTTL time.Duration) TokenService {
+	return &tokenService{
+		authenticator:   authenticator,
+		repo:            repo,
+		logger:          log,
+		accessTokenTTL:  accessTokenTTL,
+		refreshTokenTTL: refreshTokenTTL,
+	}
+}
+
+func (s *tokenService) IssueTokens(ctx context.Context, user *domain.User) (*domain.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.IssueTokens")
+	defer span.End()
+	rawToken, refreshToken := s.newRefreshToken(user.ID, user.Roles, user.Brands, uuid.NewString())
+	if err := s.repo.CreateRefreshToken(ctx, refreshToken); err != nil {
+		return nil, fmt.Errorf("failed to store refresh token: %w", err)
+	}
+	return s.respond(user.ID, user.Roles, user.Brands, rawToken)
+}
+
+func (s *tokenService) RefreshTokens(ctx context.Context, refreshToken string) (*domain.LoginResponse, error) {
+	ctx, span := tracing.Start(ctx, "TokenService.RefreshTokens")
+	defer span.End()
+	existing, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+	if err != nil {
+		return nil, fmt.Errorf("failed to look up refresh token: %w", err)
+	}
+	if existing == nil || time.Now().After(existing.ExpiresAt) {
+		return nil, ErrInvalidRefreshToken
+	}
+	if existing.RevokedAt != nil {
+		// A rotated token was presented again: assume it was stolen and end the whole session.
+		logger.FromContext(ctx, s.logger).Warn(ErrInvalidRefreshToken, "Refresh token reuse detected, revoking session", "user_id", existing.UserID, "family_id", existing.FamilyID)
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, existing.FamilyID); err != nil {
+			logger.FromContext(ctx, s.logger).Error(err, "Failed to revoke refresh token family", "family_id", existing.FamilyID)
+		}
+		return nil, ErrInvalidRefreshToken
+	}
+
+	rawToken, replacement := s.newRefreshToken(existing.UserID, existing.Roles, existing.Brands, existing.FamilyID)
+	rotated, err := s.repo.RotateRefreshToken(ctx, existing.ID, replacement)
+	if err != nil {
+		return nil, fmt.Errorf("failed to rotate refresh token: %w", err)
+	}
+	if !rotated {
+		return nil, ErrInvalidRefreshToken // Lost a race with a concurrent refresh of the same token
+	}
+	return s.respond(existing.UserID, existing.Roles, existing.Brands, rawToken)
+}
+
+func (s *tokenService) Logout(ctx context.Context, claims *commonAuth.Claims, refreshToken string) error {
+	ctx, span := tracing.Start(ctx, "TokenService.Logout")
+	defer span.End()
+	if claims.ID == "" {
+		return ErrNotASession
+	}
+	var familyID string
+	if refreshToken != "" {
+		existing, err := s.repo.GetRefreshTokenByHash(ctx, hashToken(refreshToken))
+		if err != nil {
+			return fmt.Errorf("failed to look up refresh token: %w", err)
+		}
+		if existing == nil || existing.UserID != claims.UserID {
+			return ErrInvalidRefreshToken
+		}
+		familyID = existing.FamilyID
+	}
+
+	if err := s.authenticator.RevokeToken(ctx, claims); err != nil {
+		return fmt.Errorf("failed to revoke access token: %w", err)
+	}
+	if familyID != "" {
+		if err := s.repo.RevokeRefreshTokenFamily(ctx, familyID); err != nil {
+			return fmt.Errorf("failed to revoke refresh token: %w", err)
+		}
+	}
+	logger.FromContext(ctx, s.logger).Info("User logged out", "user_id", claims.UserID)
+	return nil
+}
+
+func (s *tokenService) RevokeUserSessions(ctx context.Context, userID string) error {
+	ctx, span := tracing.Start(ctx, "TokenService.RevokeUserSessions")
+	defer span.End()
+	if err := s.repo.RevokeRefreshTokensForUser(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke sessions for user %s: %w", userID, err)
+	}
+	if err := s.authenticator.RevokeUserTokens(ctx, userID); err != nil {
+		return fmt.Errorf("failed to revoke access tokens for user %s: %w", userID, err)
+	}
+	logger.FromContext(ctx, s.logger).Info("Revoked all sessions for user", "user_id", userID)
+	return nil
+}
+
+func (s *tokenService) respond(userID string, roles, brands []string, rawRefreshToken string) (*domain.LoginResponse, error) {
+	accessToken, err := s.authenticator.GenerateUserToken(userID, roles, brands, s.accessTokenTTL)
+	if err != nil {
+		return nil, fmt.Errorf("failed to generate access token: %w", err)
+	}
+	return &domain.LoginResponse{
+		Token:        accessToken,
+		RefreshToken: rawRefreshToken,
+		TokenType:    "Bearer",
+		ExpiresIn:    int64(s.accessTokenTTL.Seconds()),
+	}, nil
+}
+
+func (s *tokenService) newRefreshToken(userID string, roles, brands []string, familyID string) (string, *domain.RefreshToken) {
+	b := make([]byte, 32)
+	if _, err := rand.Read(b); err != nil {
+		panic(fmt.Sprintf("failed to read random bytes: %v", err))
+	}
+	rawToken := base64.RawURLEncoding.EncodeToString(b)
+	now := time.Now()
+	return rawToken, &domain.RefreshToken{
+		ID:        uuid.NewString(),
+		UserID:    userID,
+		Roles:     roles,
+		Brands:    brands,
+		TokenHash: hashToken(rawToken),
+		FamilyID:  familyID,
+		ExpiresAt: now.Add(s.refreshTokenTTL),
+		CreatedAt: now,
+	}
+}
+
+func hashToken(rawToken string) string {
+	sum := sha256.Sum256([]byte(rawToken))
+	return hex.EncodeToString(sum[:])
+}
@@ -2,71 +2,98 @@ package service
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"time"
 
 	"github.com/google/uuid"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/database"
 	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/libs/tracing"
 	"github.com/omni-compos/digital-mono/services/user/internal/domain"
 	"github.com/omni-compos/digital-mono/services/user/internal/repository"
 )
 
+var (
+	// ErrPasswordLoginDisabled is returned by AuthenticateUser for every attempt until users have passwords.
+	ErrPasswordLoginDisabled = errors.New("password login is not available")
+	// ErrBrandNotAllowed is returned when a caller grants a new user a brand they may not act on themselves.
+	ErrBrandNotAllowed = errors.New("brand not allowed")
+)
+
 // UserService defines the interface for user business logic.
 type UserService interface {
-	CreateUser(ctx context.Context, name, email string) (*domain.User, error)
+	// CreateUser creates a user who may act on brands, which must be within the caller's own brands.
+	CreateUser(ctx context.Context, name, email string, brands []string) (*domain.User, error)
 	GetUser(ctx context.Context, id string) (*domain.User, error)
-		// AuthenticateUser is a placeholder for user authentication logic.
-	// In a real application, this would involve checking password hashes, etc.
+	// AuthenticateUser always returns ErrPasswordLoginDisabled: users have no password yet, so they
+	// log in through OIDC.
 	AuthenticateUser(ctx context.Context, email, password string) (*domain.User, error)
-
 }
 
 type userService struct {
 	repo   repository.UserRepository
 	logger logger.Logger // Using the common logger interface
+	kpis   *KPIs
+	tx     database.Transactor
+	outbox *database.Outbox
 }
 
-// NewUserService creates a new user service.
-func NewUserService(repo repository.UserRepository, log logger.Logger) UserService {
-	return &userService{repo: repo, logger: log}
+// NewUserService creates a new user service. kpis may be nil. Users and their domain events in
+// outbox are written in one transaction of tx; nil tx and outbox are allowed.
+func NewUserService(repo repository.UserRepository, log logger.Logger, kpis *KPIs, tx database.Transactor, outbox *database.Outbox) UserService {
+	if tx == nil {
+		tx = database.NopTransactor{}
+	}
+	return &userService{repo: repo, logger: log, kpis: kpis, tx: tx, outbox: outbox}
 }
 
-func (s *userService) CreateUser(ctx context.Context, name, email string) (*domain.User, error) {
-	s.logger.Info("Creating user", "name", name, "email", email)
+func (s *userService) CreateUser(ctx context.Context, name, email string, brands []string) (*domain.User, error) {
+	ctx, span := tracing.Start(ctx, "UserService.CreateUser")
+	defer span.End()
+	logger.FromContext(ctx, s.logger).Info("Creating user", "name", name, "email", email, "brands", brands)
+	tenants := commonAuth.TenantFilterFromContext(ctx)
+	for _, brand := range brands {
+		if !tenants.All && (brand == commonAuth.AllTenants || !tenants.Allows(brand)) {
+			return nil, fmt.Errorf("%w: %s", ErrBrandNotAllowed, brand)
+		}
+	}
 	now := time.Now()
 	user := &domain.User{
 		ID:        uuid.NewString(),
 		Name:      name,
 		Email:     email,
+		Brands:    brands,
 		CreatedAt: now,
 		UpdatedAt: now,
 	}
-	return user, s.repo.CreateUser(ctx, user)
+	err := s.tx.WithinTx(ctx, func(ctx context.Context) error {
+		if err := s.repo.CreateUser(ctx, user); err != nil {
+			return err
+		}
+		return s.outbox.Add(ctx, userAggregate, user.ID, EventUserCreated, user)
+	})
+	if err != nil {
+		return user, err
+	}
+	s.kpis.userCreated()
+	return user, nil
 }
 
 func (s *userService) GetUser(ctx context.Context, id string) (*domain.User, error) {
-	s.logger.Info("Getting user", "id", id)
+	ctx, span := tracing.Start(ctx, "UserService.GetUser")
+	defer span.End()
+	logger.FromContext(ctx, s.logger).Info("Getting user", "id", id)
 	return s.repo.GetUserByID(ctx, id)
 }
 
-
-
-// AuthenticateUser is a placeholder implementation.
-// Replace with actual authentication logic (e.g., password hashing comparison).
+// AuthenticateUser rejects every password login. Users have no stored password hash, and a token
+// carrying the user's stored brands must not be issued to anyone who merely knows their email.
 func (s *userService) AuthenticateUser(ctx context.Context, email, password string) (*domain.User, error) {
-	s.logger.Info("Attempting to authenticate user", "email", email)
-
-	// --- Placeholder Authentication Logic ---
-	// In a real app:
-	// 1. Retrieve user by email from the repository.
-	// 2. Compare the provided password with the stored hashed password.
-	// 3. Return the user object if authentication is successful.
-	// 4. Return an error (e.g., ErrInvalidCredentials) if authentication fails.
-
-	// Dummy logic: Assume authentication is successful if email and password are not empty
-	if email != "" && password != "" {
-		// In a real scenario, you'd fetch the actual user from the DB here
-		return &domain.User{ID: "dummy-user-id-for-auth", Email: email, Roles: []string{"user"}}, nil // Return a dummy user
-	}
-	return nil, fmt.Errorf("invalid credentials") // Or a specific authentication error type
-}
\ No newline at end of file
+	ctx, span := tracing.Start(ctx, "UserService.AuthenticateUser")
+	defer span.End()
+	logger.FromContext(ctx, s.logger).Info("Rejecting password login", "email", email)
+	s.kpis.login(LoginMethodPassword, LoginDisabled)
+	return nil, ErrPasswordLoginDisabled
+}
@@ -0,0 +1,86 @@
+package conformance
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/database/databasetest"
+	"github.com/omni-compos/digital-mono/services/user/internal/migrations"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository/repositorytest"
+)
+
+func TestMemoryUserRepository(t *testing.T) {
+	repositorytest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return repository.NewMemoryUserRepository()
+	})
+}
+
+func TestMemoryRefreshTokenRepository(t *testing.T) {
+	repositorytest.RunRefreshTokenRepositoryTests(t, func(t *testing.T) repository.RefreshTokenRepository {
+		return repository.NewMemoryRefreshTokenRepository()
+	})
+}
+
+func TestMemoryServiceClientRepository(t *testing.T) {
+	repositorytest.RunServiceClientRepositoryTests(t, func(t *testing.T) repository.ServiceClientRepository {
+		return repository.NewMemoryServiceClientRepository()
+	})
+}
+
+func TestMemoryAPIKeyRepository(t *testing.T) {
+	repositorytest.RunAPIKeyRepositoryTests(t, func(t *testing.T) repository.APIKeyRepository {
+		return repository.NewMemoryAPIKeyRepository()
+	})
+}
+
+// The PG tests run against the database in TEST_DATABASE_DSN and are skipped without it.
+
+func TestPGUserRepository(t *testing.T) {
+	repositorytest.RunUserRepositoryTests(t, func(t *testing.T) repository.UserRepository {
+		return repository.NewPGUserRepository(openPostgres(t, "users"), nil)
+	})
+}
+
+func TestPGRefreshTokenRepository(t *testing.T) {
+	repositorytest.RunRefreshTokenRepositoryTests(t, func(t *testing.T) repository.RefreshTokenRepository {
+		return repository.NewPGRefreshTokenRepository(openPostgres(t, "refresh_tokens"), nil)
+	})
+}
+
+func TestPGServiceClientRepository(t *testing.T) {
+	repositorytest.RunServiceClientRepositoryTests(t, func(t *testing.T) repository.ServiceClientRepository {
+		return repository.NewPGServiceClientRepository(openPostgres(t, "service_clients"), nil)
+	})
+}
+
+func TestPGAPIKeyRepository(t *testing.T) {
+	repositorytest.RunAPIKeyRepositoryTests(t, func(t *testing.T) repository.APIKeyRepository {
+		return repository.NewPGAPIKeyRepository(openPostgres(t, "api_keys"), nil)
+	})
+}
+
+// Other services read the revocation list from the tables the user service migrations create.
+func TestSQLRevocationStore_CheckSchema(t *testing.T) {
+	db := databasetest.Open(t, migrations.FS, migrations.Table)
+	if err := commonAuth.NewSQLRevocationStore(db).CheckSchema(context.Background()); err != nil {
+		t.Errorf("CheckSchema on the migrated database: %v", err)
+	}
+}
+
+func TestSQLAPIKeyStore_CheckSchema(t *testing.T) {
+	db := databasetest.Open(t, migrations.FS, migrations.Table)
+	if err := commonAuth.NewSQLAPIKeyStore(db).CheckSchema(context.Background()); err != nil {
+		t.Errorf("CheckSchema on the migrated database: %v", err)
+	}
+}
+
+// openPostgres returns the migrated test database with table emptied.
+func openPostgres(t *testing.T, table string) *sql.DB {
+	t.Helper()
+	db := databasetest.Open(t, migrations.FS, migrations.Table)
+	databasetest.Truncate(t, db, table)
+	return db
+}
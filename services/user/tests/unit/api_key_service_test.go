@@ -0,0 +1,127 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeAPIKeyRepository keeps API keys in memory and doubles as the authenticator's APIKeyStore.
+type fakeAPIKeyRepository struct {
+	mu   sync.Mutex
+	keys map[string]*domain.APIKey // keyed by ID
+}
+
+func (r *fakeAPIKeyRepository) CreateAPIKey(ctx context.Context, key *domain.APIKey) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *key
+	r.keys[key.ID] = &copied
+	return nil
+}
+
+func (r *fakeAPIKeyRepository) ListAPIKeysByOwner(ctx context.Context, ownerID string) ([]*domain.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	keys := []*domain.APIKey{}
+	for _, key := range r.keys {
+		if key.OwnerID == ownerID {
+			copied := *key
+			keys = append(keys, &copied)
+		}
+	}
+	return keys, nil
+}
+
+func (r *fakeAPIKeyRepository) RevokeAPIKey(ctx context.Context, id, ownerID string) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	key, ok := r.keys[id]
+	if !ok || key.OwnerID != ownerID || key.RevokedAt != nil {
+		return false, nil
+	}
+	now := time.Now()
+	key.RevokedAt = &now
+	return true, nil
+}
+
+func (r *fakeAPIKeyRepository) GetAPIKeyByHash(ctx context.Context, keyHash string) (*commonAuth.APIKey, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, key := range r.keys {
+		if key.KeyHash == keyHash {
+			return &commonAuth.APIKey{ID: key.ID, OwnerID: key.OwnerID, Scopes: key.Scopes, ExpiresAt: key.ExpiresAt, RevokedAt: key.RevokedAt}, nil
+		}
+	}
+	return nil, nil
+}
+
+func TestAPIKeyService_KeyAuthenticatesWithScopes(t *testing.T) {
+	repo := &fakeAPIKeyRepository{keys: make(map[string]*domain.APIKey)}
+	policy := commonAuth.DefaultPolicy()
+	apiKeyService := service.NewAPIKeyService(repo, policy, logger.NewStdLogger(), nil, nil)
+	authenticator := commonAuth.NewJWTAuthenticator("test-secret").WithAPIKeyStore(repo)
+	ctx := context.Background()
+	owner := &commonAuth.Claims{UserID: "user-1", Roles: []string{"seller_manager"}}
+
+	created, err := apiKeyService.CreateAPIKey(ctx, owner, &domain.CreateAPIKeyRequest{Name: "batch", Scopes: []string{commonAuth.PermSellersRead}})
+	require.NoError(t, err)
+	assert.NotEmpty(t, created.Key)
+	assert.Equal(t, created.Key[:len(created.Prefix)], created.Prefix)
+
+	var seen *commonAuth.Claims
+	protected := authenticator.Middleware(policy.RequirePermission(commonAuth.PermSellersRead)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		seen, _ = commonAuth.GetClaimsFromContext(r.Context())
+		w.WriteHeader(http.StatusOK)
+	})))
+	writeProtected := authenticator.Middleware(policy.RequirePermission(commonAuth.PermSellersWrite)(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	})))
+	call := func(h http.Handler, key string) int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set(commonAuth.APIKeyHeader, key)
+		rr := httptest.NewRecorder()
+		h.ServeHTTP(rr, req)
+		return rr.Code
+	}
+
+	assert.Equal(t, http.StatusOK, call(protected, created.Key))
+	require.NotNil(t, seen)
+	assert.Equal(t, "user-1", seen.UserID)
+	assert.Equal(t, "api_key:"+created.ID, seen.Principal())
+
+	// The owner's role grants sellers:write, but the key was only scoped to sellers:read.
+	assert.Equal(t, http.StatusForbidden, call(writeProtected, created.Key))
+	assert.Equal(t, http.StatusUnauthorized, call(protected, "dmk_unknown"))
+
+	require.NoError(t, apiKeyService.RevokeAPIKey(ctx, "user-1", created.ID))
+	assert.Equal(t, http.StatusUnauthorized, call(protected, created.Key))
+	assert.ErrorIs(t, apiKeyService.RevokeAPIKey(ctx, "user-1", created.ID), service.ErrAPIKeyNotFound)
+}
+
+func TestAPIKeyService_CreateAPIKeyRejectsEscalation(t *testing.T) {
+	repo := &fakeAPIKeyRepository{keys: make(map[string]*domain.APIKey)}
+	apiKeyService := service.NewAPIKeyService(repo, commonAuth.DefaultPolicy(), logger.NewStdLogger(), nil, nil)
+	ctx := context.Background()
+	user := &commonAuth.Claims{UserID: "user-1", Roles: []string{"user"}}
+
+	_, err := apiKeyService.CreateAPIKey(ctx, user, &domain.CreateAPIKeyRequest{Name: "too much", Scopes: []string{commonAuth.PermSellersWrite}})
+	assert.ErrorIs(t, err, service.ErrAPIKeyNotAllowed)
+
+	_, err = apiKeyService.CreateAPIKey(ctx, user, &domain.CreateAPIKeyRequest{Name: "forever", Scopes: []string{commonAuth.PermSellersRead}, ExpiresInDays: 1000})
+	assert.ErrorIs(t, err, service.ErrInvalidAPIKeyRequest)
+
+	keyClaims := &commonAuth.Claims{UserID: "user-1", PrincipalType: commonAuth.PrincipalAPIKey, Scope: commonAuth.PermSellersRead}
+	_, err = apiKeyService.CreateAPIKey(ctx, keyClaims, &domain.CreateAPIKeyRequest{Name: "chained", Scopes: []string{commonAuth.PermSellersRead}})
+	assert.ErrorIs(t, err, service.ErrAPIKeyNotAllowed)
+}
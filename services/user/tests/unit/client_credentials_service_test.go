@@ -0,0 +1,107 @@
+package unit
+
+import (
+	"context"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/handler/rest"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeServiceClientRepository struct {
+	clients map[string]*domain.ServiceClient
+}
+
+func (r *fakeServiceClientRepository) CreateServiceClient(ctx context.Context, client *domain.ServiceClient) error {
+	r.clients[client.ClientID] = client
+	return nil
+}
+
+func (r *fakeServiceClientRepository) GetServiceClient(ctx context.Context, clientID string) (*domain.ServiceClient, error) {
+	return r.clients[clientID], nil
+}
+
+func TestClientCredentialsService_IssueClientToken(t *testing.T) {
+	key, err := commonAuth.GenerateRSASigningKey("test-key")
+	require.NoError(t, err)
+	keySet := commonAuth.NewKeySet(key)
+	issuer := commonAuth.NewKeySetAuthenticator(keySet)
+
+	repo := &fakeServiceClientRepository{clients: map[string]*domain.ServiceClient{
+		"cart-bff": {
+			ClientID:         "cart-bff",
+			SecretHash:       service.HashClientSecret("s3cret"),
+			AllowedScopes:    []string{commonAuth.PermSellersRead, commonAuth.PermProductsRead},
+			AllowedAudiences: []string{"seller-service", "product-service"},
+		},
+	}}
+	clientService := service.NewClientCredentialsService(issuer, repo, logger.NewStdLogger(), time.Minute)
+	ctx := context.Background()
+
+	resp, err := clientService.IssueClientToken(ctx, "cart-bff", "s3cret", []string{"seller-service"}, []string{commonAuth.PermSellersRead})
+	require.NoError(t, err)
+	assert.Equal(t, commonAuth.PermSellersRead, resp.Scope)
+
+	sellerVerifier := commonAuth.NewPublicKeyAuthenticator(keySet).WithAudience("seller-service")
+	claims, err := sellerVerifier.ParseToken(resp.AccessToken)
+	require.NoError(t, err)
+	assert.True(t, claims.IsService())
+	assert.Equal(t, "service:cart-bff", claims.Principal())
+	assert.True(t, claims.HasScope(commonAuth.PermSellersRead))
+	assert.False(t, claims.HasScope(commonAuth.PermSellersWrite))
+
+	// The same token is rejected by a service it was not issued for.
+	productVerifier := commonAuth.NewPublicKeyAuthenticator(keySet).WithAudience("product-service")
+	_, err = productVerifier.ParseToken(resp.AccessToken)
+	assert.Error(t, err)
+
+	_, err = clientService.IssueClientToken(ctx, "cart-bff", "wrong", []string{"seller-service"}, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidClient)
+
+	_, err = clientService.IssueClientToken(ctx, "cart-bff", "s3cret", []string{"seller-service"}, []string{commonAuth.PermSellersDelete})
+	assert.ErrorIs(t, err, service.ErrInvalidScope)
+
+	_, err = clientService.IssueClientToken(ctx, "cart-bff", "s3cret", []string{"user-service"}, nil)
+	assert.ErrorIs(t, err, service.ErrInvalidScope)
+}
+
+func TestClientCredentialsTokenSource_RoundTripsReservedCharacters(t *testing.T) {
+	key, err := commonAuth.GenerateRSASigningKey("test-key")
+	require.NoError(t, err)
+	keySet := commonAuth.NewKeySet(key)
+
+	// Basic auth form-urlencodes both values (RFC 6749 §2.3.1); the token endpoint must decode them.
+	const clientID, secret = "cart bff:eu+1", "p@ss:w%rd+/ &="
+	repo := &fakeServiceClientRepository{clients: map[string]*domain.ServiceClient{
+		clientID: {
+			ClientID:         clientID,
+			SecretHash:       service.HashClientSecret(secret),
+			AllowedScopes:    []string{commonAuth.PermSellersRead},
+			AllowedAudiences: []string{"seller-service"},
+		},
+	}}
+	clientService := service.NewClientCredentialsService(commonAuth.NewKeySetAuthenticator(keySet), repo, logger.NewStdLogger(), time.Minute)
+	router := mux.NewRouter()
+	rest.NewClientCredentialsRESTHandler(clientService, logger.NewStdLogger()).RegisterRoutes(router)
+	server := httptest.NewServer(router)
+	defer server.Close()
+
+	token, err := commonAuth.NewClientCredentialsTokenSource(server.URL+"/oauth/token", clientID, secret, "seller-service", commonAuth.PermSellersRead).
+		Token(context.Background())
+	require.NoError(t, err)
+	claims, err := commonAuth.NewPublicKeyAuthenticator(keySet).WithAudience("seller-service").ParseToken(token)
+	require.NoError(t, err)
+	assert.Equal(t, "service:"+clientID, claims.Principal())
+
+	_, err = commonAuth.NewClientCredentialsTokenSource(server.URL+"/oauth/token", clientID, "p@ss:w%rd+/ &", "seller-service").
+		Token(context.Background())
+	assert.Error(t, err, "a wrong secret must still be rejected")
+}
@@ -0,0 +1,35 @@
+package unit
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestUserService_CreateUser_RedactsPersonalDataInLogs(t *testing.T) {
+	var buf bytes.Buffer
+	redactor := logger.NewRedactor([]byte("test-key")).RegisterKeys(logger.RedactMask, domain.LogRedactedKeys...)
+	appLogger, err := logger.NewSlogLogger(logger.Config{Output: &buf, Redactor: redactor})
+	require.NoError(t, err)
+
+	mockRepo := new(MockUserRepository)
+	mockRepo.On("CreateUser", mock.Anything, mock.Anything).Return(nil)
+	_, err = service.NewUserService(mockRepo, appLogger, nil, nil, nil).CreateUser(context.Background(), "Jane Citizen", "jane@example.com", nil)
+	require.NoError(t, err)
+
+	var line map[string]interface{}
+	require.NoError(t, json.Unmarshal(buf.Bytes(), &line), buf.String())
+	assert.Equal(t, "Creating user", line["msg"])
+	assert.Equal(t, logger.RedactedValue, line["name"])
+	assert.Equal(t, redactor.RedactField("email", "jane@example.com"), line["email"])
+	assert.NotContains(t, buf.String(), "Jane")
+	assert.NotContains(t, buf.String(), "jane@example.com")
+}
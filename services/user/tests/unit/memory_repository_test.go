@@ -0,0 +1,69 @@
+package unit
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryRefreshTokenRepository_RotatesOnce(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryRefreshTokenRepository()
+	old := &domain.RefreshToken{ID: "rt-1", UserID: "user-1", TokenHash: "hash-1", FamilyID: "family-1", ExpiresAt: time.Now().Add(time.Hour)}
+	require.NoError(t, repo.CreateRefreshToken(ctx, old))
+
+	rotated, err := repo.RotateRefreshToken(ctx, "rt-1", &domain.RefreshToken{ID: "rt-2", UserID: "user-1", TokenHash: "hash-2", FamilyID: "family-1"})
+	require.NoError(t, err)
+	assert.True(t, rotated)
+	rotated, err = repo.RotateRefreshToken(ctx, "rt-1", &domain.RefreshToken{ID: "rt-3", UserID: "user-1", TokenHash: "hash-3", FamilyID: "family-1"})
+	require.NoError(t, err)
+	assert.False(t, rotated, "a revoked token must not rotate again")
+
+	stored, err := repo.GetRefreshTokenByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, stored.RevokedAt)
+	assert.Equal(t, "rt-2", stored.ReplacedBy)
+	missing, err := repo.GetRefreshTokenByHash(ctx, "hash-3")
+	require.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryUserRepository_RejectsDuplicateEmail(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryUserRepository()
+	require.NoError(t, repo.CreateUser(ctx, &domain.User{ID: "user-1", Email: "a@example.com", Roles: []string{"admin"}}))
+	assert.Error(t, repo.CreateUser(ctx, &domain.User{ID: "user-2", Email: "a@example.com"}))
+
+	user, err := repo.GetUserByID(ctx, "user-1")
+	require.NoError(t, err)
+	assert.Nil(t, user.Roles, "roles are not stored with the user")
+}
+
+func TestMemoryAPIKeyRepository_ServesAuthLookups(t *testing.T) {
+	ctx := context.Background()
+	repo := repository.NewMemoryAPIKeyRepository()
+	var store commonAuth.APIKeyStore = repo
+	require.NoError(t, repo.CreateAPIKey(ctx, &domain.APIKey{ID: "key-1", OwnerID: "user-1", KeyHash: "hash-1", Scopes: []string{"sellers:read"}, ExpiresAt: time.Now().Add(time.Hour)}))
+
+	key, err := store.GetAPIKeyByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	require.NotNil(t, key)
+	assert.Equal(t, []string{"sellers:read"}, key.Scopes)
+
+	revoked, err := repo.RevokeAPIKey(ctx, "key-1", "user-2")
+	require.NoError(t, err)
+	assert.False(t, revoked, "only the owner may revoke a key")
+	revoked, err = repo.RevokeAPIKey(ctx, "key-1", "user-1")
+	require.NoError(t, err)
+	assert.True(t, revoked)
+
+	key, err = store.GetAPIKeyByHash(ctx, "hash-1")
+	require.NoError(t, err)
+	assert.NotNil(t, key.RevokedAt)
+}
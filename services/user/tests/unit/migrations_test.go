@@ -0,0 +1,36 @@
+package unit
+
+import (
+	"testing"
+	"testing/fstest"
+
+	commonDB "github.com/omni-compos/digital-mono/libs/database"
+	"github.com/omni-compos/digital-mono/services/user/internal/migrations"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMigrations_AreSequentialAndReversible(t *testing.T) {
+	loaded, err := commonDB.LoadMigrations(migrations.FS)
+	require.NoError(t, err)
+	require.NotEmpty(t, loaded)
+
+	for i, migration := range loaded {
+		assert.Equal(t, int64(i+1), migration.Version, "migration versions must have no gaps")
+		if migration.Version == 1 {
+			// The baseline adopts tables initdb may have created, so reverting it must not drop them
+			assert.Empty(t, migration.Down, "baseline migration %d_%s must have no down file", migration.Version, migration.Name)
+			continue
+		}
+		assert.NotEmpty(t, migration.Down, "migration %d_%s has no down file", migration.Version, migration.Name)
+	}
+}
+
+func TestLoadMigrations_RejectsMissingUpFile(t *testing.T) {
+	_, err := commonDB.LoadMigrations(fstest.MapFS{
+		"0001_create_users.up.sql": {Data: []byte("CREATE TABLE users (id TEXT);")},
+		"0002_add_index.down.sql":  {Data: []byte("DROP INDEX idx;")},
+		"README.md":                {Data: []byte("ignored")},
+	})
+	assert.Error(t, err)
+}
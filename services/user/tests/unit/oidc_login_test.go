@@ -0,0 +1,70 @@
+package unit
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/cookiejar"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/gorilla/mux"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/auth/oidctest"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/handler/rest"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestOIDCLogin_AuthorizationCodeFlow(t *testing.T) {
+	idp := oidctest.NewMockIdP("user-service", "s3cret")
+	defer idp.Close()
+	idp.Subject = "employee-42"
+	idp.Claims["roles"] = []string{"seller_manager"}
+
+	r := mux.NewRouter()
+	app := httptest.NewServer(r)
+	defer app.Close()
+
+	provider, err := commonAuth.NewOIDCProvider(context.Background(), commonAuth.OIDCConfig{
+		IssuerURL:    idp.Issuer,
+		ClientID:     idp.ClientID,
+		ClientSecret: idp.ClientSecret,
+		RedirectURL:  app.URL + "/api/v1/oidc/callback",
+	})
+	require.NoError(t, err)
+
+	tokenService, authenticator := newTestTokenService(t)
+	kpis := service.NewKPIs(nil)
+	oidcService := service.NewOIDCLoginService(provider, tokenService, logger.NewStdLogger(), kpis)
+	handler := rest.NewOIDCRESTHandler(oidcService, logger.NewStdLogger())
+	handler.RegisterRoutes(r.PathPrefix("/api/v1").Subrouter())
+
+	// The browser follows /oidc/login -> IdP /authorize -> /oidc/callback, keeping the state cookie.
+	jar, err := cookiejar.New(nil)
+	require.NoError(t, err)
+	browser := &http.Client{Jar: jar}
+	resp, err := browser.Get(app.URL + "/api/v1/oidc/login")
+	require.NoError(t, err)
+	defer resp.Body.Close()
+	require.Equal(t, http.StatusOK, resp.StatusCode)
+
+	var tokens domain.LoginResponse
+	require.NoError(t, json.NewDecoder(resp.Body).Decode(&tokens))
+	assert.NotEmpty(t, tokens.RefreshToken)
+
+	claims, err := authenticator.ParseToken(tokens.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "employee-42", claims.UserID)
+	assert.Equal(t, []string{"seller_manager"}, claims.Roles)
+	assert.Equal(t, 1.0, kpis.Logins(service.LoginMethodOIDC, service.LoginSuccess))
+
+	// A callback without the state cookie (e.g. a forged link) is rejected.
+	resp, err = http.Get(app.URL + "/api/v1/oidc/callback?code=abc&state=xyz")
+	require.NoError(t, err)
+	resp.Body.Close()
+	assert.Equal(t, http.StatusBadRequest, resp.StatusCode)
+}
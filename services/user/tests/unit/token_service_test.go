@@ -0,0 +1,179 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// fakeRefreshTokenRepository keeps refresh tokens in memory.
+type fakeRefreshTokenRepository struct {
+	mu     sync.Mutex
+	tokens map[string]*domain.RefreshToken // keyed by hash
+}
+
+func newFakeRefreshTokenRepository() *fakeRefreshTokenRepository {
+	return &fakeRefreshTokenRepository{tokens: make(map[string]*domain.RefreshToken)}
+}
+
+func (r *fakeRefreshTokenRepository) CreateRefreshToken(ctx context.Context, token *domain.RefreshToken) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	copied := *token
+	r.tokens[token.TokenHash] = &copied
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) GetRefreshTokenByHash(ctx context.Context, tokenHash string) (*domain.RefreshToken, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	token, ok := r.tokens[tokenHash]
+	if !ok {
+		return nil, nil
+	}
+	copied := *token
+	return &copied, nil
+}
+
+func (r *fakeRefreshTokenRepository) RotateRefreshToken(ctx context.Context, oldID string, replacement *domain.RefreshToken) (bool, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	for _, token := range r.tokens {
+		if token.ID == oldID {
+			if token.RevokedAt != nil {
+				return false, nil
+			}
+			now := time.Now()
+			token.RevokedAt = &now
+			token.ReplacedBy = replacement.ID
+			copied := *replacement
+			r.tokens[replacement.TokenHash] = &copied
+			return true, nil
+		}
+	}
+	return false, nil
+}
+
+func (r *fakeRefreshTokenRepository) revokeWhere(match func(*domain.RefreshToken) bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	now := time.Now()
+	for _, token := range r.tokens {
+		if match(token) && token.RevokedAt == nil {
+			token.RevokedAt = &now
+		}
+	}
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshToken(ctx context.Context, id string) error {
+	r.revokeWhere(func(t *domain.RefreshToken) bool { return t.ID == id })
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshTokenFamily(ctx context.Context, familyID string) error {
+	r.revokeWhere(func(t *domain.RefreshToken) bool { return t.FamilyID == familyID })
+	return nil
+}
+
+func (r *fakeRefreshTokenRepository) RevokeRefreshTokensForUser(ctx context.Context, userID string) error {
+	r.revokeWhere(func(t *domain.RefreshToken) bool { return t.UserID == userID })
+	return nil
+}
+
+func newTestTokenService(t *testing.T) (service.TokenService, *commonAuth.JWTAuthenticator) {
+	key, err := commonAuth.GenerateECDSASigningKey("test-key")
+	require.NoError(t, err)
+	authenticator := commonAuth.NewKeySetAuthenticator(commonAuth.NewKeySet(key)).
+		WithRevocationStore(commonAuth.NewMemoryRevocationStore())
+	tokenService := service.NewTokenService(authenticator, newFakeRefreshTokenRepository(), logger.NewStdLogger(), time.Minute, time.Hour)
+	return tokenService, authenticator
+}
+
+func TestTokenService_RefreshRotatesToken(t *testing.T) {
+	tokenService, authenticator := newTestTokenService(t)
+	ctx := context.Background()
+	user := &domain.User{ID: "user-1", Roles: []string{"user"}}
+
+	issued, err := tokenService.IssueTokens(ctx, user)
+	require.NoError(t, err)
+	assert.Equal(t, int64(60), issued.ExpiresIn)
+
+	refreshed, err := tokenService.RefreshTokens(ctx, issued.RefreshToken)
+	require.NoError(t, err)
+	assert.NotEqual(t, issued.RefreshToken, refreshed.RefreshToken)
+
+	claims, err := authenticator.ParseToken(refreshed.Token)
+	require.NoError(t, err)
+	assert.Equal(t, "user-1", claims.UserID)
+	assert.Equal(t, []string{"user"}, claims.Roles)
+
+	// Reusing the rotated token revokes the whole session, including the newest token.
+	_, err = tokenService.RefreshTokens(ctx, issued.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+	_, err = tokenService.RefreshTokens(ctx, refreshed.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestTokenService_LogoutRevokesAccessToken(t *testing.T) {
+	tokenService, authenticator := newTestTokenService(t)
+	ctx := context.Background()
+
+	issued, err := tokenService.IssueTokens(ctx, &domain.User{ID: "user-1"})
+	require.NoError(t, err)
+
+	protected := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.WriteHeader(http.StatusOK)
+	}))
+	call := func() int {
+		req := httptest.NewRequest(http.MethodGet, "/", nil)
+		req.Header.Set("Authorization", "Bearer "+issued.Token)
+		rr := httptest.NewRecorder()
+		protected.ServeHTTP(rr, req)
+		return rr.Code
+	}
+	assert.Equal(t, http.StatusOK, call())
+
+	claims, err := authenticator.ParseToken(issued.Token)
+	require.NoError(t, err)
+	require.NoError(t, tokenService.Logout(ctx, claims, issued.RefreshToken))
+
+	assert.Equal(t, http.StatusUnauthorized, call())
+	_, err = tokenService.RefreshTokens(ctx, issued.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+}
+
+func TestTokenService_LogoutRevokesNothingOnClientErrors(t *testing.T) {
+	tokenService, authenticator := newTestTokenService(t)
+	ctx := context.Background()
+	issued, err := tokenService.IssueTokens(ctx, &domain.User{ID: "user-1"})
+	require.NoError(t, err)
+	other, err := tokenService.IssueTokens(ctx, &domain.User{ID: "user-2"})
+	require.NoError(t, err)
+	claims, err := authenticator.ParseToken(issued.Token)
+	require.NoError(t, err)
+
+	assert.ErrorIs(t, tokenService.Logout(ctx, claims, "unknown-refresh-token"), service.ErrInvalidRefreshToken)
+	assert.ErrorIs(t, tokenService.Logout(ctx, claims, other.RefreshToken), service.ErrInvalidRefreshToken)
+	apiKey := &commonAuth.Claims{UserID: "user-1", PrincipalType: commonAuth.PrincipalAPIKey}
+	assert.ErrorIs(t, tokenService.Logout(ctx, apiKey, issued.RefreshToken), service.ErrNotASession)
+
+	// The session is untouched: the access token is accepted and the refresh token still rotates
+	protected := authenticator.Middleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {}))
+	req := httptest.NewRequest(http.MethodGet, "/", nil)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	rr := httptest.NewRecorder()
+	protected.ServeHTTP(rr, req)
+	assert.Equal(t, http.StatusOK, rr.Code)
+	_, err = tokenService.RefreshTokens(ctx, issued.RefreshToken)
+	assert.NoError(t, err)
+}
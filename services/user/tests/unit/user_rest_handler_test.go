@@ -0,0 +1,132 @@
+package unit
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/gorilla/mux"
+	"github.com/graphql-go/graphql"
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
+	"github.com/omni-compos/digital-mono/libs/logger"
+	"github.com/omni-compos/digital-mono/services/user/internal/domain"
+	userGraphQL "github.com/omni-compos/digital-mono/services/user/internal/handler/graphql"
+	"github.com/omni-compos/digital-mono/services/user/internal/handler/rest"
+	"github.com/omni-compos/digital-mono/services/user/internal/repository"
+	"github.com/omni-compos/digital-mono/services/user/internal/service"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+// newUserRouter registers the user REST routes as cmd/api does: public routes first, then the
+// protected ones behind the authenticator.
+func newUserRouter(t *testing.T, userService service.UserService) (*mux.Router, service.TokenService, *commonAuth.JWTAuthenticator) {
+	t.Helper()
+	tokenService, authenticator := newTestTokenService(t)
+	handler := rest.NewUserRESTHandler(userService, tokenService, logger.NewStdLogger(), commonAuth.DefaultPolicy())
+	r := mux.NewRouter()
+	handler.RegisterRoutes(r.PathPrefix("/api/v1").Subrouter())
+	protected := r.PathPrefix("/api/v1").Subrouter()
+	protected.Use(authenticator.Middleware)
+	handler.RegisterProtectedRoutes(protected)
+	return r, tokenService, authenticator
+}
+
+func bearer(t *testing.T, authenticator *commonAuth.JWTAuthenticator, roles ...string) string {
+	t.Helper()
+	token, err := authenticator.GenerateUserToken("caller-1", roles, []string{commonAuth.AllTenants}, time.Minute)
+	require.NoError(t, err)
+	return "Bearer " + token
+}
+
+func TestUserREST_CreateUserRequiresUsersWrite(t *testing.T) {
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), logger.NewStdLogger(), nil, nil, nil)
+	r, _, authenticator := newUserRouter(t, userService)
+
+	tests := map[string]struct {
+		authorization string
+		status        int
+	}{
+		"anonymous":  {"", http.StatusUnauthorized},
+		"user role":  {bearer(t, authenticator, "user"), http.StatusForbidden},
+		"admin role": {bearer(t, authenticator, "admin"), http.StatusCreated},
+	}
+	for name, tt := range tests {
+		t.Run(name, func(t *testing.T) {
+			body := `{"name": "Ada", "email": "` + strings.ReplaceAll(name, " ", "-") + `@example.com"}`
+			req := httptest.NewRequest(http.MethodPost, "/api/v1/users", strings.NewReader(body))
+			if tt.authorization != "" {
+				req.Header.Set("Authorization", tt.authorization)
+			}
+			rec := httptest.NewRecorder()
+			r.ServeHTTP(rec, req)
+			assert.Equal(t, tt.status, rec.Code, rec.Body.String())
+		})
+	}
+}
+
+func TestUserREST_LoginIsNotAvailable(t *testing.T) {
+	repo := repository.NewMemoryUserRepository()
+	require.NoError(t, repo.CreateUser(context.Background(), &domain.User{ID: "user-1", Email: "ada@example.com", Brands: []string{commonAuth.AllTenants}}))
+	r, _, _ := newUserRouter(t, service.NewUserService(repo, logger.NewStdLogger(), nil, nil, nil))
+
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/login", strings.NewReader(`{"email": "ada@example.com", "password": "anything"}`))
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusNotImplemented, rec.Code)
+	assert.NotContains(t, rec.Body.String(), "token")
+}
+
+func TestUserGraphQL_CreateUserRequiresUsersWrite(t *testing.T) {
+	userService := service.NewUserService(repository.NewMemoryUserRepository(), logger.NewStdLogger(), nil, nil, nil)
+	handler, err := userGraphQL.NewUserGraphQLHandler(userService, logger.NewStdLogger(), commonAuth.DefaultPolicy())
+	require.NoError(t, err)
+	createUser := func(roles ...string) *graphql.Result {
+		ctx := context.WithValue(context.Background(), commonAuth.ClaimsContextKey,
+			&commonAuth.Claims{UserID: "caller-1", Roles: roles, Brands: []string{commonAuth.AllTenants}})
+		return graphql.Do(graphql.Params{Schema: handler.Schema, Context: ctx,
+			RequestString: `mutation { createUser(name: "Ada", email: "ada@example.com", brands: ["BRAND_A"]) { id brands } }`})
+	}
+
+	result := createUser("user")
+	require.Len(t, result.Errors, 1)
+	assert.Contains(t, result.Errors[0].Message, "missing permission "+commonAuth.PermUsersWrite)
+
+	result = createUser("admin")
+	require.Empty(t, result.Errors)
+	created := result.Data.(map[string]interface{})["createUser"].(map[string]interface{})
+	assert.Equal(t, []interface{}{"BRAND_A"}, created["brands"])
+}
+
+func TestUserREST_RevokeUserSessions(t *testing.T) {
+	r, tokenService, authenticator := newUserRouter(t, service.NewUserService(repository.NewMemoryUserRepository(), logger.NewStdLogger(), nil, nil, nil))
+	issued, err := tokenService.IssueTokens(context.Background(), &domain.User{ID: "user-1", Roles: []string{"user"}})
+	require.NoError(t, err)
+	revoke := func(authorization string) int {
+		req := httptest.NewRequest(http.MethodPost, "/api/v1/users/user-1/sessions/revoke", nil)
+		req.Header.Set("Authorization", authorization)
+		rec := httptest.NewRecorder()
+		r.ServeHTTP(rec, req)
+		return rec.Code
+	}
+
+	assert.Equal(t, http.StatusForbidden, revoke(bearer(t, authenticator, "user")))
+	_, err = tokenService.RefreshTokens(context.Background(), issued.RefreshToken)
+	require.NoError(t, err, "a rejected revocation must leave the sessions alone")
+
+	issued, err = tokenService.IssueTokens(context.Background(), &domain.User{ID: "user-1", Roles: []string{"user"}})
+	require.NoError(t, err)
+	assert.Equal(t, http.StatusNoContent, revoke(bearer(t, authenticator, "admin")))
+	_, err = tokenService.RefreshTokens(context.Background(), issued.RefreshToken)
+	assert.ErrorIs(t, err, service.ErrInvalidRefreshToken)
+
+	// Access tokens issued before the revocation stop working too
+	req := httptest.NewRequest(http.MethodPost, "/api/v1/logout", nil)
+	req.Header.Set("Authorization", "Bearer "+issued.Token)
+	rec := httptest.NewRecorder()
+	r.ServeHTTP(rec, req)
+	assert.Equal(t, http.StatusUnauthorized, rec.Code, rec.Body.String())
+}
@@ -4,11 +4,13 @@ import (
 	"context"
 	"testing"
 
+	commonAuth "github.com/omni-compos/digital-mono/libs/auth"
 	"github.com/omni-compos/digital-mono/libs/logger" // Mock or use a test logger
 	"github.com/omni-compos/digital-mono/services/user/internal/domain"
 	"github.com/omni-compos/digital-mono/services/user/internal/service"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 // MockUserRepository is a mock type for the UserRepository type
@@ -29,12 +31,20 @@ func (m *MockUserRepository) GetUserByID(ctx context.Context, id string) (*domai
 	return args.Get(0).(*domain.User), args.Error(1)
 }
 
+func (m *MockUserRepository) GetUserByEmail(ctx context.Context, email string) (*domain.User, error) {
+	args := m.Called(ctx, email)
+	if args.Get(0) == nil {
+		return nil, args.Error(1)
+	}
+	return args.Get(0).(*domain.User), args.Error(1)
+}
+
 func TestUserService_CreateUser(t *testing.T) {
 	mockRepo := new(MockUserRepository)
 	// For logger, you can use a simple mock or a no-op logger for tests
 	testLogger := logger.NewStdLogger() // Replace with a test-specific logger if needed
 
-	userService := service.NewUserService(mockRepo, testLogger)
+	userService := service.NewUserService(mockRepo, testLogger, nil, nil, nil)
 
 	name := "Test User"
 	email := "test@example.com"
@@ -44,7 +54,7 @@ func TestUserService_CreateUser(t *testing.T) {
 		return user.Name == name && user.Email == email
 	})).Return(nil)
 
-	createdUser, err := userService.CreateUser(context.Background(), name, email)
+	createdUser, err := userService.CreateUser(context.Background(), name, email, nil)
 
 	assert.NoError(t, err)
 	assert.NotNil(t, createdUser)
@@ -53,4 +63,54 @@ func TestUserService_CreateUser(t *testing.T) {
 	mockRepo.AssertExpectations(t)
 }
 
+func TestUserService_CreateUser_BrandsWithinCallersBrands(t *testing.T) {
+	callerOf := func(brands ...string) context.Context {
+		return context.WithValue(context.Background(), commonAuth.ClaimsContextKey, &commonAuth.Claims{UserID: "admin-1", Brands: brands})
+	}
+	tests := []struct {
+		name    string
+		ctx     context.Context
+		brands  []string
+		allowed bool
+	}{
+		{"own brand", callerOf("BRAND_A", "BRAND_B"), []string{"BRAND_A"}, true},
+		{"no brands", callerOf("BRAND_A"), nil, true},
+		{"other brand", callerOf("BRAND_A"), []string{"BRAND_A", "BRAND_B"}, false},
+		{"all brands from restricted caller", callerOf("BRAND_A"), []string{commonAuth.AllTenants}, false},
+		{"all brands from unrestricted caller", callerOf(commonAuth.AllTenants), []string{commonAuth.AllTenants}, true},
+		{"caller without claims", context.Background(), []string{"BRAND_A"}, false},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			mockRepo := new(MockUserRepository)
+			mockRepo.On("CreateUser", mock.Anything, mock.Anything).Return(nil).Maybe()
+			userService := service.NewUserService(mockRepo, logger.NewStdLogger(), nil, nil, nil)
+
+			user, err := userService.CreateUser(tt.ctx, "Test User", "test@example.com", tt.brands)
+			if tt.allowed {
+				require.NoError(t, err)
+				assert.Equal(t, tt.brands, user.Brands)
+				mockRepo.AssertCalled(t, "CreateUser", mock.Anything, mock.Anything)
+			} else {
+				assert.ErrorIs(t, err, service.ErrBrandNotAllowed)
+				mockRepo.AssertNotCalled(t, "CreateUser", mock.Anything, mock.Anything)
+			}
+		})
+	}
+}
+
+// Users have no password hash yet, so no password may yield a token carrying their stored brands.
+func TestUserService_AuthenticateUser_RejectsPasswordLogin(t *testing.T) {
+	kpis := service.NewKPIs(nil)
+	mockRepo := new(MockUserRepository)
+	userService := service.NewUserService(mockRepo, logger.NewStdLogger(), kpis, nil, nil)
+
+	user, err := userService.AuthenticateUser(context.Background(), "test@example.com", "secret")
+	assert.ErrorIs(t, err, service.ErrPasswordLoginDisabled)
+	assert.Nil(t, user)
+	mockRepo.AssertNotCalled(t, "GetUserByEmail", mock.Anything, mock.Anything)
+	assert.Equal(t, 1.0, kpis.Logins(service.LoginMethodPassword, service.LoginDisabled))
+	assert.Equal(t, 0.0, kpis.Logins(service.LoginMethodPassword, service.LoginSuccess))
+}
+
 // TODO: Add more tests for GetUser, error cases, etc.
\ No newline at end of file